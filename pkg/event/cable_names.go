@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	submV1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+// isSubscribedToCable returns whether or not the given Handler is to be notified of any of the given Endpoints as per
+// CableNameSubscriber. Nil Endpoints are ignored.
+func isSubscribedToCable(h Handler, endpoints ...*submV1.Endpoint) bool {
+	sh, ok := h.(CableNameSubscriber)
+	if !ok {
+		return true
+	}
+
+	pattern := sh.CableNamePattern()
+	if pattern == nil {
+		return true
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint != nil && pattern.MatchString(endpoint.Spec.CableName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subscribedEndpoints returns the given Endpoints of which the given Handler is to be notified as per CableNameSubscriber.
+func subscribedEndpoints(h Handler, endpoints []*submV1.Endpoint) []*submV1.Endpoint {
+	subscribed := make([]*submV1.Endpoint, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		if isSubscribedToCable(h, endpoint) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+
+	return subscribed
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"k8s.io/utils/clock"
+)
+
+// DefaultResolvedAddressTTL is the default time for which an address resolved by the AddressResolver is cached.
+const DefaultResolvedAddressTTL = 5 * time.Minute
+
+// addressCache caches the addresses resolved for the remote Endpoints, keyed by Endpoint name. It's safe for concurrent
+// use.
+type addressCache struct {
+	resolve func(endpoint *subv1.Endpoint) (string, error)
+	ttl     time.Duration
+	clock   clock.PassiveClock
+	mutex   sync.Mutex
+	entries map[string]*resolvedAddress
+}
+
+type resolvedAddress struct {
+	// endpoint is the tracked Endpoint for which the address was resolved so the address is re-resolved if it's updated.
+	endpoint *subv1.Endpoint
+	address  string
+	expiry   time.Time
+}
+
+// get returns the resolved address of the given Endpoint, resolving it if it's not cached, it expired or the Endpoint was
+// updated since. If the resolution fails, the error is returned and nothing is cached so it's retried on the next call.
+func (a *addressCache) get(endpoint *subv1.Endpoint) (string, error) {
+	a.mutex.Lock()
+	entry, found := a.entries[endpoint.Name]
+	a.mutex.Unlock()
+
+	if found && entry.endpoint == endpoint && a.clock.Now().Before(entry.expiry) {
+		return entry.address, nil
+	}
+
+	// The resolver isn't invoked with the lock held as it may be slow, eg performing a DNS lookup.
+	address, err := a.resolve(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	a.entries[endpoint.Name] = &resolvedAddress{endpoint: endpoint, address: address, expiry: a.clock.Now().Add(a.ttl)}
+	a.mutex.Unlock()
+
+	return address, nil
+}
+
+func (a *addressCache) forget(name string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.entries, name)
+}
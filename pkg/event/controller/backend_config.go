@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+// backendConfigChanges returns the entries of the new backend config that were added or changed relative to the old one,
+// mapped to their new values, and the removed entries mapped to the empty string. Nil is returned if nothing changed.
+func backendConfigChanges(oldConfig, newConfig map[string]string) map[string]string {
+	var changed map[string]string
+
+	record := func(key, value string) {
+		if changed == nil {
+			changed = map[string]string{}
+		}
+
+		changed[key] = value
+	}
+
+	for key, value := range newConfig {
+		if oldValue, found := oldConfig[key]; !found || oldValue != value {
+			record(key, value)
+		}
+	}
+
+	for key := range oldConfig {
+		if _, found := newConfig[key]; !found {
+			record(key, "")
+		}
+	}
+
+	return changed
+}
+
+// notifyBackendConfigChanges notifies the handlers if the backend config of the given updated Endpoint changed.
+func (c *Controller) notifyBackendConfigChanges(oldEndpoint, newEndpoint *smv1.Endpoint) error {
+	changed := backendConfigChanges(oldEndpoint.Spec.BackendConfig, newEndpoint.Spec.BackendConfig)
+	if changed == nil {
+		return nil
+	}
+
+	c.eventLog.Infof("The backend config of endpoint %q changed: %v", newEndpoint.Name, changed)
+
+	return c.handlers.EndpointBackendConfigChanged(newEndpoint.Spec.ClusterID, changed) //nolint:wrapcheck  // Let the caller wrap it
+}
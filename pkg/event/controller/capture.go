@@ -0,0 +1,88 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// eventCapture is a ring buffer holding the most recent events dispatched to the primary registry.
+type eventCapture struct {
+	mutex  sync.Mutex
+	buffer []event.Notification
+	// next is the index in buffer at which the next event is stored.
+	next int
+	full bool
+}
+
+// EnableCapture starts capturing the last size events dispatched to the handlers so they can be retrieved via DumpCapture,
+// eg for live debugging. The handlers are unaffected. Any previously captured events are discarded. A size of 0 disables
+// the capture.
+func (c *Controller) EnableCapture(size int) {
+	c.capture.mutex.Lock()
+	defer c.capture.mutex.Unlock()
+
+	c.capture.buffer = nil
+	c.capture.next = 0
+	c.capture.full = false
+
+	if size > 0 {
+		c.capture.buffer = make([]event.Notification, size)
+	}
+}
+
+// DumpCapture returns the captured events, oldest first, or nil if capturing isn't enabled.
+func (c *Controller) DumpCapture() []event.Notification {
+	c.capture.mutex.Lock()
+	defer c.capture.mutex.Unlock()
+
+	if c.capture.buffer == nil {
+		return nil
+	}
+
+	if !c.capture.full {
+		return append([]event.Notification{}, c.capture.buffer[:c.capture.next]...)
+	}
+
+	return append(append([]event.Notification{}, c.capture.buffer[c.capture.next:]...), c.capture.buffer[:c.capture.next]...)
+}
+
+// record is the primary registry's observer which stores a copy of the given event in the buffer, evicting the oldest
+// event if it's full.
+func (e *eventCapture) record(n event.Notification) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.buffer == nil {
+		return
+	}
+
+	objs := make([]runtime.Object, len(n.Objects))
+	for i := range n.Objects {
+		objs[i] = n.Objects[i].DeepCopyObject()
+	}
+
+	n.Objects = objs
+	e.buffer[e.next] = n
+	e.next = (e.next + 1) % len(e.buffer)
+	e.full = e.full || e.next == 0
+}
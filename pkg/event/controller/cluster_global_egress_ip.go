@@ -0,0 +1,59 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func (c *Controller) handleCreatedClusterGlobalEgressIP(obj runtime.Object, requeueCount int) bool {
+	return c.handleClusterGlobalEgressIP(event.ClusterGlobalEgressIPCreated, obj, requeueCount, c.handlers.ClusterGlobalEgressIPCreated)
+}
+
+func (c *Controller) handleUpdatedClusterGlobalEgressIP(obj runtime.Object, requeueCount int) bool {
+	return c.handleClusterGlobalEgressIP(event.ClusterGlobalEgressIPUpdated, obj, requeueCount, c.handlers.ClusterGlobalEgressIPUpdated)
+}
+
+func (c *Controller) handleRemovedClusterGlobalEgressIP(obj runtime.Object, requeueCount int) bool {
+	return c.handleClusterGlobalEgressIP(event.ClusterGlobalEgressIPRemoved, obj, requeueCount, c.handlers.ClusterGlobalEgressIPRemoved)
+}
+
+func (c *Controller) handleClusterGlobalEgressIP(eventType event.Type, obj runtime.Object, requeueCount int,
+	notify func(egressIP *subv1.ClusterGlobalEgressIP) error,
+) bool {
+	egressIP := obj.(*subv1.ClusterGlobalEgressIP)
+
+	if requeueCount > maxRequeues {
+		c.dropEvent(eventType, egressIP, DropReasonMaxRequeues, "requeued more than %d times", maxRequeues)
+		return false
+	}
+
+	if !c.shouldDispatch(eventType, egressIP) {
+		return false
+	}
+
+	if err := notify(egressIP); err != nil {
+		c.eventLog.Errorf(err, "Error handling %s event for ClusterGlobalEgressIP %q", eventType, egressIP.Name)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// EventCodec serializes the events recorded to an AuditWriter and deserializes them for replay. Implementations are
+// responsible for framing the records in the stream.
+type EventCodec interface {
+	// Encode writes the given record to the writer.
+	Encode(w io.Writer, record *EventRecord) error
+
+	// Decode reads the next record from the reader, returning io.EOF if there are no more. The same reader is passed to
+	// each call for a stream. The decoded Object need not be of the watched resource's type, eg it may be an
+	// *unstructured.Unstructured, in which case it's converted on replay.
+	Decode(r *bufio.Reader) (*EventRecord, error)
+}
+
+// JSONCodec is the default EventCodec which encodes each record as a JSON line.
+type JSONCodec struct{}
+
+type jsonEventRecord struct {
+	Resource    string          `json:"resource"`
+	Cluster     string          `json:"cluster,omitempty"`
+	Operation   string          `json:"operation"`
+	NumRequeues int             `json:"numRequeues"`
+	Object      json.RawMessage `json:"object"`
+}
+
+func (JSONCodec) Encode(w io.Writer, record *EventRecord) error {
+	object, err := json.Marshal(record.Object)
+	if err != nil {
+		return errors.Wrap(err, "error serializing the object")
+	}
+
+	data, err := json.Marshal(&jsonEventRecord{
+		Resource:    record.Resource,
+		Cluster:     record.Cluster,
+		Operation:   record.Operation,
+		NumRequeues: record.NumRequeues,
+		Object:      object,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error serializing the record")
+	}
+
+	_, err = w.Write(append(data, '\n'))
+
+	return errors.Wrap(err, "error writing the record")
+}
+
+func (JSONCodec) Decode(r *bufio.Reader) (*EventRecord, error) {
+	line, err := r.ReadBytes('\n')
+	if errors.Is(err, io.EOF) && len(line) > 0 {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck  // Let the caller wrap it
+	}
+
+	record := &jsonEventRecord{}
+	if err := json.Unmarshal(line, record); err != nil {
+		return nil, errors.Wrap(err, "error deserializing the record")
+	}
+
+	object := map[string]interface{}{}
+	if err := utiljson.Unmarshal(record.Object, &object); err != nil {
+		return nil, errors.Wrap(err, "error deserializing the object")
+	}
+
+	return &EventRecord{
+		Resource:    record.Resource,
+		Cluster:     record.Cluster,
+		Operation:   record.Operation,
+		NumRequeues: record.NumRequeues,
+		Object:      &unstructured.Unstructured{Object: object},
+	}, nil
+}
@@ -19,11 +19,16 @@ limitations under the License.
 package controller
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"io"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/log"
@@ -32,26 +37,101 @@ import (
 	"github.com/submariner-io/submariner/pkg/event"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/set"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type specification struct {
 	ClusterID string
 	Namespace string
+
+	// EventTrace if true, the lifecycle of each event is traced to the logger at debug level.
+	EventTrace bool
 }
 
+// handlerStateImpl is safe for concurrent use as it's accessed by the handlers outside of the controller's syncMutex.
 type handlerStateImpl struct {
+	clusterID       string
+	localNodeName   string
 	isOnGateway     atomic.Bool
-	wasOnGateway    bool
+	wasOnGateway    atomic.Bool
 	remoteEndpoints sync.Map
+	// unhealthyClusters holds the IDs of the remote clusters whose gateway Endpoint was reported as unreachable.
+	unhealthyClusters sync.Map
+	correlationID     atomic.Value
+	// gatewayCond is signaled when isOnGateway changes.
+	gatewayCond     *sync.Cond
+	gatewayCondOnce sync.Once
+	gatewayMutex    sync.Mutex
+	ctx             context.Context
+	cancel          context.CancelFunc
+	// listNodes returns the Nodes in the informer caches.
+	listNodes func() []runtime.Object
+	// nodes indexes the watched Nodes by hostname.
+	nodes nodeIndex
+	// addresses caches the addresses resolved for the remote Endpoints. It's nil if no AddressResolver is configured.
+	addresses *addressCache
+	// log is the controller's logger.
+	log log.Logger
+}
+
+func (s *handlerStateImpl) GetClusterID() string {
+	return s.clusterID
+}
+
+func (s *handlerStateImpl) GetLocalNodeName() string {
+	return s.localNodeName
+}
+
+func (s *handlerStateImpl) GetGatewayNodeNames() []string {
+	names := []string{}
+
+	for _, obj := range s.listNodes() {
+		if node := obj.(*k8sv1.Node); node.Labels[GatewayLabel] == "true" {
+			names = append(names, node.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *handlerStateImpl) Context() context.Context {
+	return s.ctx
+}
+
+func (s *handlerStateImpl) GetCorrelationID() string {
+	id, _ := s.correlationID.Load().(string)
+	return id
 }
 
 func (s *handlerStateImpl) setIsOnGateway(v bool) {
-	s.isOnGateway.Store(v)
+	cond := s.gatewayStateCond()
+
+	cond.L.Lock()
+	defer cond.L.Unlock()
+
+	if s.isOnGateway.Swap(v) != v {
+		cond.Broadcast()
+	}
+}
+
+func (s *handlerStateImpl) gatewayStateCond() *sync.Cond {
+	s.gatewayCondOnce.Do(func() {
+		s.gatewayCond = sync.NewCond(&s.gatewayMutex)
+	})
+
+	return s.gatewayCond
 }
 
 func (s *handlerStateImpl) IsOnGateway() bool {
@@ -59,6 +139,16 @@ func (s *handlerStateImpl) IsOnGateway() bool {
 }
 
 func (s *handlerStateImpl) GetRemoteEndpoints() []subv1.Endpoint {
+	return s.GetRemoteEndpointsSortedBy(func(a, b *subv1.Endpoint) bool {
+		if a.Spec.ClusterID != b.Spec.ClusterID {
+			return a.Spec.ClusterID < b.Spec.ClusterID
+		}
+
+		return a.Name < b.Name
+	})
+}
+
+func (s *handlerStateImpl) GetRemoteEndpointsSortedBy(less func(a, b *subv1.Endpoint) bool) []subv1.Endpoint {
 	var endpoints []subv1.Endpoint
 
 	s.remoteEndpoints.Range(func(_, value any) bool {
@@ -66,18 +156,230 @@ func (s *handlerStateImpl) GetRemoteEndpoints() []subv1.Endpoint {
 		return true
 	})
 
+	sort.Slice(endpoints, func(i, j int) bool {
+		return less(&endpoints[i], &endpoints[j])
+	})
+
 	return endpoints
 }
 
+func (s *handlerStateImpl) GetRemoteSubnets() []string {
+	subnets := set.New[string]()
+
+	s.remoteEndpoints.Range(func(_, value any) bool {
+		subnets.Insert(value.(*subv1.Endpoint).Spec.Subnets...)
+		return true
+	})
+
+	return subnets.SortedList()
+}
+
+func (s *handlerStateImpl) GetNodes() []k8sv1.Node {
+	objs := s.listNodes()
+
+	nodes := make([]k8sv1.Node, 0, len(objs))
+	for _, obj := range objs {
+		nodes = append(nodes, *obj.(*k8sv1.Node).DeepCopy())
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes
+}
+
+func (s *handlerStateImpl) GetNodeByHostname(hostname string) (*k8sv1.Node, bool) {
+	return s.nodes.get(hostname)
+}
+
+func (s *handlerStateImpl) GetNodeInfos() []event.NodeInfo {
+	nodes := s.GetNodes()
+
+	infos := make([]event.NodeInfo, 0, len(nodes))
+	for i := range nodes {
+		info := event.NewNodeInfo(&nodes[i])
+		info.IsLocal = nodes[i].Name == s.localNodeName
+		infos = append(infos, *info)
+	}
+
+	return infos
+}
+
+func (s *handlerStateImpl) GetGatewayEndpoint(clusterID string) (*subv1.Endpoint, bool) {
+	var active *subv1.Endpoint
+
+	s.remoteEndpoints.Range(func(_, value any) bool {
+		endpoint := value.(*subv1.Endpoint)
+		if endpoint.Spec.ClusterID == clusterID &&
+			(active == nil || active.CreationTimestamp.Before(&endpoint.CreationTimestamp)) {
+			active = endpoint
+		}
+
+		return true
+	})
+
+	return active, active != nil
+}
+
+func (s *handlerStateImpl) HasRemoteCluster(clusterID string) bool {
+	_, found := s.GetGatewayEndpoint(clusterID)
+	return found
+}
+
+func (s *handlerStateImpl) GetEndpointInfo(clusterID string) (*event.EndpointInfo, bool) {
+	endpoint, found := s.GetGatewayEndpoint(clusterID)
+	if !found {
+		return nil, false
+	}
+
+	_, unhealthy := s.unhealthyClusters.Load(clusterID)
+
+	info := &event.EndpointInfo{Endpoint: endpoint, Healthy: !unhealthy}
+
+	if s.addresses != nil {
+		address, err := s.addresses.get(endpoint)
+		if err != nil {
+			s.log.Errorf(err, "Error resolving the address of Endpoint %q", endpoint.Name)
+		}
+
+		info.ResolvedAddress = address
+	}
+
+	if len(endpoint.Annotations) > 0 {
+		info.Annotations = make(map[string]string, len(endpoint.Annotations))
+		for k, v := range endpoint.Annotations {
+			info.Annotations[k] = v
+		}
+	}
+
+	return info, true
+}
+
+func (s *handlerStateImpl) GetEndpointAnnotation(clusterID, key string) (string, bool) {
+	endpoint, found := s.GetGatewayEndpoint(clusterID)
+	if !found {
+		return "", false
+	}
+
+	value, found := endpoint.Annotations[key]
+
+	return value, found
+}
+
 type Controller struct {
-	env             specification
-	resourceWatcher watcher.Interface
+	env              specification
+	resourceWatchers []*resourceWatcher
+	// informerFactories holds the externally supplied informer factories keyed by cluster name.
+	informerFactories map[string]dynamicinformer.DynamicSharedInformerFactory
+	restMapper        meta.RESTMapper
+	partialStart      bool
+	resourcePriority  []string
+	lifecycle         lifecycle
+
+	// watcherConfigs holds the configs used to create the watchers keyed by cluster name, eg for WatchResource.
+	watcherConfigs map[string]watcher.Config
+	// cacheTransform and cacheTransforms are the transforms applied to the watched resources before they're cached.
+	cacheTransform  func(obj *unstructured.Unstructured)
+	cacheTransforms map[string]func(obj *unstructured.Unstructured)
 
-	handlers     *event.Registry
+	handlers     registries
 	handlerState handlerStateImpl
 
-	syncMutex sync.Mutex
-	hostname  string
+	log            log.Logger
+	syncMutex      sync.Mutex
+	hostname       string
+	localEndpoints map[string]*subv1.Endpoint
+	eventFilter    func(eventType event.Type, obj runtime.Object) bool
+	maxObjectBytes int
+	recorder       *eventRecorder
+	initialSync    *initialSync
+	nodeAddrType   k8sv1.NodeAddressType
+	onDrained      func()
+	onWatchError   func(resource string, err error)
+	onEventDropped func(eventType event.Type, obj runtime.Object, reason DropReason)
+	keyedQueue     *keyedQueue
+	retryTracker   retryTracker
+	metrics        metrics
+	capture        eventCapture
+	failOnInitErr  bool
+	gatewayTaint   string
+	skipNotReady   bool
+	clock          clock.WithTickerAndDelayedExecution
+
+	heartbeatInterval     time.Duration
+	errorLogDedup         time.Duration
+	syncTimeout           time.Duration
+	nodeUpdateWindow      time.Duration
+	handlersReadyTimeout  time.Duration
+	stalledEventThreshold time.Duration
+	onEventStalled        func(stalled StalledEvent)
+	onGatewayLatency      func(latency time.Duration)
+	watchdog              watchdog
+
+	knownClusters             KnownClustersSource
+	orphanedEndpointsInterval time.Duration
+
+	// orphanedEndpoints are the names of the remote Endpoints whose removal was synthesized as their cluster is no longer
+	// known, in order to drop their delete event should it eventually arrive. Guarded by syncMutex.
+	orphanedEndpoints set.Set[string]
+
+	// deepCopyObjects and reverseTeardown are the settings applied to the registries, including one swapped in via
+	// SwapRegistry.
+	deepCopyObjects bool
+	reverseTeardown bool
+
+	// batchKeyFunc returns the key by which remote Endpoint events are batched. It's nil if batching isn't configured.
+	batchKeyFunc func(*subv1.Endpoint) string
+	batchWindow  time.Duration
+
+	// pendingBatches are the remote Endpoint events, keyed by batch key, awaiting dispatch at the end of the batching
+	// window. Guarded by syncMutex.
+	pendingBatches map[string][]event.EndpointEvent
+
+	// pendingNodeUpdates are the latest updated Nodes, keyed by name, awaiting dispatch at the end of the coalescing
+	// window. Guarded by syncMutex.
+	pendingNodeUpdates map[string]*k8sv1.Node
+
+	// restoredEndpoints are the remote Endpoints, keyed by identity, restored from the InitialEndpoints snapshot that
+	// haven't yet been confirmed by the initial sync. Guarded by syncMutex.
+	restoredEndpoints map[string]*subv1.Endpoint
+
+	// preferredGateways are the remote Endpoints annotated as the preferred gateway, keyed by cluster ID. Guarded by
+	// syncMutex.
+	preferredGateways map[string]*subv1.Endpoint
+
+	// warmCache holds the objects loaded from the cache snapshot that haven't yet been reconciled with the live state, keyed
+	// by watched resource type name and then by object key. It's nil if no CacheSnapshotPath is configured. Guarded by
+	// syncMutex.
+	warmCache         map[string]map[string]runtime.Object
+	cacheSnapshotPath string
+
+	// soleGateway indicates whether the local Node was last notified as the only gateway Node. Guarded by syncMutex.
+	soleGateway bool
+
+	// preStarted indicates whether PreStart was invoked on the handlers during Start. Guarded by syncMutex.
+	preStarted bool
+
+	// isLeader is the leadership status last told via SetLeader and gateOnLeadership indicates whether the watched
+	// resource events are gated while not the leader. Guarded by leaderMutex.
+	leaderMutex      sync.Mutex
+	isLeader         bool
+	gateOnLeadership bool
+
+	// ignoredAnnotations are the keys of the Endpoint annotations ignored when comparing Endpoint updates.
+	ignoredAnnotations set.Set[string]
+
+	// identityFunc returns the logical identity by which remote Endpoints are tracked and deduplicated.
+	identityFunc func(*subv1.Endpoint) string
+
+	// endpointValidator validates the notified Endpoints.
+	endpointValidator func(*subv1.Endpoint) error
+
+	filteredEndpoints filteredEndpoints
+
+	// eventLog is the logger for the source event being processed, which includes its correlation ID. Guarded by syncMutex.
+	eventLog log.Logger
 }
 
 // If the handler cannot recover from a failure, even after retrying for maximum requeue attempts,
@@ -88,6 +390,10 @@ type Config struct {
 	// Registry is the event handler registry where controller events will be sent.
 	Registry *event.Registry
 
+	// Registries optionally specifies additional event handler registries to which controller events are mirrored. Each
+	// registry receives identical events, after the primary Registry, and errors are attributed per registry.
+	Registries []*event.Registry
+
 	// RestConfig the REST config used to access the resources to watch.
 	RestConfig *rest.Config
 
@@ -97,11 +403,307 @@ type Config struct {
 	// Client can be provided for unit testing. By default New will create its own dynamic client.
 	Client dynamic.Interface
 
+	// InformerFactory optionally specifies a dynamic shared informer factory from which the informers for the watched
+	// resources are obtained, eg to share the informer caches with other components. The factory is started by Start and
+	// is expected to watch all namespaces. The ListPageSize and CacheTransform settings don't apply to its informers and
+	// watch reconnections and errors aren't reported.
+	InformerFactory dynamicinformer.DynamicSharedInformerFactory
+
 	Scheme *runtime.Scheme
+
+	// EventFilter if specified, is invoked prior to dispatching each event to the registry. If false is returned, the event
+	// is dropped and no handler is notified.
+	EventFilter func(eventType event.Type, obj runtime.Object) bool
+
+	// ListPageSize if non-zero, limits the number of resources returned by each list request issued by the informers so
+	// the initial sync is paginated. By default all resources are retrieved in one response.
+	ListPageSize int64
+
+	// CacheSnapshotPath if specified, is the path of the file to which SaveCacheSnapshot persists the informer caches. On
+	// Start, the handlers are notified of the creation of the Endpoints and Nodes in the snapshot before the watchers have
+	// synced so they can begin processing immediately. As the live state is received, it's reconciled with the snapshot,
+	// ie live Endpoints and Nodes that differ from the snapshot are notified as updated, those that weren't in the snapshot
+	// as created and those in the snapshot that no longer exist as removed. If the file doesn't exist, the controller
+	// starts as usual.
+	CacheSnapshotPath string
+
+	// OnWatchError if specified, is invoked with the watched resource type name, as per SyncStatus, and the error whenever a
+	// list or watch request issued by an informer fails or an error event is received on a watch, eg for alerting on
+	// persistent authorization failures. The error wraps the API error so it can be classified via the k8s.io API errors
+	// package, eg IsForbidden or IsUnauthorized. Forbidden errors additionally name the RBAC rule the service account
+	// needs and are logged regardless. The informers retry on their own. It's invoked synchronously from the informer so
+	// it mustn't block.
+	OnWatchError func(resource string, err error)
+
+	// OnEventDropped if specified, is invoked with each event received from a watcher that's dropped rather than dispatched
+	// to the handlers and the reason, eg to route the events to a dead-letter store. The reason is also included in the
+	// log line as the "dropReason" value. It's invoked synchronously with the controller's lock held so it mustn't block
+	// or call back into the controller.
+	OnEventDropped func(eventType event.Type, obj runtime.Object, reason DropReason)
+
+	// CacheTransform is applied to each resource received by the informers before it's cached, eg to strip heavy fields the
+	// handlers don't use in order to reduce memory. By default, StripManagedFields is applied. Specify a no-op function to
+	// cache the resources as received.
+	CacheTransform func(obj *unstructured.Unstructured)
+
+	// CacheTransforms optionally specifies the transforms applied to the resources of specific watched resource types,
+	// keyed by resource type, eg NodeResource, instead of the CacheTransform, eg to strip different fields from the Nodes
+	// and the Endpoints. The resource types without an entry use the CacheTransform.
+	CacheTransforms map[string]func(obj *unstructured.Unstructured)
+
+	// HeartbeatInterval if non-zero, is the interval at which HeartbeatHandlers are notified via OnHeartbeat once the
+	// controller is started, eg to periodically reconcile their state. By default, no heartbeats are dispatched.
+	HeartbeatInterval time.Duration
+
+	// NodeUpdateCoalescingWindow if non-zero, is the time window over which the updates of a Node are coalesced, ie the
+	// first update of a Node is dispatched once the window elapses and any further updates received in the meantime
+	// supersede it so only the latest Node is dispatched. This reduces the load from frequent Node status updates. Errors
+	// returned by the handlers for coalesced updates are logged and the update isn't retried. By default, each update is
+	// dispatched immediately.
+	NodeUpdateCoalescingWindow time.Duration
+
+	// AddressResolver if specified, is invoked to compute the externally-reachable address of a remote gateway Endpoint,
+	// eg by resolving its hostname, which is then available to the handlers via EndpointInfo.ResolvedAddress. It's invoked
+	// lazily from HandlerState.GetEndpointInfo and the address is cached until the Endpoint is updated or the
+	// ResolvedAddressTTL elapses. Failed resolutions aren't cached.
+	AddressResolver func(endpoint *subv1.Endpoint) (string, error)
+
+	// ResolvedAddressTTL is the time for which an address computed by the AddressResolver is cached. If zero,
+	// DefaultResolvedAddressTTL is used.
+	ResolvedAddressTTL time.Duration
+
+	// WaitForHandlersReady if true, Start waits for the handlers implementing event.ReadinessHandler to be ready, after
+	// invoking PreStart and before starting the watchers, bounded by the HandlersReadyTimeout. If the timeout elapses, the
+	// start fails if FailOnHandlerInitError is set, otherwise the error is logged and the start continues.
+	WaitForHandlersReady bool
+
+	// HandlersReadyTimeout is the maximum time Start waits for the handlers to be ready if WaitForHandlersReady is set. If
+	// zero, DefaultHandlersReadyTimeout is used.
+	HandlersReadyTimeout time.Duration
+
+	// StalledEventThreshold if non-zero, enables a watchdog that detects a watched resource event that has been in flight
+	// for longer than the threshold, eg because a handler is blocked. A stalled event is reported once with a warning
+	// naming the event key and the in-flight handlers, counted in the metrics and passed to OnEventStalled.
+	StalledEventThreshold time.Duration
+
+	// OnEventStalled if specified, is invoked with each event detected as stalled by the watchdog. It's invoked from the
+	// watchdog goroutine, so it mustn't block, and isn't invoked again for the same event.
+	OnEventStalled func(stalled StalledEvent)
+
+	// KnownClusters if specified, is periodically cross-checked against the tracked remote Endpoints at the
+	// OrphanedEndpointsInterval. The removal of the remote Endpoints of clusters no longer known is synthesized, eg if a
+	// decommissioned cluster's Endpoint delete never arrives.
+	KnownClusters KnownClustersSource
+
+	// OrphanedEndpointsInterval is the interval at which the remote Endpoints are cross-checked against KnownClusters.
+	// Default is DefaultOrphanedEndpointsInterval.
+	OrphanedEndpointsInterval time.Duration
+
+	// Clock can be provided for unit testing. By default the real clock is used.
+	Clock clock.WithTickerAndDelayedExecution
+
+	// Logger used by the controller instance, eg to route the logs of multiple controllers in one process. By default
+	// the package logger is used.
+	Logger log.Logger
+
+	// ErrorLogDedupInterval if non-zero, collapses the identical error lines logged for the same event, eg as a handler
+	// fails repeatedly while the event is retried, to avoid flooding the logs. The first line is logged and its repeats
+	// within the interval are suppressed, then the number of suppressed repeats is logged once the interval elapses.
+	ErrorLogDedupInterval time.Duration
+
+	// DeepCopyObjects if true, each object is deep-copied before being passed to each handler so a handler that mutates
+	// an object can't corrupt the object seen by subsequent handlers or the controller's state. This incurs an allocation
+	// per handler per event so it may be disabled if all handlers are known to treat the objects as read-only. Default
+	// is true.
+	DeepCopyObjects *bool
+
+	// ReverseTeardownOrder if true, TransitionToNonGateway and the removal events are dispatched to the handlers in the
+	// reverse order of their registration, eg so dependent handlers set up later are torn down first. All other events are
+	// still dispatched in registration order. Default is false.
+	ReverseTeardownOrder bool
+
+	// MaxObjectBytes if non-zero, is the maximum serialized size of a watched object. Events for larger objects are dropped
+	// with a warning rather than dispatched to the handlers.
+	MaxObjectBytes int
+
+	// Clusters optionally specifies multiple clusters to watch, eg for a hub-and-spoke topology. If specified, the
+	// top-level RestConfig, RestMapper, Client and InformerFactory are ignored and the objects notified to the handlers are
+	// annotated with the name of their origin cluster, which can be retrieved via OriginCluster.
+	Clusters []ClusterConfig
+
+	// SyncTimeout if non-zero, is the maximum time Start waits for the informer caches of the watched resources to sync,
+	// eg if a resource can't be listed due to missing RBAC rules. If it elapses, the watchers are stopped and Start returns
+	// an error naming the resource types that didn't sync. By default, Start waits until the caches sync or the stop
+	// channel is closed.
+	SyncTimeout time.Duration
+
+	// PartialStart if true, Start returns as soon as the informer cache for at least one watched resource type has synced.
+	// The remaining resource types continue to sync in the background. SyncStatus reports the progress.
+	PartialStart bool
+
+	// ResourcePriority optionally specifies watched resource types, eg NodeResource, whose initial events are dispatched
+	// before those of the other resource types during the initial sync, in the given order, eg so the handlers know the
+	// gateway Node before being notified of the Endpoints. The watchers of each resource type are only started once the
+	// initial events of the previous resource type were dispatched, so its events may be retried after those of the next
+	// resource types. Thereafter the events of all resource types are dispatched as received. It can't be combined with
+	// PartialStart.
+	ResourcePriority []string
+
+	// SuppressEventsWhileNotLeader if true, the controller is initially not the leader and the events of the watched
+	// resources are buffered, as per PauseWatcher, while it isn't the leader, as told via SetLeader, eg when running with
+	// leader election. The buffered events are dispatched once it becomes the leader. It can't be combined with
+	// ResourcePriority as the initial events wouldn't be dispatched during Start.
+	SuppressEventsWhileNotLeader bool
+
+	// WatchClusterGlobalEgressIPs if true, ClusterGlobalEgressIP resources are also watched and their events dispatched to
+	// handlers implementing event.ClusterGlobalEgressIPHandler. If the globalnet CRD isn't installed in a cluster, the
+	// watcher is skipped for that cluster with a warning.
+	WatchClusterGlobalEgressIPs bool
+
+	// AuditWriter if specified, each event received from the watchers is recorded to it via the AuditCodec. The recorded
+	// events can later be read back via a ReplaySource and fed into a controller via Replay, eg to reproduce an incident.
+	AuditWriter io.Writer
+
+	// AuditCodec is the EventCodec used to serialize the events recorded to the AuditWriter. By default, each event is
+	// recorded as a JSON line via JSONCodec.
+	AuditCodec EventCodec
+
+	// AuditCompression if true, the events recorded to the AuditWriter are gzip compressed. The compressed stream is
+	// flushed after each event so it's always decodable up to the last recorded event, and it's terminated when the
+	// controller is stopped. A ReplaySource decompresses it transparently.
+	AuditCompression bool
+
+	// BulkInitialEndpoints if true, the Endpoints that exist when the controller starts aren't notified individually as
+	// they're received during the initial sync. Instead, once the Endpoint informer cache has synced, handlers implementing
+	// event.InitialEndpointsHandler are notified of all of them in a single OnInitialEndpoints call while the other
+	// handlers are notified of each Endpoint's creation. Handlers notified of the remote Endpoint events in batches, as per
+	// RemoteEndpointBatchKey, are notified of the remote Endpoints' creation in batches instead. If the handlers fail, the
+	// bulk notification is retried with backoff and includes the Endpoint events received meanwhile.
+	BulkInitialEndpoints bool
+
+	// InitialEndpoints if specified, is a snapshot of the remote Endpoints, as returned by Controller.Snapshot, restored
+	// into the handler state on construction, eg to avoid disrupting the datapath on a controlled restart. Handlers are
+	// only notified of the creation of remote Endpoints that weren't restored. A restored Endpoint that changed is notified
+	// as updated and one that no longer exists after the initial sync is notified as removed. It can't be combined with
+	// BulkInitialEndpoints.
+	InitialEndpoints []byte
+
+	// PreferredNodeAddressType if specified, is the type of the local Node's address (InternalIP, ExternalIP or Hostname)
+	// used to identify the local Endpoint that makes this node the gateway. The first address of the type is matched
+	// against the Endpoint's private IP or, for the Hostname type, against the Endpoint's hostname. By default, or if the
+	// local Node has no address of the type, the Endpoint's hostname is matched against this host's name.
+	PreferredNodeAddressType k8sv1.NodeAddressType
+
+	// OnDrainComplete if specified, is invoked once all handlers in all registries have successfully processed a
+	// TransitionToNonGateway event, eg to signal that a rolling upgrade may proceed. If a handler fails, the event is
+	// retried and OnDrainComplete is invoked when the retry succeeds. It's invoked from the event processing path, so it
+	// should not block.
+	OnDrainComplete func()
+
+	// OnGatewayTransitionLatency if specified, is invoked once all handlers in all registries have successfully processed
+	// a transition of the local node to a gateway, with the time from the start of the TransitionToGateway notification
+	// until the last handler completed it. The latency is also observed by the
+	// submariner_event_gateway_transition_duration_seconds histogram. It's invoked from the event processing path, so it
+	// should not block.
+	OnGatewayTransitionLatency func(latency time.Duration)
+
+	// ConfirmRemoteEndpointDeletes if true, before notifying the handlers of a RemoteEndpointRemoved event, the removal is
+	// confirmed via a direct Get from the API server so a spurious delete, eg resulting from a transient watch error,
+	// doesn't cause a still-valid tunnel to be torn down. If the Endpoint still exists, the event is dropped.
+	ConfirmRemoteEndpointDeletes bool
+
+	// KeyFunc if specified, overrides the namespace/name key by which the events received from the watchers are queued
+	// for dispatch. Events with the same key are dispatched in order, one at a time, and a pending event is merged with a
+	// subsequent event with the same key: a create followed by an update is dispatched as a create of the updated object,
+	// a create followed by a delete isn't dispatched at all and an event followed by another of the same type is
+	// superseded, so only the latter is dispatched. Requeued events are retried by key.
+	KeyFunc func(obj runtime.Object) string
+
+	// FailOnHandlerInitError if true, AddHandlers registers the given handlers all or nothing, ie if a handler fails to be
+	// added, the handlers already added by the call are stopped and removed. By default, the remaining handlers are still
+	// added. It also causes Start to fail if a handler's PreStart returns an error, which is otherwise only logged.
+	FailOnHandlerInitError bool
+
+	// IneligibleGatewayTaint if specified, is the key of a taint that marks a Node as ineligible to be a gateway. While the
+	// local Node carries the taint, it isn't considered a gateway, regardless of its GatewayLabel or local Endpoint, and
+	// if it was a gateway when tainted, the handlers are notified of the transition to non-gateway.
+	IneligibleGatewayTaint string
+
+	// SuppressEndpointsOnNotReadyNodes if true, the create and update events of an Endpoint whose Node is NotReady are
+	// dropped, with DropReasonNodeNotReady, rather than dispatched to the handlers. The Node is correlated via the
+	// Endpoint's hostname among the watched Nodes. An Endpoint whose Node isn't found, eg a remote Endpoint, is dispatched
+	// as usual, as are removals. Suppressed events aren't replayed once the Node becomes Ready - the Endpoint's next event
+	// is dispatched.
+	SuppressEndpointsOnNotReadyNodes bool
+
+	// IgnoredEndpointAnnotations are the keys of the Endpoint annotations whose changes alone don't cause an Endpoint
+	// update to be dispatched, eg periodically refreshed heartbeats. If nil, DefaultIgnoredEndpointAnnotations is used.
+	// An empty slice dispatches all annotation changes.
+	IgnoredEndpointAnnotations []string
+
+	// EndpointIdentity returns the logical identity of a remote Endpoint by which the controller tracks the remote
+	// Endpoints and deduplicates their events, eg so an Endpoint recreated under a different name during a cluster rename
+	// is seen as an update of the same Endpoint. If nil, DefaultEndpointIdentity is used.
+	EndpointIdentity func(*subv1.Endpoint) string
+
+	// EndpointValidator validates each notified Endpoint before it's tracked or dispatched to the handlers. The events of
+	// Endpoints for which it returns an error are dropped, logged and counted in the metrics. If nil,
+	// DefaultEndpointValidator is used.
+	EndpointValidator func(*subv1.Endpoint) error
+
+	// RemoteEndpointBatchKey if specified, returns the key by which the remote Endpoint events are batched for handlers
+	// implementing event.RemoteEndpointBatchHandler, eg ClusterIDBatchKey. The first event with a key starts a batch that's
+	// dispatched via a single OnRemoteEndpointBatch call once the RemoteEndpointBatchWindow elapses, including the
+	// subsequent events with the same key received in the meantime. Such handlers are no longer notified of the remote
+	// Endpoint events individually while the other handlers are notified as usual. Errors returned by the handlers for a
+	// batch are logged and the batch isn't retried.
+	RemoteEndpointBatchKey func(*subv1.Endpoint) string
+
+	// RemoteEndpointBatchWindow is the time window over which the remote Endpoint events with the same batch key are
+	// batched. If zero, DefaultRemoteEndpointBatchWindow is used.
+	RemoteEndpointBatchWindow time.Duration
+
+	// MaxQueuedEvents if non-zero, bounds the number of events received from the watchers that are queued awaiting
+	// dispatch. Once the bound is reached, the intake of watch events is paused until the handlers catch up so a slow
+	// handler can't cause unbounded memory growth. As for KeyFunc, a pending event is merged with a subsequent event for
+	// the same object.
+	MaxQueuedEvents int
+
+	// SynchronousDispatch is intended for unit tests only and is unsuitable for production. If true, each event received
+	// from a watcher is processed inline before the watcher callback returns, ie the KeyFunc, MaxQueuedEvents and
+	// NodeUpdateCoalescingWindow settings are ignored, so the handlers are notified deterministically in the order in
+	// which the events are delivered. Note the watchers still deliver the events asynchronously from the API calls.
+	SynchronousDispatch bool
+}
+
+// ClusterConfig specifies how to access the resources to watch in a cluster.
+type ClusterConfig struct {
+	// Name of the cluster with which the objects originating from the cluster are labeled.
+	Name string
+
+	// RestConfig the REST config used to access the resources to watch in the cluster.
+	RestConfig *rest.Config
+
+	// RestMapper can be provided for unit testing. By default New will create its own RestMapper.
+	RestMapper meta.RESTMapper
+
+	// Client can be provided for unit testing. By default New will create its own dynamic client.
+	Client dynamic.Interface
+
+	// InformerFactory optionally specifies a dynamic shared informer factory for the cluster, as per
+	// Config.InformerFactory.
+	InformerFactory dynamicinformer.DynamicSharedInformerFactory
 }
 
 var logger = log.Logger{Logger: logf.Log.WithName("EventController")}
 
+// addToScheme adds the submariner types to the default scheme once, as re-adding them mutates the scheme which may
+// concurrently be in use by the watchers of another controller.
+var addToScheme = struct {
+	once sync.Once
+	err  error
+}{}
+
 func New(config *Config) (*Controller, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -109,8 +711,148 @@ func New(config *Config) (*Controller, error) {
 	}
 
 	ctl := Controller{
-		handlers: config.Registry,
-		hostname: hostname,
+		handlers:          append(registries{config.Registry}, config.Registries...),
+		log:               logger,
+		hostname:          hostname,
+		localEndpoints:    map[string]*subv1.Endpoint{},
+		informerFactories: map[string]dynamicinformer.DynamicSharedInformerFactory{},
+		watcherConfigs:    map[string]watcher.Config{},
+		cacheTransform:    config.CacheTransform,
+		cacheTransforms:   config.CacheTransforms,
+		retryTracker:      retryTracker{retries: map[string]int{}},
+		eventFilter:       config.EventFilter,
+		partialStart:      config.PartialStart,
+		resourcePriority:  config.ResourcePriority,
+		gateOnLeadership:  config.SuppressEventsWhileNotLeader,
+		maxObjectBytes:    config.MaxObjectBytes,
+		nodeAddrType:      config.PreferredNodeAddressType,
+		onDrained:         config.OnDrainComplete,
+		onWatchError:      config.OnWatchError,
+		onEventDropped:    config.OnEventDropped,
+		failOnInitErr:     config.FailOnHandlerInitError,
+		gatewayTaint:      config.IneligibleGatewayTaint,
+		skipNotReady:      config.SuppressEndpointsOnNotReadyNodes,
+		clock:             config.Clock,
+
+		heartbeatInterval: config.HeartbeatInterval,
+		errorLogDedup:     config.ErrorLogDedupInterval,
+		syncTimeout:       config.SyncTimeout,
+		nodeUpdateWindow:  config.NodeUpdateCoalescingWindow,
+
+		stalledEventThreshold: config.StalledEventThreshold,
+		onEventStalled:        config.OnEventStalled,
+		onGatewayLatency:      config.OnGatewayTransitionLatency,
+
+		knownClusters:             config.KnownClusters,
+		orphanedEndpointsInterval: config.OrphanedEndpointsInterval,
+		orphanedEndpoints:         set.New[string](),
+
+		pendingNodeUpdates: map[string]*k8sv1.Node{},
+		pendingBatches:     map[string][]event.EndpointEvent{},
+	}
+
+	if ctl.clock == nil {
+		ctl.clock = clock.RealClock{}
+	}
+
+	if ctl.cacheTransform == nil {
+		ctl.cacheTransform = StripManagedFields
+	}
+
+	if ctl.orphanedEndpointsInterval == 0 {
+		ctl.orphanedEndpointsInterval = DefaultOrphanedEndpointsInterval
+	}
+
+	if config.WaitForHandlersReady {
+		ctl.handlersReadyTimeout = config.HandlersReadyTimeout
+		if ctl.handlersReadyTimeout == 0 {
+			ctl.handlersReadyTimeout = DefaultHandlersReadyTimeout
+		}
+	}
+
+	ctl.ignoredAnnotations = set.New(DefaultIgnoredEndpointAnnotations...)
+	if config.IgnoredEndpointAnnotations != nil {
+		ctl.ignoredAnnotations = set.New(config.IgnoredEndpointAnnotations...)
+	}
+
+	ctl.identityFunc = config.EndpointIdentity
+	if ctl.identityFunc == nil {
+		ctl.identityFunc = DefaultEndpointIdentity
+	}
+
+	if config.RemoteEndpointBatchKey != nil {
+		ctl.batchKeyFunc = config.RemoteEndpointBatchKey
+
+		ctl.batchWindow = config.RemoteEndpointBatchWindow
+		if ctl.batchWindow == 0 {
+			ctl.batchWindow = DefaultRemoteEndpointBatchWindow
+		}
+	}
+
+	ctl.endpointValidator = config.EndpointValidator
+	if ctl.endpointValidator == nil {
+		ctl.endpointValidator = DefaultEndpointValidator
+	}
+
+	switch config.PreferredNodeAddressType {
+	case "", k8sv1.NodeInternalIP, k8sv1.NodeExternalIP, k8sv1.NodeHostName:
+	default:
+		return nil, errors.Errorf("unsupported PreferredNodeAddressType %q", config.PreferredNodeAddressType)
+	}
+
+	if config.Logger.GetSink() != nil {
+		ctl.log = config.Logger
+	}
+
+	if ctl.errorLogDedup > 0 {
+		ctl.log = log.Logger{Logger: logr.New(newDedupingSink(ctl.log.GetSink(), ctl.errorLogDedup, ctl.clock))}
+	}
+
+	ctl.eventLog = ctl.log
+
+	ctl.retryTracker.metrics = &ctl.metrics
+
+	config.Registry.SetObserver(ctl.capture.record)
+
+	if config.CacheSnapshotPath != "" {
+		ctl.cacheSnapshotPath = config.CacheSnapshotPath
+		ctl.warmCache = map[string]map[string]runtime.Object{}
+	}
+
+	if config.BulkInitialEndpoints {
+		if config.InitialEndpoints != nil {
+			return nil, errors.New("InitialEndpoints can't be combined with BulkInitialEndpoints")
+		}
+
+		ctl.initialSync = newInitialSync()
+	}
+
+	if config.PartialStart && len(config.ResourcePriority) > 0 {
+		return nil, errors.New("ResourcePriority can't be combined with PartialStart")
+	}
+
+	if config.SuppressEventsWhileNotLeader && len(config.ResourcePriority) > 0 {
+		return nil, errors.New("ResourcePriority can't be combined with SuppressEventsWhileNotLeader")
+	}
+
+	if config.SynchronousDispatch {
+		ctl.nodeUpdateWindow = 0
+	} else if config.KeyFunc != nil || config.MaxQueuedEvents > 0 {
+		ctl.keyedQueue = newKeyedQueue(config.KeyFunc, config.MaxQueuedEvents)
+		ctl.keyedQueue.onSuperseded = ctl.metrics.forgetReceived
+	}
+
+	if config.AuditWriter != nil {
+		ctl.recorder = newEventRecorder(config.AuditWriter, config.AuditCodec, config.AuditCompression, ctl.log)
+	}
+
+	ctl.deepCopyObjects = config.DeepCopyObjects == nil || *config.DeepCopyObjects
+	ctl.reverseTeardown = config.ReverseTeardownOrder
+
+	for _, registry := range ctl.handlers {
+		registry.SetDeepCopyObjects(ctl.deepCopyObjects)
+		registry.SetReverseTeardownOrder(ctl.reverseTeardown)
+		registry.SetBatchRemoteEndpoints(ctl.batchKeyFunc != nil)
 	}
 
 	err = envconfig.Process("submariner", &ctl.env)
@@ -118,41 +860,68 @@ func New(config *Config) (*Controller, error) {
 		return nil, errors.Wrap(err, "error processing env vars")
 	}
 
-	err = subv1.AddToScheme(scheme.Scheme)
-	if err != nil {
-		return nil, errors.Wrap(err, "error adding submariner types to the scheme")
+	ctl.handlerState.clusterID = ctl.env.ClusterID
+	ctl.handlerState.localNodeName = ctl.hostname
+	ctl.handlerState.log = ctl.log
+
+	if config.AddressResolver != nil {
+		ctl.handlerState.addresses = &addressCache{
+			resolve: config.AddressResolver,
+			ttl:     config.ResolvedAddressTTL,
+			clock:   ctl.clock,
+			entries: map[string]*resolvedAddress{},
+		}
+
+		if ctl.handlerState.addresses.ttl == 0 {
+			ctl.handlerState.addresses.ttl = DefaultResolvedAddressTTL
+		}
 	}
 
-	ctl.resourceWatcher, err = watcher.New(&watcher.Config{
-		Scheme:     config.Scheme,
-		RestConfig: config.RestConfig,
-		ResourceConfigs: []watcher.ResourceConfig{
-			{
-				Name:            fmt.Sprintf("Endpoint watcher for %s registry", ctl.handlers.GetName()),
-				ResourceType:    &subv1.Endpoint{},
-				SourceNamespace: ctl.env.Namespace,
-				Handler: watcher.EventHandlerFuncs{
-					OnCreateFunc: ctl.handleCreatedEndpoint,
-					OnUpdateFunc: ctl.handleUpdatedEndpoint,
-					OnDeleteFunc: ctl.handleRemovedEndpoint,
-				},
-			}, {
-				Name:                fmt.Sprintf("Node watcher for %s registry", ctl.handlers.GetName()),
-				ResourceType:        &k8sv1.Node{},
-				ResourcesEquivalent: ctl.isNodeEquivalent,
-				Handler: watcher.EventHandlerFuncs{
-					OnCreateFunc: ctl.handleCreatedNode,
-					OnUpdateFunc: ctl.handleUpdatedNode,
-					OnDeleteFunc: ctl.handleRemovedNode,
-				},
-			},
-		},
-		Client:     config.Client,
-		RestMapper: config.RestMapper,
+	ctl.handlerState.listNodes = func() []runtime.Object {
+		return ctl.listResources(NodeResource, &k8sv1.Node{})
+	}
+	ctl.preferredGateways = map[string]*subv1.Endpoint{}
+
+	if config.InitialEndpoints != nil {
+		if err := ctl.restoreSnapshot(config.InitialEndpoints); err != nil {
+			return nil, err
+		}
+	}
+	ctl.handlerState.ctx, ctl.handlerState.cancel = context.WithCancel(context.Background())
+
+	if ctl.env.EventTrace {
+		for _, registry := range ctl.handlers {
+			registry.SetTracer(&ctl.log)
+		}
+	}
+
+	addToScheme.once.Do(func() {
+		addToScheme.err = subv1.AddToScheme(scheme.Scheme)
 	})
 
+	err = addToScheme.err
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating resource watcher")
+		return nil, errors.Wrap(err, "error adding submariner types to the scheme")
+	}
+
+	clusters := config.Clusters
+	if len(clusters) == 0 {
+		clusters = []ClusterConfig{{
+			RestConfig: config.RestConfig, RestMapper: config.RestMapper, Client: config.Client,
+			InformerFactory: config.InformerFactory,
+		}}
+	}
+
+	for i := range clusters {
+		if err := ctl.addClusterWatchers(&clusters[i], config); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, resource := range ctl.resourcePriority {
+		if len(ctl.watchersOf(resource)) == 0 {
+			return nil, errors.Errorf("the %s resource in ResourcePriority is not watched", resource)
+		}
 	}
 
 	ctl.handlers.SetHandlerState(&ctl.handlerState)
@@ -162,22 +931,441 @@ func New(config *Config) (*Controller, error) {
 
 // Start starts the controller.
 func (c *Controller) Start(stopCh <-chan struct{}) error {
-	logger.Info("Starting the Event controller...")
+	if !c.lifecycle.transition(LifecycleNew, LifecycleStarting) {
+		return errors.New("the event controller has already been started")
+	}
+
+	if err := c.start(stopCh); err != nil {
+		c.lifecycle.transition(LifecycleStarting, LifecycleStopped)
+		return err
+	}
+
+	c.syncMutex.Lock()
+
+	c.lifecycle.transition(LifecycleStarting, LifecycleRunning)
+
+	if err := c.handlers.ControllerStarted(); err != nil {
+		c.log.Error(err, "Error notifying the event handlers that the controller started")
+	}
+
+	c.syncMutex.Unlock()
+
+	c.log.Info("Event controller started")
+
+	return nil
+}
+
+func (c *Controller) start(stopCh <-chan struct{}) error {
+	c.log.Info("Starting the Event controller...")
+
+	if err := c.preStartHandlers(); err != nil {
+		return err
+	}
+
+	if c.handlersReadyTimeout > 0 {
+		if err := c.waitForHandlersReady(stopCh); err != nil {
+			return err
+		}
+	}
+
+	if c.warmCache != nil {
+		c.loadCacheSnapshot()
+	}
+
+	if c.keyedQueue != nil {
+		c.keyedQueue.run(stopCh)
+	}
+
+	var err error
+
+	watchStopCh, syncDone := c.withSyncTimeout(stopCh)
 
-	err := c.resourceWatcher.Start(stopCh)
+	if c.partialStart {
+		err = c.startWatchersPartially(watchStopCh)
+	} else if len(c.resourcePriority) > 0 {
+		err = c.startWatchersByPriority(watchStopCh)
+	} else {
+		err = c.startWatchers(watchStopCh)
+	}
+
+	if syncDone() {
+		return c.syncTimedOut()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if c.heartbeatInterval > 0 {
+		go c.runHeartbeats(c.heartbeatInterval, stopCh)
+	}
+
+	if c.stalledEventThreshold > 0 {
+		go c.runWatchdog(stopCh)
+	}
+
+	if c.knownClusters != nil {
+		go c.runOrphanedEndpointsReconciler(stopCh)
+	}
+
+	return nil
+}
+
+// preStartHandlers invokes PreStart on the handlers before any event is dispatched. If FailOnHandlerInitError is set, an
+// error aborts the start, otherwise it's logged.
+func (c *Controller) preStartHandlers() error {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	c.preStarted = true
+
+	err := c.handlers.PreStart(&c.handlerState)
+	if err == nil {
+		return nil
+	}
+
+	if c.failOnInitErr {
+		return errors.Wrap(err, "error invoking PreStart on the event handlers")
+	}
+
+	c.log.Error(err, "Error invoking PreStart on the event handlers")
+
+	return nil
+}
+
+// QueuedEvents returns the number of events received from the watchers awaiting dispatch if a KeyFunc or MaxQueuedEvents
+// is configured, otherwise 0.
+func (c *Controller) QueuedEvents() int {
+	if c.keyedQueue == nil {
+		return 0
+	}
+
+	return c.keyedQueue.len()
+}
+
+// LastSuccess returns the last time the given event type was processed successfully or the zero time if it never was. If
+// multiple registries are configured, the earliest of their last success times is returned.
+func (c *Controller) LastSuccess(eventType event.Type) time.Time {
+	var last time.Time
+
+	for i, registry := range c.handlers {
+		t := registry.LastSuccess(eventType)
+		if i == 0 || t.Before(last) {
+			last = t
+		}
+	}
+
+	return last
+}
+
+// ClusterID returns the ID of the local cluster.
+func (c *Controller) ClusterID() string {
+	return c.env.ClusterID
+}
+
+// Namespace returns the namespace in which the controller watches the namespaced resources, ie the Endpoints, as
+// configured via the SUBMARINER_NAMESPACE environment variable. Handlers can reuse it, eg to create resources alongside
+// the Endpoints. Cluster-scoped resources, eg Nodes, are watched regardless.
+func (c *Controller) Namespace() string {
+	return c.env.Namespace
+}
+
+// RestMapper returns the RESTMapper used by the controller, either the one provided in the Config or the one created by
+// New. If multiple Clusters are configured, the RESTMapper for the first cluster is returned. Handlers can reuse it
+// rather than building their own.
+func (c *Controller) RestMapper() meta.RESTMapper {
+	return c.restMapper
+}
+
+// transitionToGateway notifies the handlers of the transition to a gateway node and, once they've all completed
+// successfully, records the time they took.
+func (c *Controller) transitionToGateway() error {
+	started := c.clock.Now()
+
+	err := c.handlers.TransitionToGateway()
 	if err != nil {
-		return errors.Wrap(err, "error starting the resource watcher")
+		return err
 	}
 
-	logger.Info("Event controller started")
+	latency := c.clock.Since(started)
+	c.metrics.recordGatewayTransition(latency)
+
+	c.eventLog.V(log.DEBUG).Infof("The handlers completed the transition to gateway in %v", latency)
+
+	if c.onGatewayLatency != nil {
+		c.onGatewayLatency(latency)
+	}
 
 	return nil
 }
 
+// transitionToNonGateway notifies the handlers of the transition to a non-gateway node and, once they've all completed
+// successfully, signals that the node is drained.
+func (c *Controller) transitionToNonGateway() error {
+	if err := c.handlers.TransitionToNonGateway(); err != nil {
+		return err
+	}
+
+	c.metrics.nonGatewayTransitions.Add(1)
+
+	if c.onDrained != nil {
+		c.onDrained()
+	}
+
+	return nil
+}
+
+// AwaitGatewayState blocks until whether or not the local node is a gateway matches the given onGateway value or the given
+// context ends, in which case the context's error is returned.
+func (c *Controller) AwaitGatewayState(ctx context.Context, onGateway bool) error {
+	cond := c.handlerState.gatewayStateCond()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cond.L.Lock()
+			defer cond.L.Unlock()
+
+			cond.Broadcast()
+		case <-done:
+		}
+	}()
+
+	cond.L.Lock()
+	defer cond.L.Unlock()
+
+	for c.handlerState.IsOnGateway() != onGateway {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "error awaiting the gateway state %v", onGateway)
+		}
+
+		cond.Wait()
+	}
+
+	return nil
+}
+
+// AddHandler adds the given Handler to the controller's registry. This may be called after the controller is started,
+// in which case the Handler is initialized, PreStart is invoked if it's a PreStartHandler and the current state is replayed
+// to it, ie the local and remote Endpoints are notified as created and, if the local node is a gateway,
+// TransitionToGateway is invoked. OnControllerStarted is then invoked if it's a ControllerLifecycleHandler. Thereafter the
+// Handler receives live notifications.
+func (c *Controller) AddHandler(h event.Handler) error {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	_, err := c.addHandler(h)
+
+	return err
+}
+
+// AddHandlers adds each of the given Handlers, as per AddHandler, and returns the aggregate of the errors. If
+// FailOnHandlerInitError is set, it stops at the first failure and rolls back the Handlers already added, ie they're
+// stopped and removed.
+func (c *Controller) AddHandlers(hs ...event.Handler) error {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	var (
+		added []event.Handler
+		errs  []error
+	)
+
+	for _, h := range hs {
+		ok, err := c.addHandler(h)
+		if ok {
+			added = append(added, h)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		errs = append(errs, err)
+
+		if c.failOnInitErr {
+			c.rollbackHandlers(added)
+			break
+		}
+	}
+
+	return errors.Wrap(k8serrors.NewAggregate(errs), "error adding event handlers")
+}
+
+// addHandler adds the given Handler and replays the current state to it. Returns true if the Handler was added to the
+// registry, even if the replay failed.
+func (c *Controller) addHandler(h event.Handler) (bool, error) {
+	added, err := c.handlers.AddHandler(h)
+	if err != nil || !added {
+		return false, err //nolint:wrapcheck  // Let the caller wrap it
+	}
+
+	h.SetState(&c.handlerState)
+
+	if ph, ok := h.(event.PreStartHandler); ok && c.preStarted {
+		if err := ph.PreStart(&c.handlerState); err != nil {
+			return true, errors.Wrapf(err, "error invoking PreStart on handler %q", h.GetName())
+		}
+	}
+
+	if err := c.replayState(h); err != nil {
+		return true, err
+	}
+
+	if lh, ok := h.(event.ControllerLifecycleHandler); ok && c.lifecycle.get() == LifecycleRunning {
+		if err := lh.OnControllerStarted(); err != nil {
+			return true, errors.Wrapf(err, "error invoking OnControllerStarted on handler %q", h.GetName())
+		}
+	}
+
+	return true, nil
+}
+
+func (c *Controller) rollbackHandlers(hs []event.Handler) {
+	for i := len(hs) - 1; i >= 0; i-- {
+		c.handlers.RemoveHandler(hs[i].GetName())
+
+		if err := hs[i].Stop(); err != nil {
+			c.eventLog.Warningf("Error stopping event handler %q on rollback: %v", hs[i].GetName(), err)
+		}
+	}
+}
+
+// SetHandlerEnabled enables or disables event notifications for the handler with the given name, eg for feature-flag
+// driven operations. A disabled handler receives no event callbacks but it isn't stopped or re-initialized and the
+// handler state continues to be maintained.
+func (c *Controller) SetHandlerEnabled(name string, enabled bool) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	if !c.handlers.SetHandlerEnabled(name, enabled) {
+		c.log.Warningf("Event handler %q not found", name)
+	}
+}
+
+// replayState notifies the given handler of copies of the tracked Endpoints so it can't mutate the controller's state.
+func (c *Controller) replayState(h event.Handler) error {
+	for _, endpoint := range c.localEndpoints {
+		if err := h.LocalEndpointCreated(endpoint.DeepCopy()); err != nil {
+			return errors.Wrapf(err, "error replaying local Endpoint %q to handler %q", endpoint.Name, h.GetName())
+		}
+	}
+
+	if c.handlerState.wasOnGateway.Load() {
+		if err := h.TransitionToGateway(); err != nil {
+			return errors.Wrapf(err, "error replaying TransitionToGateway to handler %q", h.GetName())
+		}
+	}
+
+	var err error
+
+	c.handlerState.remoteEndpoints.Range(func(_, value any) bool {
+		endpoint := value.(*subv1.Endpoint)
+
+		err = h.RemoteEndpointCreated(endpoint.DeepCopy())
+		if err != nil {
+			err = errors.Wrapf(err, "error replaying remote Endpoint %q to handler %q", endpoint.Name, h.GetName())
+		}
+
+		return err == nil
+	})
+
+	return err
+}
+
+func (c *Controller) shouldDispatch(eventType event.Type, obj runtime.Object) bool {
+	c.trace("Event %q received for %T %q", eventType, obj, resourceName(obj))
+
+	if c.maxObjectBytes > 0 {
+		if size := objectSize(obj); size > c.maxObjectBytes {
+			c.dropEvent(eventType, obj, DropReasonOversized, "its size of %d bytes exceeds the maximum of %d bytes", size,
+				c.maxObjectBytes)
+			return false
+		}
+	}
+
+	if c.eventFilter != nil && !c.eventFilter(eventType, obj) {
+		c.dropEvent(eventType, obj, DropReasonFiltered, "rejected by the event filter")
+		return false
+	}
+
+	if nodeName, notReady := c.isOnNotReadyNode(eventType, obj); notReady {
+		c.dropEvent(eventType, obj, DropReasonNodeNotReady, "its Node %q is NotReady", nodeName)
+		return false
+	}
+
+	return true
+}
+
+// beginEvent assigns a new correlation ID to the source event being processed. The ID is shared by all the resulting
+// handler notifications, via HandlerState.GetCorrelationID, and included in the event's logs and traces. The returned
+// function ends the event. Must be called with the syncMutex held.
+func (c *Controller) beginEvent() func() {
+	id := string(uuid.NewUUID())
+
+	c.handlerState.correlationID.Store(id)
+	c.eventLog = log.Logger{Logger: c.log.WithValues("correlationID", id)}
+
+	return func() {
+		c.handlerState.correlationID.Store("")
+		c.eventLog = c.log
+	}
+}
+
+// trace must be called with the syncMutex held.
+func (c *Controller) trace(format string, args ...interface{}) {
+	if c.env.EventTrace {
+		c.eventLog.V(log.DEBUG).Infof("Trace: "+format, args...)
+	}
+}
+
+func objectSize(obj runtime.Object) int {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return 0
+	}
+
+	return len(data)
+}
+
+func resourceName(obj runtime.Object) string {
+	if m, err := meta.Accessor(obj); err == nil {
+		return m.GetName()
+	}
+
+	return ""
+}
+
+// Stop stops the handlers and cancels the handler state's context. Subsequent invocations do nothing.
 func (c *Controller) Stop() {
-	logger.Info("Event controller stopping")
+	if !c.lifecycle.beginStop() {
+		return
+	}
+
+	defer c.lifecycle.transition(LifecycleStopping, LifecycleStopped)
+
+	c.log.Info("Event controller stopping")
+
+	if err := c.handlers.ControllerStopping(); err != nil {
+		c.log.Warningf("In Event Controller, ControllerStopping returned error: %v", err)
+	}
+
+	c.handlerState.cancel()
 
 	if err := c.handlers.StopHandlers(); err != nil {
-		logger.Warningf("In Event Controller, StopHandlers returned error: %v", err)
+		c.log.Warningf("In Event Controller, StopHandlers returned error: %v", err)
 	}
+
+	if c.recorder != nil {
+		c.recorder.close()
+	}
+
+	c.metrics.forgetAllReceived()
 }
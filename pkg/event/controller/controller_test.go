@@ -19,13 +19,49 @@ limitations under the License.
 package controller_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/go-logr/logr/funcr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
 	submV1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	"github.com/submariner-io/submariner/pkg/event"
+	"github.com/submariner-io/submariner/pkg/event/controller"
 	"github.com/submariner-io/submariner/pkg/event/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -53,26 +89,4255 @@ var _ = Describe("Event controller", func() {
 		})
 	})
 
+	When("the cached Nodes are retrieved via the handler state", func() {
+		It("should return copies of the Nodes", func() {
+			Expect(t.handler.State().GetNodes()).To(BeEmpty())
+
+			node2 := t.CreateNode(testing.NewNode("node2"))
+			t.awaitEvent(testing.EvNodeCreated, node2)
+
+			node1 := testing.NewNode("node1")
+			node1.Labels = map[string]string{"label": "value"}
+			node1 = t.CreateNode(node1)
+			t.awaitEvent(testing.EvNodeCreated, node1)
+
+			nodes := t.handler.State().GetNodes()
+			Expect(nodes).To(Equal([]corev1.Node{*node1, *node2}))
+
+			nodes[0].Labels["label"] = "mutated"
+			Expect(t.handler.State().GetNodes()[0].Labels).To(HaveKeyWithValue("label", "value"))
+
+			t.DeleteNode(node2.Name)
+			t.awaitEvent(testing.EvNodeRemoved, node2)
+			Expect(t.handler.State().GetNodes()).To(Equal([]corev1.Node{*node1}))
+		})
+
+		It("should flag the local Node in the NodeInfos", func() {
+			Expect(t.handler.State().GetLocalNodeName()).To(Equal(t.Hostname))
+
+			other := t.CreateNode(testing.NewNode("a-other-node"))
+			t.awaitEvent(testing.EvNodeCreated, other)
+
+			local := t.CreateNode(testing.NewNode(t.Hostname))
+			t.awaitEvent(testing.EvNodeCreated, local)
+
+			Expect(t.handler.State().GetNodeInfos()).To(Equal([]event.NodeInfo{
+				{Node: other},
+				{Node: local, IsLocal: true},
+			}))
+		})
+		It("should return the names of the Nodes labeled as gateways", func() {
+			Expect(t.handler.State().GetGatewayNodeNames()).To(BeEmpty())
+
+			gateway2 := testing.NewNode("gateway2")
+			gateway2.Labels = map[string]string{controller.GatewayLabel: "true"}
+			gateway2 = t.CreateNode(gateway2)
+			t.awaitEvent(testing.EvNodeCreated, gateway2)
+
+			gateway1 := testing.NewNode("gateway1")
+			gateway1.Labels = map[string]string{controller.GatewayLabel: "true"}
+			gateway1 = t.CreateNode(gateway1)
+			t.awaitEvent(testing.EvNodeCreated, gateway1)
+
+			other := testing.NewNode("other")
+			other.Labels = map[string]string{controller.GatewayLabel: "false"}
+			other = t.CreateNode(other)
+			t.awaitEvent(testing.EvNodeCreated, other)
+
+			Expect(t.handler.State().GetGatewayNodeNames()).To(Equal([]string{"gateway1", "gateway2"}))
+
+			By("Unlabeling a gateway Node")
+
+			gateway2.Labels = nil
+			t.UpdateNode(gateway2)
+			t.awaitEvent(testing.EvNodeUpdated, gateway2)
+
+			Expect(t.handler.State().GetGatewayNodeNames()).To(Equal([]string{"gateway1"}))
+
+			By("Labeling another Node")
+
+			other.Labels[controller.GatewayLabel] = "true"
+			t.UpdateNode(other)
+			t.awaitEvent(testing.EvNodeUpdated, other)
+
+			Expect(t.handler.State().GetGatewayNodeNames()).To(Equal([]string{"gateway1", "other"}))
+
+			t.DeleteNode(gateway1.Name)
+			t.awaitEvent(testing.EvNodeRemoved, gateway1)
+
+			Expect(t.handler.State().GetGatewayNodeNames()).To(Equal([]string{"other"}))
+		})
+
+		It("should look up a Node by its name or hostname address", func() {
+			_, found := t.handler.State().GetNodeByHostname("node1")
+			Expect(found).To(BeFalse())
+
+			node1 := testing.NewNode("node1")
+			node1.Status.Addresses = []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "192.168.1.1"},
+				{Type: corev1.NodeHostName, Address: "node1.example.com"},
+			}
+			node1 = t.CreateNode(node1)
+			t.awaitEvent(testing.EvNodeCreated, node1)
+
+			node2 := t.CreateNode(testing.NewNode("node2"))
+			t.awaitEvent(testing.EvNodeCreated, node2)
+
+			for hostname, expected := range map[string]*corev1.Node{"node1": node1, "node1.example.com": node1, "node2": node2} {
+				node, found := t.handler.State().GetNodeByHostname(hostname)
+				Expect(found).To(BeTrue(), "Node not found for hostname %q", hostname)
+				Expect(node).To(Equal(expected))
+			}
+
+			_, found = t.handler.State().GetNodeByHostname("192.168.1.1")
+			Expect(found).To(BeFalse())
+
+			By("Updating the hostname address of the Node")
+
+			node1.Status.Addresses[1].Address = "node1.other.com"
+			t.UpdateNode(node1)
+			t.awaitEvent(testing.EvNodeUpdated, node1)
+
+			_, found = t.handler.State().GetNodeByHostname("node1.example.com")
+			Expect(found).To(BeFalse())
+
+			node, found := t.handler.State().GetNodeByHostname("node1.other.com")
+			Expect(found).To(BeTrue())
+			Expect(node.Status.Addresses).To(Equal(node1.Status.Addresses))
+
+			By("Deleting the Node")
+
+			t.DeleteNode(node1.Name)
+			t.awaitEvent(testing.EvNodeRemoved, node1)
+
+			_, found = t.handler.State().GetNodeByHostname("node1")
+			Expect(found).To(BeFalse())
+
+			_, found = t.handler.State().GetNodeByHostname("node1.other.com")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	When("the remote subnets are retrieved via the handler state", func() {
+		It("should return the deduplicated union of the subnets of the remote Endpoints", func() {
+			Expect(t.handler.State().GetRemoteSubnets()).To(BeEmpty())
+
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1", "10.2.0.0/16", "10.1.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host2", "10.3.0.0/16", "10.1.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+
+			Expect(t.handler.State().GetRemoteSubnets()).To(Equal([]string{"10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"}))
+
+			t.DeleteEndpoint(endpoint2.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint2)
+
+			Expect(t.handler.State().GetRemoteSubnets()).To(Equal([]string{"10.1.0.0/16", "10.2.0.0/16"}))
+		})
+	})
+
+	When("Nodes with managedFields are cached", func() {
+		var preExisting *corev1.Node
+
+		newNode := func(name string) *corev1.Node {
+			node := testing.NewNode(name)
+			node.Labels = map[string]string{"topology.kubernetes.io/zone": "zone1"}
+			node.Annotations = map[string]string{"heavy": "data"}
+			node.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubelet", Operation: metav1.ManagedFieldsOperationUpdate}}
+
+			return node
+		}
+
+		awaitNode := func(name string) *corev1.Node {
+			var e testing.TestEvent
+
+			Eventually(t.testEvents).Should(Receive(&e))
+			Expect(e.Name).To(Equal(testing.EvNodeCreated))
+
+			node := e.Parameter.(*corev1.Node)
+			Expect(node.Name).To(Equal(name))
+
+			return node
+		}
+
+		JustBeforeEach(func() {
+			Expect(awaitNode(preExisting.Name).ManagedFields).To(BeEmpty())
+		})
+
+		Context("by default", func() {
+			BeforeEach(func() {
+				t.Configure = func(_ *controller.Config) {
+					preExisting = t.CreateNode(newNode("node1"))
+				}
+			})
+
+			It("should strip the managedFields while retaining the other fields", func() {
+				t.CreateNode(newNode("node2"))
+
+				node := awaitNode("node2")
+				Expect(node.ManagedFields).To(BeEmpty())
+				Expect(node.Labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "zone1"))
+				Expect(node.Annotations).To(HaveKeyWithValue("heavy", "data"))
+			})
+		})
+
+		Context("and a custom transform is configured", func() {
+			BeforeEach(func() {
+				t.Configure = func(config *controller.Config) {
+					config.CacheTransform = func(obj *unstructured.Unstructured) {
+						controller.StripManagedFields(obj)
+						obj.SetAnnotations(nil)
+					}
+
+					preExisting = t.CreateNode(newNode("node1"))
+				}
+			})
+
+			It("should apply it", func() {
+				t.CreateNode(newNode("node2"))
+
+				node := awaitNode("node2")
+				Expect(node.ManagedFields).To(BeEmpty())
+				Expect(node.Annotations).To(BeEmpty())
+				Expect(node.Labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "zone1"))
+			})
+		})
+		Context("and per resource type transforms are configured", func() {
+			BeforeEach(func() {
+				t.Configure = func(config *controller.Config) {
+					config.CacheTransform = func(obj *unstructured.Unstructured) {
+						obj.SetLabels(nil)
+					}
+
+					config.CacheTransforms = map[string]func(obj *unstructured.Unstructured){
+						controller.NodeResource: func(obj *unstructured.Unstructured) {
+							controller.StripManagedFields(obj)
+							obj.SetAnnotations(nil)
+						},
+					}
+
+					preExisting = t.CreateNode(newNode("node1"))
+				}
+			})
+
+			It("should apply each independently", func() {
+				t.CreateNode(newNode("node2"))
+
+				node := awaitNode("node2")
+				Expect(node.ManagedFields).To(BeEmpty())
+				Expect(node.Annotations).To(BeEmpty())
+				Expect(node.Labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "zone1"))
+
+				endpoint := testing.NewEndpoint("remote-cluster", "host", "10.1.0.0/16")
+				endpoint.Labels = map[string]string{"label": "value"}
+				endpoint.Annotations = map[string]string{"annotation": "value"}
+				t.CreateEndpoint(endpoint)
+
+				var e testing.TestEvent
+
+				Eventually(t.testEvents).Should(Receive(&e))
+				Expect(e.Name).To(Equal(testing.EvRemoteEndpointCreated))
+
+				cached := e.Parameter.(*submV1.Endpoint)
+				Expect(cached.Labels).To(BeEmpty())
+				Expect(cached.Annotations).To(HaveKeyWithValue("annotation", "value"))
+			})
+		})
+	})
+
+	When("a watch request fails", func() {
+		type watchError struct {
+			resource string
+			err      error
+		}
+
+		var watchErrors chan watchError
+
+		BeforeEach(func() {
+			watchErrors = make(chan watchError, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.OnWatchError = func(resource string, err error) {
+					watchErrors <- watchError{resource: resource, err: err}
+				}
+
+				failed := atomic.Bool{}
+
+				config.Client.(*dynamicfake.FakeDynamicClient).PrependWatchReactor("nodes",
+					func(_ k8stesting.Action) (bool, watch.Interface, error) {
+						if failed.Swap(true) {
+							return false, nil, nil
+						}
+
+						return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("RBAC denied"))
+					})
+			}
+		})
+
+		It("should invoke the OnWatchError callback with the resource and error", func() {
+			var e watchError
+
+			Eventually(watchErrors).Should(Receive(&e))
+			Expect(e.resource).To(Equal(controller.NodeResource))
+			Expect(apierrors.IsForbidden(e.err)).To(BeTrue())
+
+			By("Ensuring the informer recovers once the watch request succeeds")
+
+			node := t.CreateNode(testing.NewNode("node1"))
+			Eventually(t.testEvents, 5*time.Second).Should(Receive(Equal(
+				testing.TestEvent{Handler: testHandlerName, Name: testing.EvNodeCreated, Parameter: node})))
+		})
+
+		It("should explain the RBAC rule needed if the request is forbidden", func() {
+			var e watchError
+
+			Eventually(watchErrors).Should(Receive(&e))
+			Expect(e.err.Error()).To(ContainSubstring(`not permitted to watch "nodes" cluster-wide - the RBAC rules of the ` +
+				`service account must allow the "list" and "watch" verbs for apiGroups: [""], resources: ["nodes"]`))
+			Expect(e.err.Error()).To(ContainSubstring("RBAC denied"))
+		})
+	})
+
+	When("event capture is enabled", func() {
+		It("should hold the most recent dispatched events", func() {
+			Expect(t.Controller.DumpCapture()).To(BeNil())
+
+			t.Controller.EnableCapture(3)
+			Expect(t.Controller.DumpCapture()).To(BeEmpty())
+
+			var nodes []*corev1.Node
+
+			for i := 1; i <= 4; i++ {
+				node := t.CreateNode(testing.NewNode(fmt.Sprintf("node%d", i)))
+				t.awaitEvent(testing.EvNodeCreated, node)
+
+				nodes = append(nodes, node)
+			}
+
+			captured := t.Controller.DumpCapture()
+			Expect(captured).To(HaveLen(3))
+
+			for i, n := range captured {
+				Expect(n.Type).To(Equal(event.NodeCreated))
+				Expect(n.Objects).To(Equal([]runtime.Object{nodes[i+1]}))
+			}
+
+			By("Disabling the capture")
+
+			t.Controller.EnableCapture(0)
+			Expect(t.Controller.DumpCapture()).To(BeNil())
+		})
+	})
+
+	When("a Node update coalescing window is configured", func() {
+		var fakeClock *testingclock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+
+			t.Configure = func(config *controller.Config) {
+				config.NodeUpdateCoalescingWindow = 10 * time.Second
+				config.Clock = fakeClock
+			}
+		})
+
+		It("should dispatch the latest Node once per window", func() {
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			for i := 1; i <= 3; i++ {
+				processed := t.Controller.Metrics().EventsProcessed
+
+				node.Labels = map[string]string{"update": strconv.Itoa(i)}
+				t.UpdateNode(node)
+
+				Eventually(func() uint64 {
+					return t.Controller.Metrics().EventsProcessed
+				}).Should(Equal(processed + 1))
+			}
+
+			t.ensureNoEvents()
+
+			fakeClock.Step(10 * time.Second)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+			t.ensureNoEvents()
+
+			By("Updating the Node in the next window")
+
+			node.Labels = map[string]string{"update": "4"}
+			t.UpdateNode(node)
+
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+			t.ensureNoEvents()
+
+			fakeClock.Step(10 * time.Second)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+		})
+	})
+
+	When("a resource priority is configured", func() {
+		var (
+			nodes     []*corev1.Node
+			endpoints []*submV1.Endpoint
+		)
+
+		BeforeEach(func() {
+			nodes = nil
+			endpoints = nil
+
+			t.Configure = func(config *controller.Config) {
+				config.ResourcePriority = []string{controller.NodeResource, controller.EndpointResource}
+
+				for i := 1; i <= 3; i++ {
+					endpoints = append(endpoints, t.CreateEndpoint(testing.NewEndpoint(fmt.Sprintf("remote-cluster%d", i), "host")))
+					nodes = append(nodes, t.CreateNode(testing.NewNode(fmt.Sprintf("node%d", i))))
+				}
+			}
+		})
+
+		It("should dispatch the initial events of the prioritized resource types first", func() {
+			var received []string
+
+			for i := 0; i < len(nodes)+len(endpoints); i++ {
+				var e testing.TestEvent
+
+				Eventually(t.testEvents).Should(Receive(&e))
+				received = append(received, e.Name)
+			}
+
+			Expect(received).To(Equal([]string{
+				testing.EvNodeCreated, testing.EvNodeCreated, testing.EvNodeCreated,
+				testing.EvRemoteEndpointCreated, testing.EvRemoteEndpointCreated, testing.EvRemoteEndpointCreated,
+			}))
+
+			By("Dispatching subsequent events as received")
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster4", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+	})
+
+	When("a remote Endpoint batch key is configured", func() {
+		var (
+			fakeClock *testingclock.FakeClock
+			batches   chan endpointBatch
+		)
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+			batches = make(chan endpointBatch, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.Clock = fakeClock
+				config.RemoteEndpointBatchKey = controller.ClusterIDBatchKey
+				config.RemoteEndpointBatchWindow = 10 * time.Second
+
+				_, err := config.Registry.AddHandler(&batchHandler{batches: batches})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should dispatch the events with the same key to the batch handler in a single call", func() {
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			created1 := endpoint1.DeepCopy()
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+
+			endpoint1.Labels = map[string]string{"updated": "true"}
+			t.UpdateEndpoint(endpoint1)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint1)
+
+			endpoint3 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host3"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint3)
+
+			Consistently(batches).ShouldNot(Receive())
+
+			fakeClock.Step(10 * time.Second)
+
+			received := map[string][]event.EndpointEvent{}
+
+			for i := 0; i < 2; i++ {
+				var b endpointBatch
+
+				Eventually(batches).Should(Receive(&b))
+				received[b.key] = b.events
+			}
+
+			Expect(received).To(Equal(map[string][]event.EndpointEvent{
+				"remote-cluster1": {
+					{Type: event.RemoteEndpointCreated, Endpoint: created1},
+					{Type: event.RemoteEndpointCreated, Endpoint: endpoint2},
+					{Type: event.RemoteEndpointUpdated, Endpoint: endpoint1},
+				},
+				"remote-cluster2": {
+					{Type: event.RemoteEndpointCreated, Endpoint: endpoint3},
+				},
+			}))
+
+			Consistently(batches).ShouldNot(Receive())
+
+			By("Deleting an Endpoint in the next window")
+
+			t.DeleteEndpoint(endpoint2.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint2)
+
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+			fakeClock.Step(10 * time.Second)
+
+			Eventually(batches).Should(Receive(Equal(endpointBatch{
+				key:    "remote-cluster1",
+				events: []event.EndpointEvent{{Type: event.RemoteEndpointRemoved, Endpoint: endpoint2}},
+			})))
+		})
+	})
+
+	When("an address resolver is configured", func() {
+		var (
+			fakeClock   *testingclock.FakeClock
+			resolutions atomic.Int32
+			resolveErr  atomic.Value
+		)
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+			resolutions.Store(0)
+			resolveErr.Store("")
+
+			t.Configure = func(config *controller.Config) {
+				config.Clock = fakeClock
+				config.ResolvedAddressTTL = time.Minute
+				config.AddressResolver = func(endpoint *submV1.Endpoint) (string, error) {
+					if msg := resolveErr.Load().(string); msg != "" {
+						return "", errors.New(msg)
+					}
+
+					n := resolutions.Add(1)
+
+					return fmt.Sprintf("%s-%d", endpoint.Spec.Hostname, n), nil
+				}
+			}
+		})
+
+		resolvedAddress := func(clusterID string) string {
+			info, found := t.handler.State().GetEndpointInfo(clusterID)
+			Expect(found).To(BeTrue())
+
+			return info.ResolvedAddress
+		}
+
+		It("should resolve and cache the address until the TTL elapses or the Endpoint is updated", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("host-1"))
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("host-1"))
+			Expect(resolutions.Load()).To(Equal(int32(1)))
+
+			fakeClock.Step(time.Minute)
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("host-2"))
+
+			endpoint.Spec.Hostname = "other-host"
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("other-host-3"))
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("other-host-3"))
+		})
+
+		It("should not cache failed resolutions", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			resolveErr.Store("mock resolution error")
+			Expect(resolvedAddress("remote-cluster1")).To(BeEmpty())
+
+			resolveErr.Store("")
+			Expect(resolvedAddress("remote-cluster1")).To(Equal("host-1"))
+		})
+	})
+
+	When("a heartbeat interval is configured", func() {
+		var fakeClock *testingclock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+
+			t.Configure = func(config *controller.Config) {
+				config.HeartbeatInterval = 10 * time.Second
+				config.Clock = fakeClock
+			}
+		})
+
+		It("should notify the handlers at the interval", func() {
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			fakeClock.Step(5 * time.Second)
+			t.ensureNoEvents()
+
+			fakeClock.Step(5 * time.Second)
+			t.awaitEvent(testing.EvHeartbeat, nil)
+			t.ensureNoEvents()
+
+			fakeClock.Step(10 * time.Second)
+			t.awaitEvent(testing.EvHeartbeat, nil)
+		})
+	})
+
+	When("a known clusters source is configured", func() {
+		var (
+			fakeClock *testingclock.FakeClock
+			known     *knownClustersStub
+			dropped   chan controller.DropReason
+		)
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+			known = &knownClustersStub{}
+			known.set("remote-cluster1", "remote-cluster2")
+			dropped = make(chan controller.DropReason, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.Clock = fakeClock
+				config.KnownClusters = known
+				config.OrphanedEndpointsInterval = time.Minute
+				config.OnEventDropped = func(_ event.Type, _ runtime.Object, reason controller.DropReason) {
+					dropped <- reason
+				}
+			}
+		})
+
+		It("should synthesize the removal of the remote endpoints of clusters no longer known", func() {
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			fakeClock.Step(time.Minute)
+			t.ensureNoEvents()
+
+			By("Removing a cluster from the known clusters")
+
+			known.set("remote-cluster1")
+			fakeClock.Step(time.Minute)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint2)
+			t.ensureNoEvents()
+			Expect(t.handler.State().HasRemoteCluster("remote-cluster2")).To(BeFalse())
+			Expect(t.handler.State().HasRemoteCluster("remote-cluster1")).To(BeTrue())
+
+			By("Deleting the orphaned endpoint")
+
+			t.DeleteEndpoint(endpoint2.Name)
+			Eventually(dropped).Should(Receive(Equal(controller.DropReasonOrphanRemoved)))
+			t.ensureNoEvents()
+		})
+
+		It("should not remove endpoints if the known clusters can't be retrieved", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			known.setErr(errors.New("mock error"))
+			fakeClock.Step(time.Minute)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("a stalled event threshold is configured and a handler is blocked", func() {
+		var (
+			fakeClock *testingclock.FakeClock
+			stalled   chan controller.StalledEvent
+			release   chan struct{}
+		)
+
+		BeforeEach(func() {
+			fakeClock = testingclock.NewFakeClock(time.Now())
+			stalled = make(chan controller.StalledEvent, 10)
+			release = make(chan struct{})
+
+			t.Configure = func(config *controller.Config) {
+				config.Clock = fakeClock
+				config.StalledEventThreshold = 10 * time.Second
+				config.OnEventStalled = func(event controller.StalledEvent) {
+					stalled <- event
+				}
+
+				_, err := config.Registry.AddHandler(&blockingNodeHandler{blockOn: "blocker", release: release})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("should report the stalled event once with the blocked handler", func() {
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			blocker := t.CreateNode(testing.NewNode("blocker"))
+			t.awaitEvent(testing.EvNodeCreated, blocker)
+
+			fakeClock.Step(5 * time.Second)
+			Consistently(stalled).ShouldNot(Receive())
+
+			fakeClock.Step(5 * time.Second)
+			Eventually(stalled).Should(Receive(Equal(controller.StalledEvent{
+				Key:      controller.NodeResource + "/blocker",
+				Handlers: []string{"test-registry/blocking-node-handler"},
+				Duration: 10 * time.Second,
+			})))
+
+			fakeClock.Step(10 * time.Second)
+			Consistently(stalled).ShouldNot(Receive())
+
+			Expect(t.Controller.Metrics().StalledEvents).To(Equal(uint64(1)))
+		})
+	})
+
+	When("remote Endpoint deletes are confirmed", func() {
+		var stillExists atomic.Bool
+
+		BeforeEach(func() {
+			stillExists.Store(false)
+
+			t.Configure = func(config *controller.Config) {
+				config.ConfirmRemoteEndpointDeletes = true
+
+				config.Client.(*dynamicfake.FakeDynamicClient).PrependReactor("get", "endpoints",
+					func(action k8stesting.Action) (bool, runtime.Object, error) {
+						if !stillExists.Load() {
+							return false, nil, nil
+						}
+
+						u := &unstructured.Unstructured{}
+						u.SetName(action.(k8stesting.GetAction).GetName())
+
+						return true, u, nil
+					})
+			}
+		})
+
+		It("should notify the handler of a confirmed removal", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint)
+		})
+
+		It("should suppress the removal if the Endpoint still exists", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			stillExists.Store(true)
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("events are dropped", func() {
+		const deniedClusterID = "denied-cluster"
+
+		type droppedEvent struct {
+			eventType event.Type
+			name      string
+			reason    controller.DropReason
+		}
+
+		var (
+			dropped     chan droppedEvent
+			stillExists atomic.Bool
+		)
+
+		BeforeEach(func() {
+			dropped = make(chan droppedEvent, 10)
+			stillExists.Store(false)
+
+			t.Configure = func(config *controller.Config) {
+				config.OnEventDropped = func(eventType event.Type, obj runtime.Object, reason controller.DropReason) {
+					dropped <- droppedEvent{eventType: eventType, name: obj.(metav1.Object).GetName(), reason: reason}
+				}
+
+				config.MaxObjectBytes = 1000
+				config.ConfirmRemoteEndpointDeletes = true
+				config.EventFilter = func(_ event.Type, obj runtime.Object) bool {
+					endpoint, ok := obj.(*submV1.Endpoint)
+					return !ok || endpoint.Spec.ClusterID != deniedClusterID
+				}
+
+				config.Client.(*dynamicfake.FakeDynamicClient).PrependReactor("get", "endpoints",
+					func(action k8stesting.Action) (bool, runtime.Object, error) {
+						if !stillExists.Load() {
+							return false, nil, nil
+						}
+
+						u := &unstructured.Unstructured{}
+						u.SetName(action.(k8stesting.GetAction).GetName())
+
+						return true, u, nil
+					})
+			}
+		})
+
+		It("should report the Filtered reason for events rejected by the event filter", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint(deniedClusterID, "host"))
+			Eventually(dropped).Should(Receive(Equal(droppedEvent{
+				eventType: event.RemoteEndpointCreated, name: endpoint.Name, reason: controller.DropReasonFiltered,
+			})))
+		})
+
+		It("should report the Oversized reason for oversized objects", func() {
+			endpoint := testing.NewEndpoint("remote-cluster1", "host")
+			endpoint.Annotations = map[string]string{"blob": strings.Repeat("x", 2000)}
+			t.CreateEndpoint(endpoint)
+
+			Eventually(dropped).Should(Receive(Equal(droppedEvent{
+				eventType: event.RemoteEndpointCreated, name: endpoint.Name, reason: controller.DropReasonOversized,
+			})))
+		})
+
+		It("should report the StillExists reason for unconfirmed remote Endpoint deletes", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			stillExists.Store(true)
+			t.DeleteEndpoint(endpoint.Name)
+
+			Eventually(dropped).Should(Receive(Equal(droppedEvent{
+				eventType: event.RemoteEndpointRemoved, name: endpoint.Name, reason: controller.DropReasonStillExists,
+			})))
+		})
+
+		It("should expose the filtered Endpoints with their reasons", func() {
+			denied := t.CreateEndpoint(testing.NewEndpoint(deniedClusterID, "host"))
+
+			oversized := testing.NewEndpoint("remote-cluster1", "host")
+			oversized.Annotations = map[string]string{"blob": strings.Repeat("x", 2000)}
+			t.CreateEndpoint(oversized)
+
+			invalid := t.CreateEndpoint(testing.NewEndpoint("", "host"))
+
+			type filtered struct {
+				name   string
+				reason controller.DropReason
+			}
+
+			filteredEndpoints := func() []filtered {
+				var result []filtered
+				for _, f := range t.Controller.FilteredEndpoints() {
+					Expect(f.EventType).To(Equal(event.RemoteEndpointCreated))
+					Expect(f.Message).ToNot(BeEmpty())
+
+					result = append(result, filtered{name: f.Endpoint.Name, reason: f.Reason})
+				}
+
+				return result
+			}
+
+			Eventually(filteredEndpoints).Should(ConsistOf(
+				filtered{name: denied.Name, reason: controller.DropReasonFiltered},
+				filtered{name: oversized.Name, reason: controller.DropReasonOversized},
+				filtered{name: invalid.Name, reason: controller.DropReasonInvalid}))
+
+			By("Deleting a filtered Endpoint")
+
+			t.DeleteEndpoint(denied.Name)
+			Eventually(filteredEndpoints).Should(HaveLen(2))
+
+			By("Updating a filtered Endpoint so it's dispatched")
+
+			invalid.Spec.ClusterID = "remote-cluster2"
+			t.UpdateEndpoint(invalid)
+			Eventually(filteredEndpoints).Should(ConsistOf(filtered{name: oversized.Name, reason: controller.DropReasonOversized}))
+		})
+
+		It("should report the MaxRequeues reason for events requeued too many times", func() {
+			endpoint := testing.NewEndpoint("remote-cluster1", "host")
+
+			var auditLog bytes.Buffer
+			Expect(controller.JSONCodec{}.Encode(&auditLog, &controller.EventRecord{
+				Resource:    controller.EndpointResource,
+				Operation:   controller.UpdateOperation,
+				NumRequeues: 21,
+				Object:      endpoint,
+			})).To(Succeed())
+
+			Expect(t.Controller.Replay(controller.NewReplaySource(&auditLog))).To(Succeed())
+			Expect(dropped).To(Receive(Equal(droppedEvent{
+				eventType: event.RemoteEndpointUpdated, name: endpoint.Name, reason: controller.DropReasonMaxRequeues,
+			})))
+			t.ensureNoEvents()
+		})
+	})
+
+	When("invalid Endpoints are notified", func() {
+		var dropped chan controller.DropReason
+
+		BeforeEach(func() {
+			dropped = make(chan controller.DropReason, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.OnEventDropped = func(_ event.Type, _ runtime.Object, reason controller.DropReason) {
+					dropped <- reason
+				}
+			}
+		})
+
+		assertRejected := func(endpoint *submV1.Endpoint) {
+			t.CreateEndpoint(endpoint)
+			Eventually(dropped).Should(Receive(Equal(controller.DropReasonInvalid)))
+			t.ensureNoEvents()
+
+			Expect(t.handler.State().GetRemoteEndpoints()).To(BeEmpty())
+			Expect(t.Controller.Metrics().InvalidEndpoints).To(Equal(uint64(1)))
+		}
+
+		Context("by default", func() {
+			It("should reject an Endpoint without a cluster ID", func() {
+				assertRejected(testing.NewEndpoint("", "host"))
+			})
+
+			It("should reject an Endpoint with a malformed subnet", func() {
+				assertRejected(testing.NewEndpoint("remote-cluster1", "host", "10.0.0.0/16", "bogus"))
+			})
+		})
+
+		Context("and a custom validator is configured", func() {
+			BeforeEach(func() {
+				configure := t.Configure
+				t.Configure = func(config *controller.Config) {
+					configure(config)
+
+					config.EndpointValidator = func(endpoint *submV1.Endpoint) error {
+						if len(endpoint.Spec.Subnets) == 0 {
+							return errors.New("no subnets")
+						}
+
+						return controller.DefaultEndpointValidator(endpoint)
+					}
+				}
+			})
+
+			It("should reject the Endpoints as per the validator", func() {
+				assertRejected(testing.NewEndpoint("remote-cluster1", "host"))
+
+				endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host", "10.0.0.0/16"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			})
+		})
+	})
+
+	When("a custom key function is configured", func() {
+		const groupLabel = "group"
+
+		var (
+			release  chan struct{}
+			keyCalls atomic.Int32
+		)
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			keyCalls.Store(0)
+
+			t.Configure = func(config *controller.Config) {
+				config.KeyFunc = func(obj runtime.Object) string {
+					keyCalls.Add(1)
+					return obj.(metav1.Object).GetLabels()[groupLabel]
+				}
+
+				_, err := config.Registry.AddHandler(&blockingNodeHandler{blockOn: "blocker", release: release})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		newGroupedNode := func(name, group string) *corev1.Node {
+			node := testing.NewNode(name)
+			node.Labels = map[string]string{groupLabel: group}
+
+			return node
+		}
+
+		It("should order and coalesce the events by the custom key", func() {
+			blocker := t.CreateNode(newGroupedNode("blocker", "blocking"))
+			t.awaitEvent(testing.EvNodeCreated, blocker)
+
+			t.CreateNode(newGroupedNode("node1", "a"))
+			node2 := t.CreateNode(newGroupedNode("node2", "a"))
+			node3 := t.CreateNode(newGroupedNode("node3", "b"))
+			Eventually(keyCalls.Load).Should(Equal(int32(4)))
+
+			close(release)
+
+			t.awaitEvent(testing.EvNodeCreated, node2)
+			t.awaitEvent(testing.EvNodeCreated, node3)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("events for the same object are queued while the dispatch is blocked", func() {
+		var (
+			release  chan struct{}
+			keyCalls atomic.Int32
+		)
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			keyCalls.Store(0)
+
+			t.Configure = func(config *controller.Config) {
+				config.KeyFunc = func(obj runtime.Object) string {
+					keyCalls.Add(1)
+					return fmt.Sprintf("%T/%s", obj, obj.(metav1.Object).GetName())
+				}
+
+				_, err := config.Registry.AddHandler(&blockingNodeHandler{blockOn: "blocker", release: release})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		// awaitQueued waits for the given total number of events to be queued and checks the number of pending events.
+		awaitQueued := func(total, pending int) {
+			Eventually(keyCalls.Load).Should(Equal(int32(total)))
+			Expect(t.Controller.QueuedEvents()).To(Equal(pending))
+		}
+
+		It("should merge a pending create with a subsequent update or delete", func() {
+			blocker := t.CreateNode(testing.NewNode("blocker"))
+			t.awaitEvent(testing.EvNodeCreated, blocker)
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			awaitQueued(2, 1)
+
+			endpoint.Labels = map[string]string{"updated": "true"}
+			t.UpdateEndpoint(endpoint)
+			awaitQueued(3, 1)
+
+			deleted := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host"))
+			awaitQueued(4, 2)
+
+			t.DeleteEndpoint(deleted.Name)
+			awaitQueued(5, 1)
+
+			close(release)
+
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			t.ensureNoEvents()
+		})
+
+		It("should dispatch the create of the updated local Endpoint and transition to gateway", func() {
+			blocker := t.CreateNode(testing.NewNode("blocker"))
+			t.awaitEvent(testing.EvNodeCreated, blocker)
+
+			endpoint := t.CreateLocalHostEndpoint()
+			awaitQueued(2, 1)
+
+			endpoint.Labels = map[string]string{"updated": "true"}
+			t.UpdateEndpoint(endpoint)
+			awaitQueued(3, 1)
+
+			close(release)
+
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+			t.ensureNoEvents()
+
+			Expect(t.handler.State().IsOnGateway()).To(BeTrue())
+		})
+	})
+
+	When("synchronous dispatch is configured", func() {
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.SynchronousDispatch = true
+				config.KeyFunc = func(obj runtime.Object) string {
+					return obj.(metav1.Object).GetName()
+				}
+				config.NodeUpdateCoalescingWindow = time.Hour
+			}
+		})
+
+		It("should dispatch the events inline in delivery order", func() {
+			var nodes []*corev1.Node
+
+			for i := 0; i < 5; i++ {
+				nodes = append(nodes, t.CreateNode(testing.NewNode(fmt.Sprintf("node%d", i))))
+			}
+
+			for _, node := range nodes {
+				t.awaitEvent(testing.EvNodeCreated, node)
+			}
+
+			Expect(t.Controller.QueuedEvents()).To(BeZero())
+
+			nodes[0].Labels = map[string]string{"updated": "true"}
+			t.UpdateNode(nodes[0])
+			t.awaitEvent(testing.EvNodeUpdated, nodes[0])
+		})
+	})
+
+	When("only ignored annotations of an Endpoint change", func() {
+		const heartbeat = "submariner.io/last-heartbeat"
+
+		var endpoint *submV1.Endpoint
+
+		JustBeforeEach(func() {
+			endpoint = testing.NewEndpoint("remote-cluster1", "host")
+			endpoint.Annotations = map[string]string{heartbeat: "1"}
+			t.CreateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+
+		Context("by default", func() {
+			It("should not dispatch heartbeat-only updates", func() {
+				endpoint.Annotations[heartbeat] = "2"
+				t.UpdateEndpoint(endpoint)
+				t.ensureNoEvents()
+
+				endpoint.Annotations["other"] = "value"
+				t.UpdateEndpoint(endpoint)
+				t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			})
+		})
+
+		Context("and the ignored annotations are configured", func() {
+			BeforeEach(func() {
+				t.Configure = func(config *controller.Config) {
+					config.IgnoredEndpointAnnotations = []string{"custom-timestamp"}
+				}
+			})
+
+			It("should only suppress updates of the configured annotations", func() {
+				endpoint.Annotations["custom-timestamp"] = "1"
+				t.UpdateEndpoint(endpoint)
+				t.ensureNoEvents()
+
+				endpoint.Annotations[heartbeat] = "2"
+				t.UpdateEndpoint(endpoint)
+				t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			})
+		})
+	})
+
+	When("an event is being retried", func() {
+		var failing *failingNodeHandler
+
+		BeforeEach(func() {
+			failing = &failingNodeHandler{}
+			failing.fail.Store(true)
+
+			t.Configure = func(config *controller.Config) {
+				_, err := config.Registry.AddHandler(failing)
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should report the key and attempt count in the pending retries", func() {
+			Expect(t.Controller.PendingRetries()).To(BeEmpty())
+
+			t.CreateNode(testing.NewNode("node1"))
+
+			Eventually(t.Controller.PendingRetries).Should(HaveKeyWithValue("Node/node1", BeNumerically(">=", 2)))
+
+			failing.fail.Store(false)
+
+			Eventually(t.Controller.PendingRetries).Should(BeEmpty())
+		})
+	})
+
+	When("an event is being retried and error log deduplication is configured", func() {
+		const interval = time.Minute
+
+		var (
+			failing   *failingNodeHandler
+			fakeClock *testingclock.FakeClock
+			mutex     sync.Mutex
+			lines     []string
+		)
+
+		BeforeEach(func() {
+			failing = &failingNodeHandler{}
+			failing.fail.Store(true)
+			fakeClock = testingclock.NewFakeClock(time.Now())
+			lines = nil
+
+			t.Configure = func(config *controller.Config) {
+				_, err := config.Registry.AddHandler(failing)
+				Expect(err).To(Succeed())
+
+				config.Clock = fakeClock
+				config.ErrorLogDedupInterval = interval
+				config.Logger = log.Logger{Logger: funcr.New(func(prefix, args string) {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					lines = append(lines, prefix+" "+args)
+				}, funcr.Options{})}
+			}
+		})
+
+		countLines := func(substrs ...string) int {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			count := 0
+
+		lines:
+			for _, line := range lines {
+				for _, substr := range substrs {
+					if !strings.Contains(line, substr) {
+						continue lines
+					}
+				}
+
+				count++
+			}
+
+			return count
+		}
+
+		It("should collapse the repeated identical errors with a count", func() {
+			t.CreateNode(testing.NewNode("node1"))
+			Eventually(t.Controller.PendingRetries).Should(HaveKeyWithValue("Node/node1", BeNumerically(">=", 3)))
+			Expect(countLines("Error handling created Node", "Node/node1")).To(Equal(1))
+
+			By("Failing another event with the same error")
+
+			t.CreateNode(testing.NewNode("node2"))
+			Eventually(t.Controller.PendingRetries).Should(HaveKeyWithValue("Node/node2", BeNumerically(">=", 3)))
+			Expect(countLines("Error handling created Node", "Node/node2")).To(Equal(1))
+
+			failing.fail.Store(false)
+			Eventually(t.Controller.PendingRetries).Should(BeEmpty())
+
+			By("Elapsing the interval")
+
+			fakeClock.Step(interval)
+
+			Eventually(func() int {
+				return countLines("Error handling created Node (repeated", "Node/node1")
+			}).Should(Equal(1))
+
+			Eventually(func() int {
+				return countLines("Error handling created Node (repeated", "Node/node2")
+			}).Should(Equal(1))
+		})
+	})
+
+	When("events are queued behind a slow handler", func() {
+		const delay = 300 * time.Millisecond
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.MaxQueuedEvents = 10
+
+				_, err := config.Registry.AddHandler(&slowNodeHandler{slowOn: "node1", delay: delay})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should observe the queue wait and processing durations separately", func() {
+			initialWait, initialWaitCount := nodeEventHistogram("submariner_event_queue_wait_duration_seconds")
+			initialProcessing, initialProcessingCount := nodeEventHistogram("submariner_event_processing_duration_seconds")
+
+			var nodes []*corev1.Node
+
+			for _, name := range []string{"node1", "node2", "node3"} {
+				nodes = append(nodes, t.CreateNode(testing.NewNode(name)))
+			}
+
+			for _, node := range nodes {
+				t.awaitEvent(testing.EvNodeCreated, node)
+			}
+
+			Eventually(func() uint64 {
+				_, count := nodeEventHistogram("submariner_event_processing_duration_seconds")
+				return count - initialProcessingCount
+			}).Should(BeNumerically(">=", 3))
+
+			wait, waitCount := nodeEventHistogram("submariner_event_queue_wait_duration_seconds")
+			processing, _ := nodeEventHistogram("submariner_event_processing_duration_seconds")
+
+			Expect(waitCount - initialWaitCount).To(BeNumerically(">=", 3))
+
+			// Only node1 is slow to process whereas node2 and node3 each wait for it.
+			Expect(processing - initialProcessing).To(BeNumerically(">=", delay.Seconds()))
+			Expect(wait - initialWait).To(BeNumerically(">=", 1.5*delay.Seconds()))
+			Expect(wait - initialWait).To(BeNumerically(">", processing-initialProcessing))
+		})
+	})
+
+	When("a metrics snapshot is retrieved", func() {
+		var failing *failingNodeHandler
+
+		BeforeEach(func() {
+			failing = &failingNodeHandler{}
+
+			t.Configure = func(config *controller.Config) {
+				_, err := config.Registry.AddHandler(failing)
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should reflect the dispatched events, errors, retries and transitions", func() {
+			Expect(t.Controller.Metrics()).To(Equal(controller.MetricsSnapshot{}))
+
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			Eventually(t.Controller.Metrics).Should(Equal(controller.MetricsSnapshot{
+				EventsProcessed:    1,
+				GatewayTransitions: 1,
+			}))
+
+			failing.fail.Store(true)
+
+			t.CreateNode(testing.NewNode("node1"))
+
+			Eventually(func() uint64 {
+				return t.Controller.Metrics().Retries
+			}).Should(BeNumerically(">=", 1))
+
+			failing.fail.Store(false)
+
+			Eventually(t.Controller.PendingRetries).Should(BeEmpty())
+
+			metrics := t.Controller.Metrics()
+			Expect(metrics.EventsProcessed).To(Equal(uint64(2)))
+			Expect(metrics.EventErrors).To(Equal(metrics.Retries))
+			Expect(metrics.EventErrors).To(BeNumerically(">=", 2))
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvLocalEndpointRemoved, endpoint)
+			t.awaitEvent(testing.EvTransitionToNonGateway, nil)
+
+			Eventually(t.Controller.Metrics).Should(Equal(controller.MetricsSnapshot{
+				EventsProcessed:       3,
+				EventErrors:           metrics.EventErrors,
+				Retries:               metrics.Retries,
+				GatewayTransitions:    1,
+				NonGatewayTransitions: 1,
+			}))
+		})
+	})
+
+	When("handlers take varying times to complete the transition to gateway", func() {
+		const (
+			delay1 = 100 * time.Millisecond
+			delay2 = 300 * time.Millisecond
+		)
+
+		var latencies chan time.Duration
+
+		BeforeEach(func() {
+			latencies = make(chan time.Duration, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.OnGatewayTransitionLatency = func(latency time.Duration) {
+					latencies <- latency
+				}
+
+				for i, delay := range []time.Duration{delay1, delay2} {
+					_, err := config.Registry.AddHandler(&slowGatewayHandler{name: fmt.Sprintf("slow-gateway-handler%d", i), delay: delay})
+					Expect(err).To(Succeed())
+				}
+			}
+		})
+
+		It("should measure the latency until the last handler completed", func() {
+			initialSum, initialCount := gatewayTransitionHistogram()
+
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			var latency time.Duration
+			Eventually(latencies).Should(Receive(&latency))
+			Expect(latency).To(BeNumerically(">=", delay1+delay2))
+			Expect(latency).To(BeNumerically("<", delay1+delay2+5*time.Second))
+
+			sum, count := gatewayTransitionHistogram()
+			Expect(count - initialCount).To(Equal(uint64(1)))
+			Expect(sum - initialSum).To(BeNumerically("~", latency.Seconds(), 0.001))
+
+			By("Transitioning to non-gateway")
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvLocalEndpointRemoved, endpoint)
+			t.awaitEvent(testing.EvTransitionToNonGateway, nil)
+			Consistently(latencies, 300*time.Millisecond).ShouldNot(Receive())
+		})
+	})
+
+	When("remote Endpoint events are handled for multiple clusters", func() {
+		It("should count the events per known cluster and event type", func() {
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+
+			endpoint1.Spec.PublicIP = "10.1.1.1"
+			t.UpdateEndpoint(endpoint1)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint1)
+
+			Expect(t.Controller.Metrics().RemoteEndpointEvents).To(Equal(map[string]map[event.Type]uint64{
+				"remote-cluster1": {event.RemoteEndpointCreated: 1, event.RemoteEndpointUpdated: 1},
+				"remote-cluster2": {event.RemoteEndpointCreated: 1},
+			}))
+
+			t.DeleteEndpoint(endpoint2.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint2)
+
+			Expect(t.Controller.Metrics().RemoteEndpointEvents).To(Equal(map[string]map[event.Type]uint64{
+				"remote-cluster1": {event.RemoteEndpointCreated: 1, event.RemoteEndpointUpdated: 1},
+			}))
+		})
+
+		It("should count the events for unknown clusters under the unknown cluster ID", func() {
+			endpoint := testing.NewEndpoint("remote-cluster1", "host")
+
+			var auditLog bytes.Buffer
+			Expect(controller.JSONCodec{}.Encode(&auditLog, &controller.EventRecord{
+				Resource:  controller.EndpointResource,
+				Operation: controller.DeleteOperation,
+				Object:    endpoint,
+			})).To(Succeed())
+
+			Expect(t.Controller.Replay(controller.NewReplaySource(&auditLog))).To(Succeed())
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint)
+
+			Expect(t.Controller.Metrics().RemoteEndpointEvents).To(Equal(map[string]map[event.Type]uint64{
+				controller.UnknownClusterID: {event.RemoteEndpointRemoved: 1},
+			}))
+		})
+	})
+
+	When("the number of queued events is bounded and the handlers are slow", func() {
+		const maxQueued = 2
+
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+
+			t.Configure = func(config *controller.Config) {
+				config.MaxQueuedEvents = maxQueued
+
+				_, err := config.Registry.AddHandler(&blockingNodeHandler{blockOn: "blocker", release: release})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should not queue more events than the bound", func() {
+			blocker := t.CreateNode(testing.NewNode("blocker"))
+			t.awaitEvent(testing.EvNodeCreated, blocker)
+
+			var names []string
+
+			for i := 1; i <= 5; i++ {
+				names = append(names, t.CreateNode(testing.NewNode(fmt.Sprintf("node%d", i))).Name)
+			}
+
+			Eventually(t.Controller.QueuedEvents).Should(Equal(maxQueued))
+			Consistently(t.Controller.QueuedEvents).Should(BeNumerically("<=", maxQueued))
+
+			close(release)
+
+			var dispatched []string
+
+			for range names {
+				var e testing.TestEvent
+				Eventually(t.testEvents).Should(Receive(&e))
+				Expect(e.Name).To(Equal(testing.EvNodeCreated))
+				dispatched = append(dispatched, e.Parameter.(*corev1.Node).Name)
+			}
+
+			Expect(dispatched).To(Equal(names))
+			Expect(t.Controller.QueuedEvents()).To(BeZero())
+		})
+	})
+
+	When("events are processed successfully", func() {
+		It("should advance the last success time for the event type", func() {
+			Expect(t.Controller.LastSuccess(event.NodeUpdated)).To(BeZero())
+
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			Eventually(func() time.Time {
+				return t.Controller.LastSuccess(event.NodeCreated)
+			}).ShouldNot(BeZero())
+
+			node.Labels = map[string]string{"label": "1"}
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			Eventually(func() time.Time {
+				return t.Controller.LastSuccess(event.NodeUpdated)
+			}).ShouldNot(BeZero())
+
+			first := t.Controller.LastSuccess(event.NodeUpdated)
+
+			time.Sleep(10 * time.Millisecond)
+
+			node.Labels["label"] = "2"
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			Eventually(func() time.Time {
+				return t.Controller.LastSuccess(event.NodeUpdated)
+			}).Should(BeTemporally(">", first))
+		})
+	})
+
+	When("a resource type is resynced", func() {
+		var (
+			node     *corev1.Node
+			endpoint *submV1.Endpoint
+		)
+
+		JustBeforeEach(func() {
+			node = t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			endpoint = t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+
+		It("should re-deliver only the cached objects of that type", func() {
+			Expect(t.Controller.ResyncNodes()).To(Succeed())
+			t.awaitEvent(testing.EvNodeUpdated, node)
+			t.ensureNoEvents()
+
+			Expect(t.Controller.ResyncEndpoints()).To(Succeed())
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			t.ensureNoEvents()
+		})
+
+		It("should return an error for an unwatched resource type", func() {
+			Expect(t.Controller.Resync("Unknown")).ToNot(Succeed())
+		})
+	})
+
+	When("a resource type's watcher is paused", func() {
+		It("should buffer only the events of that type until resumed", func() {
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			Expect(t.Controller.PauseWatcher(controller.NodeResource)).To(Succeed())
+
+			node.Labels = map[string]string{"migrated": "true"}
+			t.UpdateNode(node)
+
+			node2 := t.CreateNode(testing.NewNode("node2"))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			t.ensureNoEvents()
+
+			Expect(t.Controller.ResumeWatcher(controller.NodeResource)).To(Succeed())
+			t.awaitEvent(testing.EvNodeUpdated, node)
+			t.awaitEvent(testing.EvNodeCreated, node2)
+
+			t.DeleteNode(node2.Name)
+			t.awaitEvent(testing.EvNodeRemoved, node2)
+		})
+
+		It("should return an error for an unwatched resource type", func() {
+			Expect(t.Controller.PauseWatcher("Unknown")).ToNot(Succeed())
+			Expect(t.Controller.ResumeWatcher("Unknown")).ToNot(Succeed())
+		})
+
+		Context("and the buffered events fail once resumed", func() {
+			var failing *failingNodeHandler
+
+			BeforeEach(func() {
+				failing = &failingNodeHandler{}
+
+				t.Configure = func(config *controller.Config) {
+					_, err := config.Registry.AddHandler(failing)
+					Expect(err).To(Succeed())
+				}
+			})
+
+			It("should coalesce the buffered events per object and retry them", func() {
+				Expect(t.Controller.PauseWatcher(controller.NodeResource)).To(Succeed())
+
+				node := t.CreateNode(testing.NewNode("node1"))
+
+				for i := 1; i <= 3; i++ {
+					node.Labels = map[string]string{"update": strconv.Itoa(i)}
+					t.UpdateNode(node)
+				}
+
+				t.ensureNoEvents()
+
+				failing.fail.Store(true)
+
+				Expect(t.Controller.ResumeWatcher(controller.NodeResource)).To(Succeed())
+				t.awaitEvent(testing.EvNodeCreated, node)
+				Eventually(t.Controller.PendingRetries).Should(HaveKey("Node/node1"))
+
+				failing.fail.Store(false)
+
+				t.awaitEvent(testing.EvNodeCreated, node)
+				t.ensureNoEvents()
+				Eventually(t.Controller.PendingRetries).Should(BeEmpty())
+
+				node.Labels = map[string]string{"update": "4"}
+				t.UpdateNode(node)
+				t.awaitEvent(testing.EvNodeUpdated, node)
+			})
+		})
+
+		Context("and a buffered event keeps failing once resumed", func() {
+			var (
+				failing   *failingNodeHandler
+				fakeClock *testingclock.FakeClock
+			)
+
+			BeforeEach(func() {
+				failing = &failingNodeHandler{failOnly: "node1"}
+				fakeClock = testingclock.NewFakeClock(time.Now())
+
+				t.Configure = func(config *controller.Config) {
+					config.Clock = fakeClock
+
+					_, err := config.Registry.AddHandler(failing)
+					Expect(err).To(Succeed())
+				}
+			})
+
+			It("should not hold back the events of other objects while it awaits a retry", func() {
+				Expect(t.Controller.PauseWatcher(controller.NodeResource)).To(Succeed())
+
+				node1 := t.CreateNode(testing.NewNode("node1"))
+				t.ensureNoEvents()
+
+				failing.fail.Store(true)
+
+				Expect(t.Controller.ResumeWatcher(controller.NodeResource)).To(Succeed())
+				t.awaitEvent(testing.EvNodeCreated, node1)
+
+				node2 := t.CreateNode(testing.NewNode("node2"))
+				t.awaitEvent(testing.EvNodeCreated, node2)
+
+				node1.Labels = map[string]string{"label": "value"}
+				t.UpdateNode(node1)
+				t.ensureNoEvents()
+
+				failing.fail.Store(false)
+				fakeClock.Step(time.Minute)
+
+				t.awaitEvent(testing.EvNodeCreated, node1)
+				t.ensureNoEvents()
+			})
+		})
+	})
+
+	When("the controller's leadership status changes", func() {
+		var leadership *leadershipHandler
+
+		BeforeEach(func() {
+			leadership = &leadershipHandler{changes: make(chan bool, 10)}
+
+			t.Configure = func(config *controller.Config) {
+				_, err := config.Registry.AddHandler(leadership)
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should notify the handlers of the changes", func() {
+			Expect(t.Controller.SetLeader(false)).To(Succeed())
+			Consistently(leadership.changes).ShouldNot(Receive())
+
+			Expect(t.Controller.SetLeader(true)).To(Succeed())
+			Eventually(leadership.changes).Should(Receive(BeTrue()))
+
+			Expect(t.Controller.SetLeader(true)).To(Succeed())
+			Consistently(leadership.changes).ShouldNot(Receive())
+
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			Expect(t.Controller.SetLeader(false)).To(Succeed())
+			Eventually(leadership.changes).Should(Receive(BeFalse()))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+
+		Context("and events are suppressed while not the leader", func() {
+			BeforeEach(func() {
+				configure := t.Configure
+
+				t.Configure = func(config *controller.Config) {
+					configure(config)
+					config.SuppressEventsWhileNotLeader = true
+				}
+			})
+
+			It("should buffer the events until it becomes the leader", func() {
+				node := t.CreateNode(testing.NewNode("node1"))
+				t.ensureNoEvents()
+
+				Expect(t.Controller.SetLeader(true)).To(Succeed())
+				Eventually(leadership.changes).Should(Receive(BeTrue()))
+				t.awaitEvent(testing.EvNodeCreated, node)
+
+				By("Losing the leadership")
+
+				Expect(t.Controller.SetLeader(false)).To(Succeed())
+				Eventually(leadership.changes).Should(Receive(BeFalse()))
+
+				endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+
+				node.Labels = map[string]string{"label": "value"}
+				t.UpdateNode(node)
+				t.ensureNoEvents()
+
+				By("Regaining the leadership")
+
+				Expect(t.Controller.SetLeader(true)).To(Succeed())
+				Eventually(leadership.changes).Should(Receive(BeTrue()))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+				t.awaitEvent(testing.EvNodeUpdated, node)
+			})
+
+			It("should keep a paused watcher's events buffered until it's also resumed", func() {
+				Expect(t.Controller.PauseWatcher(controller.NodeResource)).To(Succeed())
+
+				node := t.CreateNode(testing.NewNode("node1"))
+
+				Expect(t.Controller.SetLeader(true)).To(Succeed())
+				t.ensureNoEvents()
+
+				Expect(t.Controller.ResumeWatcher(controller.NodeResource)).To(Succeed())
+				t.awaitEvent(testing.EvNodeCreated, node)
+			})
+
+			Context("and the buffered events fail once it becomes the leader", func() {
+				var failing *failingNodeHandler
+
+				BeforeEach(func() {
+					failing = &failingNodeHandler{}
+					configure := t.Configure
+
+					t.Configure = func(config *controller.Config) {
+						configure(config)
+
+						_, err := config.Registry.AddHandler(failing)
+						Expect(err).To(Succeed())
+					}
+				})
+
+				It("should retry them", func() {
+					node := t.CreateNode(testing.NewNode("node1"))
+					t.ensureNoEvents()
+
+					failing.fail.Store(true)
+
+					Expect(t.Controller.SetLeader(true)).To(Succeed())
+					Eventually(leadership.changes).Should(Receive(BeTrue()))
+					t.awaitEvent(testing.EvNodeCreated, node)
+
+					failing.fail.Store(false)
+
+					t.awaitEvent(testing.EvNodeCreated, node)
+					t.ensureNoEvents()
+				})
+			})
+		})
+
+		It("should fail to create the controller if combined with ResourcePriority", func() {
+			registry, err := event.NewRegistry("leadership-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			_, err = controller.New(&controller.Config{
+				RestMapper:                   test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:                       dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:                     registry,
+				ResourcePriority:             []string{controller.NodeResource},
+				SuppressEventsWhileNotLeader: true,
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the gateway label on the local Node changes and the gateway state is refreshed", func() {
+		It("should notify the handler of the transitions", func() {
+			node := testing.NewNode(t.Hostname)
+			node.Labels = map[string]string{controller.GatewayLabel: "true"}
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			By("Refreshing without a local Endpoint for the Node")
+
+			Expect(t.Controller.RefreshGatewayState()).To(Succeed())
+			t.ensureNoEvents()
+			Expect(t.handler.State().IsOnGateway()).To(BeFalse())
+
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			Expect(t.Controller.RefreshGatewayState()).To(Succeed())
+			t.ensureNoEvents()
+
+			By("Removing the gateway label")
+
+			node.Labels = map[string]string{"other": "label"}
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			Expect(t.Controller.RefreshGatewayState()).To(Succeed())
+			t.awaitEvent(testing.EvTransitionToNonGateway, nil)
+			Expect(t.handler.State().IsOnGateway()).To(BeFalse())
+
+			By("Restoring the gateway label")
+
+			node.Labels = map[string]string{controller.GatewayLabel: "true"}
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			Expect(t.Controller.RefreshGatewayState()).To(Succeed())
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+			Expect(t.handler.State().IsOnGateway()).To(BeTrue())
+		})
+	})
+
+	When("a handler is interested in whether the local Node is the sole gateway", func() {
+		var sole chan bool
+
+		BeforeEach(func() {
+			sole = make(chan bool, 10)
+
+			t.Configure = func(config *controller.Config) {
+				_, err := config.Registry.AddHandler(&soleGatewayHandler{sole: sole})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		newGatewayNode := func(name string) *corev1.Node {
+			node := testing.NewNode(name)
+			node.Labels = map[string]string{controller.GatewayLabel: "true"}
+
+			return node
+		}
+
+		It("should notify it when the sole gateway status changes", func() {
+			By("Creating the local gateway Node")
+
+			local := t.CreateNode(newGatewayNode(t.Hostname))
+			t.awaitEvent(testing.EvNodeCreated, local)
+			Eventually(sole).Should(Receive(BeTrue()))
+
+			By("Creating another gateway Node")
+
+			other := t.CreateNode(newGatewayNode("other-gateway"))
+			t.awaitEvent(testing.EvNodeCreated, other)
+			Eventually(sole).Should(Receive(BeFalse()))
+
+			By("Creating a non-gateway Node")
+
+			worker := t.CreateNode(testing.NewNode("worker"))
+			t.awaitEvent(testing.EvNodeCreated, worker)
+			Consistently(sole).ShouldNot(Receive())
+
+			By("Removing the other gateway Node")
+
+			t.DeleteNode(other.Name)
+			t.awaitEvent(testing.EvNodeRemoved, other)
+			Eventually(sole).Should(Receive(BeTrue()))
+
+			By("Removing the gateway label from the local Node")
+
+			local.Labels = map[string]string{"other": "label"}
+			t.UpdateNode(local)
+			t.awaitEvent(testing.EvNodeUpdated, local)
+			Eventually(sole).Should(Receive(BeFalse()))
+		})
+
+		It("should not notify it if only other gateway Nodes exist", func() {
+			other := t.CreateNode(newGatewayNode("other-gateway"))
+			t.awaitEvent(testing.EvNodeCreated, other)
+
+			local := t.CreateNode(testing.NewNode(t.Hostname))
+			t.awaitEvent(testing.EvNodeCreated, local)
+			Consistently(sole).ShouldNot(Receive())
+		})
+	})
+
+	When("a preferred Node address type is configured", func() {
+		var (
+			addrType corev1.NodeAddressType
+			node     *corev1.Node
+		)
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.PreferredNodeAddressType = addrType
+			}
+
+			node = testing.NewNode(t.Hostname)
+			node.Status.Addresses = []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: corev1.NodeExternalIP, Address: "172.16.0.1"},
+				{Type: corev1.NodeHostName, Address: "node-host"},
+			}
+		})
+
+		JustBeforeEach(func() {
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+		})
+
+		testGatewayEndpoint := func(hostname, privateIP string) {
+			endpoint := testing.NewEndpoint(testing.LocalClusterID, hostname)
+			endpoint.Spec.PrivateIP = privateIP
+			t.CreateEndpoint(endpoint)
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+		}
+
+		Context("of InternalIP", func() {
+			BeforeEach(func() {
+				addrType = corev1.NodeInternalIP
+			})
+
+			It("should identify the local gateway Endpoint by the first InternalIP", func() {
+				testGatewayEndpoint("other-host", "10.0.0.1")
+			})
+		})
+
+		Context("of ExternalIP", func() {
+			BeforeEach(func() {
+				addrType = corev1.NodeExternalIP
+			})
+
+			It("should identify the local gateway Endpoint by the ExternalIP", func() {
+				testGatewayEndpoint("other-host", "172.16.0.1")
+			})
+		})
+
+		Context("of Hostname", func() {
+			BeforeEach(func() {
+				addrType = corev1.NodeHostName
+			})
+
+			It("should identify the local gateway Endpoint by the Hostname address", func() {
+				testGatewayEndpoint("node-host", "10.0.0.3")
+			})
+		})
+
+		Context("and the local Node has no address of the type", func() {
+			BeforeEach(func() {
+				addrType = corev1.NodeExternalIP
+				node.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}
+			})
+
+			It("should identify the local gateway Endpoint by this host's name", func() {
+				t.testLocalEndpoint()
+			})
+		})
+	})
+
+	When("the local Node is tainted as ineligible to be a gateway", func() {
+		const ineligibleTaint = "submariner.io/gateway-ineligible"
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.IneligibleGatewayTaint = ineligibleTaint
+			}
+		})
+
+		It("should drop and regain the gateway status as the taint is added and removed", func() {
+			node := testing.NewNode(t.Hostname)
+			node.Labels = map[string]string{controller.GatewayLabel: "true"}
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			By("Tainting the local Node")
+
+			node.Spec.Taints = []corev1.Taint{{Key: ineligibleTaint, Effect: corev1.TaintEffectNoSchedule}}
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+			t.awaitEvent(testing.EvTransitionToNonGateway, nil)
+			Expect(t.handler.State().IsOnGateway()).To(BeFalse())
+
+			Expect(t.Controller.RefreshGatewayState()).To(Succeed())
+			t.ensureNoEvents()
+			Expect(t.handler.State().IsOnGateway()).To(BeFalse())
+
+			By("Removing the taint from the local Node")
+
+			node.Spec.Taints = nil
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+			Expect(t.handler.State().IsOnGateway()).To(BeTrue())
+		})
+
+		It("should ignore taints with other keys", func() {
+			node := testing.NewNode(t.Hostname)
+			node.Spec.Taints = []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+		})
+	})
+
+	When("Endpoint events on NotReady Nodes are suppressed", func() {
+		var dropped chan controller.DropReason
+
+		BeforeEach(func() {
+			dropped = make(chan controller.DropReason, 10)
+
+			t.Configure = func(config *controller.Config) {
+				config.SuppressEndpointsOnNotReadyNodes = true
+				config.OnEventDropped = func(_ event.Type, _ runtime.Object, reason controller.DropReason) {
+					dropped <- reason
+				}
+			}
+		})
+
+		setNodeReady := func(node *corev1.Node, status corev1.ConditionStatus) {
+			node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+		}
+
+		It("should suppress the Endpoint events while the Node is NotReady", func() {
+			node := testing.NewNode("remote-host")
+			setNodeReady(node, corev1.ConditionTrue)
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster", "remote-host", "10.1.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			By("Setting the Node NotReady")
+
+			setNodeReady(node, corev1.ConditionFalse)
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			endpoint.Spec.Subnets = []string{"10.2.0.0/16"}
+			t.UpdateEndpoint(endpoint)
+			Eventually(dropped).Should(Receive(Equal(controller.DropReasonNodeNotReady)))
+			t.ensureNoEvents()
+
+			filtered := t.Controller.FilteredEndpoints()
+			Expect(filtered).To(HaveLen(1))
+			Expect(filtered[0].Reason).To(Equal(controller.DropReasonNodeNotReady))
+
+			By("Setting the Node Ready")
+
+			setNodeReady(node, corev1.ConditionTrue)
+			t.UpdateNode(node)
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			endpoint.Spec.Subnets = []string{"10.3.0.0/16"}
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			Expect(t.Controller.FilteredEndpoints()).To(BeEmpty())
+		})
+
+		It("should suppress the creation of an Endpoint on a NotReady Node but not its removal", func() {
+			node := testing.NewNode("remote-host")
+			setNodeReady(node, corev1.ConditionFalse)
+			t.CreateNode(node)
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster", "remote-host", "10.1.0.0/16"))
+			Eventually(dropped).Should(Receive(Equal(controller.DropReasonNodeNotReady)))
+
+			other := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "other-host", "10.2.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, other)
+			t.ensureNoEvents()
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint)
+		})
+	})
+
+	When("the controller is stopped", func() {
+		It("should cancel the handler state's context", func() {
+			ctx := t.handler.State().Context()
+			Expect(ctx.Err()).To(Succeed())
+
+			t.Controller.Stop()
+			Eventually(ctx.Done()).Should(BeClosed())
+		})
+	})
+
+	When("awaiting a gateway state", func() {
+		It("should unblock on transition", func() {
+			Expect(t.Controller.AwaitGatewayState(context.TODO(), false)).To(Succeed())
+
+			result := make(chan error, 1)
+
+			go func() {
+				result <- t.Controller.AwaitGatewayState(context.TODO(), true)
+			}()
+
+			Consistently(result).ShouldNot(Receive())
+
+			endpoint := t.CreateLocalHostEndpoint()
+			Eventually(result).Should(Receive(Succeed()))
+
+			go func() {
+				result <- t.Controller.AwaitGatewayState(context.TODO(), false)
+			}()
+
+			t.DeleteEndpoint(endpoint.Name)
+			Eventually(result).Should(Receive(Succeed()))
+		})
+
+		It("should return an error when the context ends", func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancel()
+
+			Expect(t.Controller.AwaitGatewayState(ctx, true)).To(MatchError(ContainSubstring(context.DeadlineExceeded.Error())))
+		})
+	})
+
+	When("the node transitions to non-gateway with multiple handlers", func() {
+		var (
+			slow    *drainHandler
+			fast    *drainHandler
+			drained chan []bool
+		)
+
+		BeforeEach(func() {
+			slow = &drainHandler{name: "slow-handler", delay: 300 * time.Millisecond}
+			fast = &drainHandler{name: "fast-handler"}
+			drained = make(chan []bool, 10)
+
+			t.Configure = func(config *controller.Config) {
+				for _, h := range []*drainHandler{fast, slow} {
+					_, err := config.Registry.AddHandler(h)
+					Expect(err).To(Succeed())
+				}
+
+				config.OnDrainComplete = func() {
+					drained <- []bool{slow.drained.Load(), fast.drained.Load()}
+				}
+			}
+		})
+
+		It("should signal drain complete after the slowest handler completes", func() {
+			endpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+			Consistently(drained).ShouldNot(Receive())
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvLocalEndpointRemoved, endpoint)
+			t.awaitEvent(testing.EvTransitionToNonGateway, nil)
+			Eventually(drained).Should(Receive(Equal([]bool{true, true})))
+		})
+	})
+
+	When("the gateway state is refreshed and the local Node doesn't exist", func() {
+		It("should return an error", func() {
+			Expect(t.Controller.RefreshGatewayState()).ToNot(Succeed())
+		})
+	})
+
+	When("the IP of a local Endpoint changes", func() {
+		It("should notify the handler of the IP change", func() {
+			endpoint := testing.NewEndpoint(testing.LocalClusterID, t.Hostname)
+			endpoint.Spec.PublicIP = "1.2.3.4"
+			endpoint.Spec.PrivateIP = "10.0.0.1"
+			t.CreateEndpoint(endpoint)
+			t.awaitEvent(testing.EvLocalEndpointCreated, endpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			endpoint.Labels = map[string]string{"labeled-i-am": "i-am"}
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvLocalEndpointUpdated, endpoint)
+			t.ensureNoEvents()
+
+			oldEndpoint := endpoint.DeepCopy()
+			endpoint.Spec.PrivateIP = "10.0.0.2"
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvLocalEndpointUpdated, endpoint)
+			t.awaitEvent(testing.EvLocalEndpointIPChanged, testing.EndpointIPChange{Old: oldEndpoint, New: endpoint})
+			t.ensureNoEvents()
+		})
+	})
+
 	When("a local Endpoint on this host is created, updated and deleted", func() {
 		It("should correctly notify the handler", func() {
 			t.testLocalEndpoint()
 		})
-	})
+	})
+
+	When("remote Endpoints are created, updated and deleted", func() {
+		It("should correctly notify the handler", func() {
+			t.testRemoteEndpoints()
+		})
+	})
+
+	When("the handler returns an error from an event notification", func() {
+		It("should retry the event", func() {
+			t.handler.FailOnEvent(testing.EvLocalEndpointCreated, testing.EvLocalEndpointUpdated, testing.EvLocalEndpointRemoved,
+				testing.EvTransitionToGateway, testing.EvTransitionToNonGateway, testing.EvRemoteEndpointCreated,
+				testing.EvRemoteEndpointUpdated, testing.EvRemoteEndpointRemoved)
+
+			t.testLocalEndpoint()
+			t.testRemoteEndpoints()
+		})
+	})
+
+	Specify("ClusterID should return the local cluster ID", func() {
+		Expect(t.Controller.ClusterID()).To(Equal(testing.LocalClusterID))
+		Expect(t.handler.State().GetClusterID()).To(Equal(testing.LocalClusterID))
+	})
+
+	Specify("Namespace should return the namespace in which the Endpoints are watched", func() {
+		Expect(t.Controller.Namespace()).To(Equal(testing.Namespace))
+	})
+
+	Specify("RestMapper should return the controller's RESTMapper", func() {
+		Expect(t.Controller.RestMapper()).ToNot(BeNil())
+
+		_, err := t.Controller.RestMapper().RESTMapping(schema.GroupKind{Group: submV1.SchemeGroupVersion.Group, Kind: "Endpoint"})
+		Expect(err).To(Succeed())
+	})
+
+	When("the registry is swapped while events are being processed", func() {
+		It("should dispatch each event to either the old or the new handlers exactly once", func() {
+			const numEndpoints = 20
+
+			newEvents := make(chan testing.TestEvent, 1000)
+			newHandler := testing.NewTestHandler("new-handler", event.AnyNetworkPlugin, newEvents)
+
+			newRegistry, err := event.NewRegistry("new-registry", event.AnyNetworkPlugin, newHandler)
+			Expect(err).To(Succeed())
+
+			var names []string
+
+			created := make(chan struct{})
+
+			go func() {
+				defer GinkgoRecover()
+
+				for i := 0; i < numEndpoints; i++ {
+					names = append(names, t.CreateEndpoint(testing.NewEndpoint(fmt.Sprintf("remote-cluster%d", i), "host")).Name)
+
+					if i == numEndpoints/2 {
+						go func() {
+							defer GinkgoRecover()
+							Expect(t.Controller.SwapRegistry(newRegistry)).To(Succeed())
+						}()
+					}
+				}
+
+				close(created)
+			}()
+
+			Eventually(created).Should(BeClosed())
+
+			received := map[string]int{}
+
+			Eventually(func() int {
+				for {
+					select {
+					case e := <-newEvents:
+						if e.Name == testing.EvRemoteEndpointCreated {
+							received[e.Parameter.(*submV1.Endpoint).Name]++
+						}
+					default:
+						return len(received)
+					}
+				}
+			}, 5*time.Second).Should(Equal(numEndpoints))
+
+			Consistently(newEvents).ShouldNot(Receive(HaveField("Name", testing.EvRemoteEndpointCreated)))
+
+			for _, name := range names {
+				Expect(received).To(HaveKeyWithValue(name, 1))
+			}
+
+			Eventually(t.testEvents).Should(Receive(HaveField("Name", testing.EvStop)))
+			t.ensureNoEvents()
+		})
+	})
+
+	When("a handler is added after the controller is started", func() {
+		It("should initialize the handler and replay the current state", func() {
+			localEndpoint := t.CreateLocalHostEndpoint()
+			t.awaitEvent(testing.EvLocalEndpointCreated, localEndpoint)
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+
+			remoteEndpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, remoteEndpoint)
+
+			otherEvents := make(chan testing.TestEvent, 100)
+			other := testing.NewTestHandler("other-handler", event.AnyNetworkPlugin, otherEvents)
+
+			Expect(t.Controller.AddHandler(other)).To(Succeed())
+			Expect(other.Initialized).To(BeTrue())
+			Expect(other.State().IsOnGateway()).To(BeTrue())
+
+			Expect(otherEvents).To(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvLocalEndpointCreated, Parameter: localEndpoint,
+			})))
+			Expect(otherEvents).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: testing.EvTransitionToGateway})))
+			Expect(otherEvents).To(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvRemoteEndpointCreated, Parameter: remoteEndpoint,
+			})))
+
+			By("Create another remote Endpoint")
+
+			remoteEndpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, remoteEndpoint2)
+			Eventually(otherEvents).Should(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvRemoteEndpointCreated, Parameter: remoteEndpoint2,
+			})))
+		})
+	})
+
+	When("multiple Endpoints briefly coexist for a remote cluster during a gateway failover", func() {
+		It("should return the most recent Endpoint as the gateway Endpoint", func() {
+			const clusterID = "remote-cluster1"
+
+			gatewayEndpoint := func(clusterID string) *submV1.Endpoint {
+				endpoint, found := t.handler.State().GetGatewayEndpoint(clusterID)
+				Expect(found).To(Equal(endpoint != nil))
+
+				return endpoint
+			}
+
+			Expect(gatewayEndpoint(clusterID)).To(BeNil())
+
+			now := time.Now()
+
+			oldEndpoint := testing.NewEndpoint(clusterID, "host1")
+			oldEndpoint.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+			t.CreateEndpoint(oldEndpoint)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, oldEndpoint)
+
+			Expect(gatewayEndpoint(clusterID)).To(Equal(oldEndpoint))
+
+			newEndpoint := testing.NewEndpoint(clusterID, "host2")
+			newEndpoint.CreationTimestamp = metav1.NewTime(now)
+			t.CreateEndpoint(newEndpoint)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, newEndpoint)
+
+			Expect(gatewayEndpoint(clusterID)).To(Equal(newEndpoint))
+
+			t.DeleteEndpoint(oldEndpoint.Name)
+			Eventually(func() []submV1.Endpoint {
+				return t.handler.State().GetRemoteEndpoints()
+			}).Should(HaveLen(1))
+
+			Expect(gatewayEndpoint(clusterID)).To(Equal(newEndpoint))
+
+			Expect(gatewayEndpoint("other-cluster")).To(BeNil())
+		})
+	})
+
+	When("remote Endpoints share the same identity", func() {
+		newEndpoint := func(clusterID, cableName string) *submV1.Endpoint {
+			endpoint := testing.NewEndpoint(clusterID, "host")
+			endpoint.Spec.CableName = cableName
+			endpoint.Labels = map[string]string{"logical-id": "gateway1"}
+
+			return endpoint
+		}
+
+		Context("by default", func() {
+			It("should deduplicate the Endpoints with the same cluster ID and cable name", func() {
+				endpoint1 := t.CreateEndpoint(newEndpoint("remote-cluster1", "cable1"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+				endpoint2 := t.CreateEndpoint(newEndpoint("remote-cluster1", "cable1"))
+				t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint2)
+
+				endpoint3 := t.CreateEndpoint(newEndpoint("remote-cluster1", "cable2"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint3)
+			})
+		})
+
+		Context("as per a custom identity function", func() {
+			var dropped chan controller.DropReason
+
+			BeforeEach(func() {
+				dropped = make(chan controller.DropReason, 10)
+
+				t.Configure = func(config *controller.Config) {
+					config.EndpointIdentity = func(endpoint *submV1.Endpoint) string {
+						return endpoint.Labels["logical-id"]
+					}
+
+					config.OnEventDropped = func(_ event.Type, _ runtime.Object, reason controller.DropReason) {
+						dropped <- reason
+					}
+				}
+			})
+
+			It("should deduplicate and track the Endpoints by the custom identity", func() {
+				endpoint1 := t.CreateEndpoint(newEndpoint("remote-cluster1", "cable1"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+				By("Creating an Endpoint with the same identity under a renamed cluster")
+
+				endpoint2 := t.CreateEndpoint(newEndpoint("remote-cluster2", "cable2"))
+				t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint2)
+				Expect(t.handler.State().GetRemoteEndpoints()).To(HaveLen(1))
+
+				By("Deleting the superseded Endpoint")
+
+				t.DeleteEndpoint(endpoint1.Name)
+				Eventually(dropped).Should(Receive(Equal(controller.DropReasonSuperseded)))
+				t.ensureNoEvents()
+				Expect(t.handler.State().GetRemoteEndpoints()).To(HaveLen(1))
+
+				By("Deleting the current Endpoint")
+
+				t.DeleteEndpoint(endpoint2.Name)
+				t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint2)
+				Expect(t.handler.State().GetRemoteEndpoints()).To(BeEmpty())
+			})
+		})
+	})
+
+	When("a remote Endpoint is created and removed", func() {
+		It("should report whether the remote cluster is present", func() {
+			const clusterID = "remote-cluster1"
+
+			Expect(t.handler.State().HasRemoteCluster(clusterID)).To(BeFalse())
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint(clusterID, "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			Expect(t.handler.State().HasRemoteCluster(clusterID)).To(BeTrue())
+			Expect(t.handler.State().HasRemoteCluster("other-cluster")).To(BeFalse())
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint)
+
+			Expect(t.handler.State().HasRemoteCluster(clusterID)).To(BeFalse())
+		})
+	})
+
+	When("remote Endpoints advertise overlapping subnets", func() {
+		It("should notify the handler of the subnet conflict", func() {
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host", "10.0.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host", "192.168.0.0/16", "10.0.1.0/24"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+			t.awaitEvent(testing.EvSubnetConflictDetected, testing.SubnetConflict{A: endpoint2, B: endpoint1, Overlap: "10.0.1.0/24"})
+
+			By("Create a remote Endpoint with non-overlapping subnets")
+
+			endpoint3 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster3", "host", "172.16.0.0/16"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint3)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("the handler is disabled and then re-enabled", func() {
+		It("should only be notified of events outside the muted window", func() {
+			t.Controller.SetHandlerEnabled(testHandlerName, false)
+
+			endpoint1 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			Eventually(func() []submV1.Endpoint {
+				return t.handler.State().GetRemoteEndpoints()
+			}).Should(HaveLen(1))
+			t.ensureNoEvents()
+
+			t.Controller.SetHandlerEnabled(testHandlerName, true)
+
+			endpoint2 := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+			Expect(t.handler.remoteEndpoints.Load()).To(ConsistOf(*endpoint1, *endpoint2))
+		})
+	})
+
+	When("the health of a remote Endpoint is reported", func() {
+		It("should update the Endpoint info and notify the handler on change", func() {
+			const clusterID = "remote-cluster1"
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint(clusterID, "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			info, found := t.handler.State().GetEndpointInfo(clusterID)
+			Expect(found).To(BeTrue())
+			Expect(info).To(Equal(&event.EndpointInfo{Endpoint: endpoint, Healthy: true}))
+
+			t.Controller.ReportEndpointHealth(clusterID, false)
+			t.awaitEvent(testing.EvEndpointHealthChanged, testing.EndpointHealth{Endpoint: endpoint, Healthy: false})
+
+			info, _ = t.handler.State().GetEndpointInfo(clusterID)
+			Expect(info.Healthy).To(BeFalse())
+
+			t.Controller.ReportEndpointHealth(clusterID, false)
+			t.ensureNoEvents()
+
+			t.Controller.ReportEndpointHealth(clusterID, true)
+			t.awaitEvent(testing.EvEndpointHealthChanged, testing.EndpointHealth{Endpoint: endpoint, Healthy: true})
+
+			info, _ = t.handler.State().GetEndpointInfo(clusterID)
+			Expect(info.Healthy).To(BeTrue())
+
+			t.Controller.ReportEndpointHealth("unknown-cluster", false)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("a single backend config entry of a remote Endpoint changes", func() {
+		It("should notify the handler of the changed entry", func() {
+			endpoint := testing.NewEndpoint("remote-cluster1", "host")
+			endpoint.Spec.BackendConfig = map[string]string{"psk": "secret", "port": "4500"}
+
+			endpoint = t.CreateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			endpoint.Spec.BackendConfig["port"] = "4501"
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			t.awaitEvent(testing.EvEndpointBackendConfigChanged, &testing.BackendConfigChange{
+				ClusterID: "remote-cluster1",
+				Changed:   map[string]string{"port": "4501"},
+			})
+
+			By("Removing a backend config entry")
+
+			delete(endpoint.Spec.BackendConfig, "psk")
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			t.awaitEvent(testing.EvEndpointBackendConfigChanged, &testing.BackendConfigChange{
+				ClusterID: "remote-cluster1",
+				Changed:   map[string]string{"psk": ""},
+			})
+
+			By("Updating another field")
+
+			endpoint.Spec.PublicIP = "10.1.1.1"
+			t.UpdateEndpoint(endpoint)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, endpoint)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("remote Endpoints carry annotations", func() {
+		It("should return the annotation values for the given cluster", func() {
+			endpoint1 := testing.NewEndpoint("remote-cluster1", "host")
+			endpoint1.Annotations = map[string]string{"routing.submariner.io/preference": "low-latency"}
+			endpoint1 = t.CreateEndpoint(endpoint1)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint1)
+
+			endpoint2 := testing.NewEndpoint("remote-cluster2", "host")
+			endpoint2.Annotations = map[string]string{"routing.submariner.io/preference": "high-bandwidth"}
+			endpoint2 = t.CreateEndpoint(endpoint2)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint2)
+
+			value, found := t.handler.State().GetEndpointAnnotation("remote-cluster1", "routing.submariner.io/preference")
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("low-latency"))
+
+			value, found = t.handler.State().GetEndpointAnnotation("remote-cluster2", "routing.submariner.io/preference")
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("high-bandwidth"))
+
+			_, found = t.handler.State().GetEndpointAnnotation("remote-cluster1", "unknown")
+			Expect(found).To(BeFalse())
+
+			_, found = t.handler.State().GetEndpointAnnotation("unknown-cluster", "routing.submariner.io/preference")
+			Expect(found).To(BeFalse())
+
+			info, found := t.handler.State().GetEndpointInfo("remote-cluster1")
+			Expect(found).To(BeTrue())
+			Expect(info.Annotations).To(Equal(endpoint1.Annotations))
+		})
+	})
+
+	When("a list page size is configured", func() {
+		var client *testClient
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				client = &testClient{Interface: config.Client}
+				config.Client = client
+				config.ListPageSize = 10
+			}
+		})
+
+		It("should pass the limit in the list options", func() {
+			Eventually(client.listLimits).Should(HaveLen(2))
+			Expect(client.listLimits()).To(HaveEach(int64(10)))
+		})
+	})
+
+	When("an external informer factory is configured", func() {
+		var factory dynamicinformer.DynamicSharedInformerFactory
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				factory = dynamicinformer.NewDynamicSharedInformerFactory(config.Client, 0)
+				config.InformerFactory = factory
+			}
+		})
+
+		It("should notify Node events from its informers", func() {
+			node := t.CreateNode(testing.NewNode(t.Hostname))
+
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			node.Labels = map[string]string{"labeled-i-am": "i-am"}
+			t.UpdateNode(node)
+
+			t.awaitEvent(testing.EvNodeUpdated, node)
+
+			t.DeleteNode(node.GetName())
+
+			t.awaitEvent(testing.EvNodeRemoved, node)
+			t.ensureNoEvents()
+		})
+
+		It("should notify remote Endpoint events from its informers", func() {
+			t.testRemoteEndpoints()
+		})
+
+		It("should share the informers with the factory", func() {
+			node := t.CreateNode(testing.NewNode(t.Hostname))
+
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			informer := factory.ForResource(*test.GetGroupVersionResourceFor(t.Controller.RestMapper(), &corev1.Node{})).Informer()
+			Expect(informer.HasSynced()).To(BeTrue())
+			Expect(informer.GetStore().ListKeys()).To(ConsistOf(node.Name))
+			Expect(t.handler.State().GetNodes()).To(HaveLen(1))
+		})
+	})
+
+	When("an additional registry is configured", func() {
+		var mirrorEvents chan testing.TestEvent
+
+		BeforeEach(func() {
+			mirrorEvents = make(chan testing.TestEvent, 100)
+
+			t.Configure = func(config *controller.Config) {
+				mirror, err := event.NewRegistry("mirror-registry", event.AnyNetworkPlugin,
+					testing.NewTestHandler("mirror-handler", event.AnyNetworkPlugin, mirrorEvents))
+				Expect(err).To(Succeed())
+
+				config.Registries = []*event.Registry{mirror}
+			}
+		})
+
+		It("should notify the handlers in both registries of the same events", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			Eventually(mirrorEvents).Should(Receive(Equal(testing.TestEvent{
+				Handler: "mirror-handler", Name: testing.EvRemoteEndpointCreated, Parameter: endpoint,
+			})))
+
+			t.DeleteEndpoint(endpoint.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, endpoint)
+			Eventually(mirrorEvents).Should(Receive(Equal(testing.TestEvent{
+				Handler: "mirror-handler", Name: testing.EvRemoteEndpointRemoved, Parameter: endpoint,
+			})))
+		})
+	})
+
+	When("partial start is configured and a watcher is slow to sync", func() {
+		var client *testClient
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				client = &testClient{Interface: config.Client, blockedResource: "nodes", unblock: make(chan struct{})}
+				config.Client = client
+				config.PartialStart = true
+
+				DeferCleanup(func() {
+					select {
+					case <-client.unblock:
+					default:
+						close(client.unblock)
+					}
+				})
+			}
+		})
+
+		It("should start once the first watcher has synced and report the sync progress", func() {
+			Expect(t.Controller.SyncStatus()).To(Equal(map[string]bool{
+				controller.EndpointResource: true,
+				controller.NodeResource:     false,
+			}))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			close(client.unblock)
+
+			Eventually(t.Controller.SyncStatus).Should(Equal(map[string]bool{
+				controller.EndpointResource: true,
+				controller.NodeResource:     true,
+			}))
+
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+		})
+	})
+
+	When("multiple handlers are added and one fails to initialize", func() {
+		var (
+			events   chan testing.TestEvent
+			handlers []event.Handler
+		)
+
+		BeforeEach(func() {
+			events = make(chan testing.TestEvent, 100)
+			handlers = []event.Handler{
+				testing.NewTestHandler("handler1", event.AnyNetworkPlugin, events),
+				&failingInitHandler{TestHandler: testing.NewTestHandler("handler2", event.AnyNetworkPlugin, events)},
+				testing.NewTestHandler("handler3", event.AnyNetworkPlugin, events),
+			}
+		})
+
+		Context("and FailOnHandlerInitError is set", func() {
+			BeforeEach(func() {
+				t.Configure = func(config *controller.Config) {
+					config.FailOnHandlerInitError = true
+				}
+			})
+
+			It("should roll back the handlers already added", func() {
+				Expect(t.Controller.AddHandlers(handlers...)).To(MatchError(ContainSubstring("handler2")))
+				Expect(events).To(Receive(Equal(testing.TestEvent{Handler: "handler1", Name: testing.EvStop})))
+				Expect(handlers[2].(*testing.TestHandler).Initialized).To(BeFalse())
+
+				node := t.CreateNode(testing.NewNode("node1"))
+				t.awaitEvent(testing.EvNodeCreated, node)
+				Consistently(events).ShouldNot(Receive())
+			})
+		})
+
+		Context("and FailOnHandlerInitError isn't set", func() {
+			It("should add the remaining handlers", func() {
+				Expect(t.Controller.AddHandlers(handlers...)).To(MatchError(ContainSubstring("handler2")))
+
+				node := t.CreateNode(testing.NewNode("node1"))
+				t.awaitEvent(testing.EvNodeCreated, node)
+				Eventually(events).Should(Receive(Equal(testing.TestEvent{Handler: "handler1", Name: testing.EvNodeCreated, Parameter: node})))
+				Eventually(events).Should(Receive(Equal(testing.TestEvent{Handler: "handler3", Name: testing.EvNodeCreated, Parameter: node})))
+			})
+		})
+	})
+
+	When("a handler implements PreStart", func() {
+		var (
+			events  chan testing.TestEvent
+			handler *preStartHandler
+		)
+
+		BeforeEach(func() {
+			events = make(chan testing.TestEvent, 10)
+			handler = &preStartHandler{TestHandler: testing.NewTestHandler("pre-start-handler", event.AnyNetworkPlugin, events)}
+		})
+
+		Context("and is registered before the controller is started", func() {
+			var node *corev1.Node
+
+			BeforeEach(func() {
+				t.Configure = func(config *controller.Config) {
+					node = t.CreateNode(testing.NewNode("node1"))
+
+					_, err := config.Registry.AddHandler(handler)
+					Expect(err).To(Succeed())
+				}
+			})
+
+			It("should invoke PreStart before any event callback", func() {
+				Expect(events).To(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: evPreStart})))
+				Eventually(events).Should(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: testing.EvNodeCreated, Parameter: node})))
+				Expect(handler.state).ToNot(BeNil())
+			})
+		})
+
+		Context("and is added after the controller is started", func() {
+			It("should invoke PreStart before replaying the state", func() {
+				t.testLocalEndpoint()
+
+				Expect(t.Controller.AddHandler(handler)).To(Succeed())
+				Expect(events).To(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: evPreStart})))
+				Expect(events).To(Receive(HaveField("Name", testing.EvLocalEndpointCreated)))
+			})
+		})
+
+		Context("and PreStart fails", func() {
+			var config *controller.Config
+
+			BeforeEach(func() {
+				handler.err = errors.New("mock PreStart error")
+
+				registry, err := event.NewRegistry("pre-start-registry", event.AnyNetworkPlugin, handler)
+				Expect(err).To(Succeed())
+
+				config = &controller.Config{
+					RestMapper: test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+					Client:     dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+					Registry:   registry,
+				}
+			})
+
+			startController := func() error {
+				ctl, err := controller.New(config)
+				Expect(err).To(Succeed())
+
+				stopCh := make(chan struct{})
+				DeferCleanup(func() {
+					close(stopCh)
+					ctl.Stop()
+				})
+
+				return ctl.Start(stopCh)
+			}
+
+			It("should fail to start if FailOnHandlerInitError is set", func() {
+				config.FailOnHandlerInitError = true
+				Expect(startController()).To(MatchError(ContainSubstring("mock PreStart error")))
+			})
+
+			It("should still start if FailOnHandlerInitError isn't set", func() {
+				Expect(startController()).To(Succeed())
+			})
+		})
+	})
+
+	When("handlers signal readiness asynchronously", func() {
+		var (
+			handler *readinessHandler
+			config  *controller.Config
+		)
+
+		BeforeEach(func() {
+			handler = &readinessHandler{
+				TestHandler: testing.NewTestHandler("readiness-handler", event.AnyNetworkPlugin, make(chan testing.TestEvent, 10)),
+				ready:       make(chan struct{}),
+			}
+
+			registry, err := event.NewRegistry("readiness-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			config = &controller.Config{
+				RestMapper:           test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:               dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:             registry,
+				WaitForHandlersReady: true,
+			}
+		})
+
+		startController := func() error {
+			ctl, err := controller.New(config)
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+			})
+
+			return ctl.Start(stopCh)
+		}
+
+		It("should wait for the handlers to be ready before Start returns", func() {
+			time.AfterFunc(200*time.Millisecond, func() {
+				close(handler.ready)
+			})
+
+			Expect(startController()).To(Succeed())
+			Expect(handler.ready).To(BeClosed())
+		})
+
+		Context("and a handler isn't ready within the timeout", func() {
+			BeforeEach(func() {
+				config.HandlersReadyTimeout = 100 * time.Millisecond
+			})
+
+			It("should continue the start", func() {
+				Expect(startController()).To(Succeed())
+				Expect(handler.ready).ToNot(BeClosed())
+			})
+
+			Context("and FailOnHandlerInitError is set", func() {
+				BeforeEach(func() {
+					config.FailOnHandlerInitError = true
+				})
+
+				It("should fail the start with an error naming the handler", func() {
+					err := startController()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("readiness-registry/readiness-handler"))
+				})
+			})
+		})
+
+		Context("and WaitForHandlersReady isn't set", func() {
+			BeforeEach(func() {
+				config.WaitForHandlersReady = false
+			})
+
+			It("should not wait for the handlers to be ready", func() {
+				Expect(startController()).To(Succeed())
+				Expect(handler.ready).ToNot(BeClosed())
+			})
+		})
+	})
+
+	When("a sync timeout is configured", func() {
+		var config *controller.Config
+
+		BeforeEach(func() {
+			registry, err := event.NewRegistry("sync-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler("sync-handler", event.AnyNetworkPlugin, make(chan testing.TestEvent, 10)))
+			Expect(err).To(Succeed())
+
+			config = &controller.Config{
+				RestMapper:  test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:      dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:    registry,
+				SyncTimeout: 300 * time.Millisecond,
+			}
+		})
+
+		startController := func() error {
+			ctl, err := controller.New(config)
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+			})
+
+			return ctl.Start(stopCh)
+		}
+
+		Context("and an informer cache never syncs", func() {
+			BeforeEach(func() {
+				config.Client.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "nodes",
+					func(_ k8stesting.Action) (bool, runtime.Object, error) {
+						return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("RBAC denied"))
+					})
+			})
+
+			It("should fail the start with a timeout error naming the resource", func() {
+				err := startController()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("timed out after 300ms waiting for the informer caches to sync: " +
+					controller.NodeResource))
+			})
+		})
+
+		Context("and the informer caches sync in time", func() {
+			It("should start successfully", func() {
+				Expect(startController()).To(Succeed())
+			})
+		})
+	})
+
+	When("an arbitrary resource type is watched via WatchResource", func() {
+		type resourceEvent struct {
+			gvk    schema.GroupVersionKind
+			action string
+			name   string
+		}
+
+		var (
+			gvk         schema.GroupVersionKind
+			ctl         *controller.Controller
+			widgets     dynamic.NamespaceableResourceInterface
+			events      chan resourceEvent
+			eventFilter func(eventType event.Type, obj runtime.Object) bool
+		)
+
+		newWidget := func(namespace, name string) *unstructured.Unstructured {
+			widget := &unstructured.Unstructured{}
+			widget.SetGroupVersionKind(gvk)
+			widget.SetNamespace(namespace)
+			widget.SetName(name)
+
+			return widget
+		}
+
+		BeforeEach(func() {
+			gvk = schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Widget"}
+			events = make(chan resourceEvent, 10)
+			eventFilter = nil
+		})
+
+		JustBeforeEach(func() {
+			// Created once the test driver has set up the controller's namespace.
+			restMapper := test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}).(*meta.DefaultRESTMapper)
+			restMapper.Add(gvk, meta.RESTScopeNamespace)
+
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, newWidget(testing.Namespace, "initial"))
+			widgets = client.Resource(gvk.GroupVersion().WithResource("widgets"))
+
+			registry, err := event.NewRegistry("widget-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			ctl, err = controller.New(&controller.Config{
+				RestMapper:  restMapper,
+				Client:      client,
+				Registry:    registry,
+				EventFilter: eventFilter,
+			})
+			Expect(err).To(Succeed())
+		})
+
+		It("should dispatch the resource's events to the subscribed handler", func() {
+			Expect(ctl.WatchResource(controller.ResourceSubscription{
+				GVK: gvk,
+				Handler: controller.ResourceEventHandlerFunc(
+					func(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error {
+						events <- resourceEvent{gvk: gvk, action: action, name: obj.GetName()}
+						return nil
+					}),
+			})).To(Succeed())
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			defer ctl.Stop()
+
+			Eventually(events).Should(Receive(Equal(resourceEvent{gvk: gvk, action: controller.CreateOperation, name: "initial"})))
+
+			By("Creating a resource in another namespace")
+
+			_, err := widgets.Namespace("other").Create(context.TODO(), newWidget("other", "ignored"), metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+			Consistently(events).ShouldNot(Receive())
+
+			By("Updating the resource")
+
+			widget := newWidget(testing.Namespace, "initial")
+			widget.SetLabels(map[string]string{"color": "blue"})
+			_, err = widgets.Namespace(testing.Namespace).Update(context.TODO(), widget, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+			Eventually(events).Should(Receive(Equal(resourceEvent{gvk: gvk, action: controller.UpdateOperation, name: "initial"})))
+
+			By("Deleting the resource")
+
+			Expect(widgets.Namespace(testing.Namespace).Delete(context.TODO(), "initial", metav1.DeleteOptions{})).To(Succeed())
+			Eventually(events).Should(Receive(Equal(resourceEvent{gvk: gvk, action: controller.DeleteOperation, name: "initial"})))
+		})
+
+		It("should retry the event if the handler returns an error", func() {
+			var attempts atomic.Int32
+
+			Expect(ctl.WatchResource(controller.ResourceSubscription{
+				GVK: gvk,
+				Handler: controller.ResourceEventHandlerFunc(
+					func(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error {
+						if attempts.Add(1) == 1 {
+							return errors.New("mock error")
+						}
+
+						events <- resourceEvent{gvk: gvk, action: action, name: obj.GetName()}
+
+						return nil
+					}),
+			})).To(Succeed())
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			defer ctl.Stop()
+
+			Eventually(events).Should(Receive(Equal(resourceEvent{gvk: gvk, action: controller.CreateOperation, name: "initial"})))
+			Expect(attempts.Load()).To(Equal(int32(2)))
+		})
+
+		Context("and an event filter is configured", func() {
+			BeforeEach(func() {
+				eventFilter = func(eventType event.Type, obj runtime.Object) bool {
+					return eventType != event.UnstructuredCreated || obj.(*unstructured.Unstructured).GetName() != "initial"
+				}
+			})
+
+			It("should not dispatch the events rejected by the filter", func() {
+				Expect(ctl.WatchResource(controller.ResourceSubscription{
+					GVK: gvk,
+					Handler: controller.ResourceEventHandlerFunc(
+						func(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error {
+							events <- resourceEvent{gvk: gvk, action: action, name: obj.GetName()}
+							return nil
+						}),
+				})).To(Succeed())
+
+				stopCh := make(chan struct{})
+				defer close(stopCh)
+
+				Expect(ctl.Start(stopCh)).To(Succeed())
+				defer ctl.Stop()
+
+				Consistently(events).ShouldNot(Receive())
+
+				By("Updating the resource")
+
+				widget := newWidget(testing.Namespace, "initial")
+				widget.SetLabels(map[string]string{"color": "blue"})
+				_, err := widgets.Namespace(testing.Namespace).Update(context.TODO(), widget, metav1.UpdateOptions{})
+				Expect(err).To(Succeed())
+				Eventually(events).Should(Receive(Equal(resourceEvent{gvk: gvk, action: controller.UpdateOperation, name: "initial"})))
+			})
+		})
+
+		It("should fail for an unknown resource type", func() {
+			Expect(ctl.WatchResource(controller.ResourceSubscription{
+				GVK:     schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Gadget"},
+				Handler: controller.ResourceEventHandlerFunc(nil),
+			})).ToNot(Succeed())
+		})
+
+		It("should fail for an already watched resource type", func() {
+			Expect(ctl.WatchResource(controller.ResourceSubscription{
+				GVK:     test.GetGroupVersionKindFor(&corev1.Node{}),
+				Handler: controller.ResourceEventHandlerFunc(nil),
+			})).ToNot(Succeed())
+		})
+	})
+
+	When("an arbitrary resource type is watched via WatchGVR", func() {
+		var (
+			gvr     schema.GroupVersionResource
+			ctl     *controller.Controller
+			widgets dynamic.NamespaceableResourceInterface
+			handler *unstructuredHandler
+		)
+
+		newWidget := func(namespace, name string) *unstructured.Unstructured {
+			widget := &unstructured.Unstructured{}
+			widget.SetGroupVersionKind(gvr.GroupVersion().WithKind("Widget"))
+			widget.SetNamespace(namespace)
+			widget.SetName(name)
+
+			return widget
+		}
+
+		BeforeEach(func() {
+			gvr = schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "widgets"}
+			handler = &unstructuredHandler{events: make(chan unstructuredEvent, 10)}
+		})
+
+		JustBeforeEach(func() {
+			restMapper := test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}).(*meta.DefaultRESTMapper)
+			restMapper.Add(gvr.GroupVersion().WithKind("Widget"), meta.RESTScopeNamespace)
+
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, newWidget("widget-ns", "initial"))
+			widgets = client.Resource(gvr)
+
+			registry, err := event.NewRegistry("widget-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			ctl, err = controller.New(&controller.Config{
+				RestMapper: restMapper,
+				Client:     client,
+				Registry:   registry,
+			})
+			Expect(err).To(Succeed())
+		})
+
+		It("should dispatch the resource's events in the given namespace to the handlers", func() {
+			Expect(ctl.WatchGVR(gvr, "widget-ns")).To(Succeed())
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			defer ctl.Stop()
+
+			Eventually(handler.events).Should(Receive(Equal(unstructuredEvent{gvr: gvr, eventType: event.UnstructuredCreated, name: "initial"})))
+
+			By("Creating a resource in another namespace")
+
+			_, err := widgets.Namespace("other").Create(context.TODO(), newWidget("other", "ignored"), metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+			Consistently(handler.events).ShouldNot(Receive())
+
+			By("Updating the resource")
+
+			widget := newWidget("widget-ns", "initial")
+			widget.SetLabels(map[string]string{"color": "blue"})
+			_, err = widgets.Namespace("widget-ns").Update(context.TODO(), widget, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+			Eventually(handler.events).Should(Receive(Equal(unstructuredEvent{gvr: gvr, eventType: event.UnstructuredUpdated, name: "initial"})))
+
+			By("Deleting the resource")
+
+			Expect(widgets.Namespace("widget-ns").Delete(context.TODO(), "initial", metav1.DeleteOptions{})).To(Succeed())
+			Eventually(handler.events).Should(Receive(Equal(unstructuredEvent{gvr: gvr, eventType: event.UnstructuredRemoved, name: "initial"})))
+		})
+
+		It("should dispatch the resource's events in all namespaces if none is given", func() {
+			Expect(ctl.WatchGVR(gvr, "")).To(Succeed())
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			defer ctl.Stop()
+
+			Eventually(handler.events).Should(Receive(Equal(unstructuredEvent{gvr: gvr, eventType: event.UnstructuredCreated, name: "initial"})))
+
+			_, err := widgets.Namespace("other").Create(context.TODO(), newWidget("other", "another"), metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+			Eventually(handler.events).Should(Receive(Equal(unstructuredEvent{gvr: gvr, eventType: event.UnstructuredCreated, name: "another"})))
+		})
+
+		It("should fail for an unknown resource type", func() {
+			Expect(ctl.WatchGVR(schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "gadgets"}, "")).ToNot(Succeed())
+		})
+
+		It("should fail for an already watched resource type", func() {
+			Expect(ctl.WatchGVR(corev1.SchemeGroupVersion.WithResource("nodes"), "")).ToNot(Succeed())
+		})
+	})
+
+	When("the controller is started and stopped", func() {
+		It("should transition through the lifecycle states", func() {
+			var stateOnPreStart controller.LifecycleState
+
+			handler := &preStartHandler{TestHandler: testing.NewTestHandler("lifecycle-handler", event.AnyNetworkPlugin,
+				make(chan testing.TestEvent, 10))}
+
+			registry, err := event.NewRegistry("lifecycle-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper: test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:     dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:   registry,
+			})
+			Expect(err).To(Succeed())
+
+			handler.onPreStart = func() {
+				stateOnPreStart = ctl.State()
+			}
+
+			Expect(ctl.State()).To(Equal(controller.LifecycleNew))
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			Expect(stateOnPreStart).To(Equal(controller.LifecycleStarting))
+			Expect(ctl.State()).To(Equal(controller.LifecycleRunning))
+
+			ctl.Stop()
+			Expect(ctl.State()).To(Equal(controller.LifecycleStopped))
+
+			ctl.Stop()
+			Expect(ctl.State()).To(Equal(controller.LifecycleStopped))
+			Expect(ctl.Start(stopCh)).ToNot(Succeed())
+		})
+
+		It("should notify the controller lifecycle handlers around Start and Stop", func() {
+			events := make(chan testing.TestEvent, 10)
+			handler := &lifecycleHandler{TestHandler: testing.NewTestHandler("lifecycle-handler", event.AnyNetworkPlugin, events)}
+
+			registry, err := event.NewRegistry("lifecycle-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper: test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:     dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:   registry,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			Consistently(events).ShouldNot(Receive())
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: evControllerStarted})))
+			Expect(events).ToNot(Receive())
+
+			By("Adding a handler after the controller started")
+
+			other := &lifecycleHandler{TestHandler: testing.NewTestHandler("other-handler", event.AnyNetworkPlugin, events)}
+			Expect(ctl.AddHandler(other)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: evControllerStarted})))
+
+			ctl.Stop()
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: evControllerStopping})))
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: evControllerStopping})))
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: handler.Name, Name: testing.EvStop})))
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: testing.EvStop})))
+		})
+
+		It("should be stopped if it fails to start", func() {
+			handler := &preStartHandler{
+				TestHandler: testing.NewTestHandler("lifecycle-handler", event.AnyNetworkPlugin, make(chan testing.TestEvent, 10)),
+				err:         errors.New("mock PreStart error"),
+			}
+
+			registry, err := event.NewRegistry("lifecycle-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper:             test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:                 dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:               registry,
+				FailOnHandlerInitError: true,
+			})
+			Expect(err).To(Succeed())
+
+			Expect(ctl.Start(make(chan struct{}))).ToNot(Succeed())
+			Expect(ctl.State()).To(Equal(controller.LifecycleStopped))
+		})
+	})
+
+	When("neither a Client nor a RestConfig is specified", func() {
+		It("New should return a descriptive error", func() {
+			registry, err := event.NewRegistry("invalid-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			_, err = controller.New(&controller.Config{Registry: registry})
+			Expect(err).To(MatchError(ContainSubstring("either a Client or a RestConfig must be specified")))
+
+			_, err = controller.New(&controller.Config{
+				Registry: registry,
+				Clusters: []controller.ClusterConfig{{Name: "east"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring(`for cluster "east"`)))
+
+			_, err = controller.New(&controller.Config{
+				Registry: registry,
+				Client:   dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+			})
+			Expect(err).To(MatchError(ContainSubstring("either a RestMapper or a RestConfig must be specified")))
+		})
+	})
+
+	When("an unsupported preferred Node address type is specified", func() {
+		It("New should return an error", func() {
+			registry, err := event.NewRegistry("invalid-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			_, err = controller.New(&controller.Config{
+				Registry:                 registry,
+				Client:                   dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				PreferredNodeAddressType: corev1.NodeInternalDNS,
+			})
+			Expect(err).To(MatchError(ContainSubstring("unsupported PreferredNodeAddressType")))
+		})
+	})
+
+	When("the controller is started asynchronously", func() {
+		It("should return immediately and process events in the background", func() {
+			client := &testClient{
+				Interface:       dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				blockedResource: "nodes",
+				unblock:         make(chan struct{}),
+			}
+
+			registry, err := event.NewRegistry("async-registry", event.AnyNetworkPlugin, t.handler)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper: test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:     client,
+				Registry:   registry,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+
+			handle, err := ctl.StartAsync(stopCh)
+			Expect(err).To(Succeed())
+			Expect(ctl.SyncStatus()).To(HaveKeyWithValue(controller.NodeResource, false))
+			Expect(handle.Err()).To(Succeed())
+
+			close(client.unblock)
+			Eventually(ctl.SyncStatus).Should(HaveKeyWithValue(controller.NodeResource, true))
+
+			close(stopCh)
+			Expect(handle.Wait()).To(Succeed())
+			ctl.Stop()
+		})
+
+		It("should surface a start failure via the handle", func() {
+			handle, err := t.Controller.StartAsync(make(chan struct{}))
+			Expect(err).To(Succeed())
+
+			Expect(handle.Wait()).To(MatchError(ContainSubstring("already been started")))
+			Expect(handle.Err()).To(HaveOccurred())
+		})
+
+		It("should fail if no stop channel is provided", func() {
+			_, err := t.Controller.StartAsync(nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("events are recorded and replayed into another controller", func() {
+		var (
+			auditLog *syncBuffer
+			codec    controller.EventCodec
+			compress bool
+		)
+
+		BeforeEach(func() {
+			auditLog = &syncBuffer{}
+			codec = nil
+			compress = false
+
+			t.Configure = func(config *controller.Config) {
+				config.AuditWriter = auditLog
+				config.AuditCodec = codec
+				config.AuditCompression = compress
+			}
+		})
+
+		testRecordAndReplay := func() {
+			var recorded []testing.TestEvent
+
+			record := func(name string, param interface{}) {
+				t.awaitEvent(name, param)
+				recorded = append(recorded, testing.TestEvent{Handler: testHandlerName, Name: name, Parameter: param})
+			}
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			record(testing.EvRemoteEndpointCreated, endpoint.DeepCopy())
+
+			endpoint.Spec.Hostname = "other-host"
+			t.UpdateEndpoint(endpoint)
+			record(testing.EvRemoteEndpointUpdated, endpoint.DeepCopy())
+
+			node := t.CreateNode(testing.NewNode("node1"))
+			record(testing.EvNodeCreated, node)
+
+			t.DeleteEndpoint(endpoint.Name)
+			record(testing.EvRemoteEndpointRemoved, endpoint)
+
+			replayEvents := make(chan testing.TestEvent, 100)
+
+			registry, err := event.NewRegistry("replay-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler(testHandlerName, event.AnyNetworkPlugin, replayEvents))
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper: test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:     dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:   registry,
+			})
+			Expect(err).To(Succeed())
+
+			source := controller.NewReplaySource(strings.NewReader(auditLog.String()))
+			if codec != nil {
+				source = controller.NewReplaySourceWithCodec(strings.NewReader(auditLog.String()), codec)
+			}
+
+			Expect(ctl.Replay(source)).To(Succeed())
+
+			for i := range recorded {
+				Expect(replayEvents).To(Receive(Equal(recorded[i])))
+			}
+
+			Consistently(replayEvents).ShouldNot(Receive())
+		}
+
+		Context("with the default codec", func() {
+			It("should result in identical handler invocations", func() {
+				testRecordAndReplay()
+			})
+		})
+
+		Context("with a custom codec", func() {
+			var custom *lengthPrefixedCodec
+
+			BeforeEach(func() {
+				custom = &lengthPrefixedCodec{}
+				codec = custom
+			})
+
+			It("should result in identical handler invocations", func() {
+				testRecordAndReplay()
+				Expect(custom.encoded.Load()).To(BeNumerically(">", 0))
+				Expect(custom.decoded.Load()).To(Equal(custom.encoded.Load()))
+			})
+		})
+
+		Context("with compression", func() {
+			BeforeEach(func() {
+				compress = true
+			})
+
+			It("should record a gzip compressed stream that's replayable while recording", func() {
+				testRecordAndReplay()
+
+				t.Controller.Stop()
+
+				reader, err := gzip.NewReader(strings.NewReader(auditLog.String()))
+				Expect(err).To(Succeed())
+
+				var recorded []string
+
+				decoder := bufio.NewReader(reader)
+
+				for {
+					record, err := controller.JSONCodec{}.Decode(decoder)
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					Expect(err).To(Succeed())
+					Expect(record.Object).ToNot(BeNil())
+
+					recorded = append(recorded, record.Resource+"/"+record.Operation)
+				}
+
+				Expect(recorded).To(Equal([]string{
+					controller.EndpointResource + "/" + controller.CreateOperation,
+					controller.EndpointResource + "/" + controller.UpdateOperation,
+					controller.NodeResource + "/" + controller.CreateOperation,
+					controller.EndpointResource + "/" + controller.DeleteOperation,
+				}))
+			})
+		})
+	})
+
+	When("a source event results in notifications to multiple handlers", func() {
+		var correlationIDs chan correlatedEvent
+
+		BeforeEach(func() {
+			correlationIDs = make(chan correlatedEvent, 100)
+
+			t.Configure = func(config *controller.Config) {
+				for _, name := range []string{"handler1", "handler2"} {
+					_, err := config.Registry.AddHandler(&correlationHandler{name: name, events: correlationIDs})
+					Expect(err).To(Succeed())
+				}
+			}
+		})
+
+		receive := func(name string) string {
+			var e correlatedEvent
+
+			Eventually(correlationIDs).Should(Receive(&e))
+			Expect(e.name).To(Equal(name))
+			Expect(e.correlationID).ToNot(BeEmpty())
+
+			return e.correlationID
+		}
+
+		It("should share the same correlation ID across the handlers invoked for the event", func() {
+			t.CreateLocalHostEndpoint()
+
+			id := receive("handler1/" + testing.EvLocalEndpointCreated)
+			Expect(receive("handler2/" + testing.EvLocalEndpointCreated)).To(Equal(id))
+			Expect(receive("handler1/" + testing.EvTransitionToGateway)).To(Equal(id))
+			Expect(receive("handler2/" + testing.EvTransitionToGateway)).To(Equal(id))
+
+			t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+
+			nextID := receive("handler1/" + testing.EvRemoteEndpointCreated)
+			Expect(nextID).ToNot(Equal(id))
+			Expect(receive("handler2/" + testing.EvRemoteEndpointCreated)).To(Equal(nextID))
+
+			Expect(t.handler.State().GetCorrelationID()).To(BeEmpty())
+		})
+	})
+
+	When("the watch connection for a resource drops and reconnects", func() {
+		var client *testClient
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				client = &testClient{Interface: config.Client}
+				config.Client = client
+			}
+		})
+
+		It("should notify the handler of the reconnection", func() {
+			client.failWatches("endpoints")
+			Eventually(t.testEvents, 5*time.Second).Should(Receive(Equal(testing.TestEvent{
+				Handler: testHandlerName, Name: testing.EvWatchReconnected, Parameter: controller.EndpointResource,
+			})))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			t.ensureNoEvents()
+		})
+
+		It("should not notify the handler if the watch is routinely restarted", func() {
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+			// The watch received an event so it's restarted from the last resource version without a relist, as on a
+			// server-side timeout.
+			client.dropWatches("endpoints")
+			Eventually(func() int {
+				return client.activeWatches("endpoints")
+			}, 5*time.Second).Should(Equal(1))
+			t.ensureNoEvents()
+
+			endpoint = t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+	})
+
+	When("the preferred gateway Endpoint of a remote cluster fails over", func() {
+		It("should notify the preferred gateway changes", func() {
+			const clusterID = "remote-cluster1"
+
+			preferred := testing.NewEndpoint(clusterID, "host1")
+			preferred.Annotations = map[string]string{controller.PreferredGatewayAnnotation: "true"}
+			preferred = t.CreateEndpoint(preferred)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, preferred)
+			t.awaitEvent(testing.EvPreferredGatewayChanged, testing.PreferredGatewayChange{ClusterID: clusterID, New: preferred})
+
+			standby := t.CreateEndpoint(testing.NewEndpoint(clusterID, "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, standby)
+			t.ensureNoEvents()
+
+			By("Marking the standby Endpoint as preferred")
+
+			standby.Annotations = map[string]string{controller.PreferredGatewayAnnotation: "true"}
+			t.UpdateEndpoint(standby)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, standby)
+			t.awaitEvent(testing.EvPreferredGatewayChanged, testing.PreferredGatewayChange{
+				ClusterID: clusterID,
+				Old:       preferred,
+				New:       standby,
+			})
+
+			By("Unmarking the previously preferred Endpoint")
+
+			previous := preferred.DeepCopy()
+			previous.Annotations = nil
+			t.UpdateEndpoint(previous)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, previous)
+			t.ensureNoEvents()
+
+			By("Deleting the preferred Endpoint")
+
+			t.DeleteEndpoint(standby.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, standby)
+			t.awaitEvent(testing.EvPreferredGatewayChanged, testing.PreferredGatewayChange{ClusterID: clusterID, Old: standby})
+		})
+	})
+
+	When("the controller is started from a cache snapshot that diverges from the live state", func() {
+		var (
+			client       dynamic.Interface
+			snapshotPath string
+		)
+
+		BeforeEach(func() {
+			snapshotPath = filepath.Join(GinkgoT().TempDir(), "cache-snapshot.json")
+
+			t.Configure = func(config *controller.Config) {
+				client = config.Client
+				config.CacheSnapshotPath = snapshotPath
+			}
+		})
+
+		It("should dispatch the snapshot and reconcile the differences", func() {
+			unchangedNode := t.CreateNode(testing.NewNode("unchanged-node"))
+			t.awaitEvent(testing.EvNodeCreated, unchangedNode)
+
+			changedNode := t.CreateNode(testing.NewNode("changed-node"))
+			t.awaitEvent(testing.EvNodeCreated, changedNode)
+
+			unchangedEndpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, unchangedEndpoint)
+
+			deletedEndpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, deletedEndpoint)
+
+			Expect(t.Controller.SaveCacheSnapshot()).To(Succeed())
+
+			changedNode.Labels = map[string]string{"label": "changed"}
+			t.UpdateNode(changedNode)
+			t.awaitEvent(testing.EvNodeUpdated, changedNode)
+
+			t.DeleteEndpoint(deletedEndpoint.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, deletedEndpoint)
+
+			createdNode := t.CreateNode(testing.NewNode("created-node"))
+			t.awaitEvent(testing.EvNodeCreated, createdNode)
+
+			events := make(chan testing.TestEvent, 20)
+
+			registry, err := event.NewRegistry("warm-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler("warm-handler", event.AnyNetworkPlugin, events))
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper:        test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:            client,
+				Registry:          registry,
+				CacheSnapshotPath: snapshotPath,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+				ctl.Stop()
+			})
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+
+			var received []string
+
+			Eventually(func() []string {
+				select {
+				case e := <-events:
+					received = append(received, e.Name+"/"+e.Parameter.(metav1.Object).GetName())
+				default:
+				}
+
+				return received
+			}).Should(ConsistOf(
+				testing.EvNodeCreated+"/"+unchangedNode.Name,
+				testing.EvNodeCreated+"/"+changedNode.Name,
+				testing.EvRemoteEndpointCreated+"/"+unchangedEndpoint.Name,
+				testing.EvRemoteEndpointCreated+"/"+deletedEndpoint.Name,
+				testing.EvNodeUpdated+"/"+changedNode.Name,
+				testing.EvRemoteEndpointRemoved+"/"+deletedEndpoint.Name,
+				testing.EvNodeCreated+"/"+createdNode.Name,
+			))
+			Consistently(events).ShouldNot(Receive())
+		})
+
+		It("should notify the live object if the notification from the snapshot failed", func() {
+			node := t.CreateNode(testing.NewNode("node1"))
+			t.awaitEvent(testing.EvNodeCreated, node)
+
+			Expect(t.Controller.SaveCacheSnapshot()).To(Succeed())
+
+			events := make(chan testing.TestEvent, 20)
+			failing := &failingNodeHandler{}
+			failing.fail.Store(true)
+
+			registry, err := event.NewRegistry("warm-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler("warm-handler", event.AnyNetworkPlugin, events), failing)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper:        test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:            client,
+				Registry:          registry,
+				CacheSnapshotPath: snapshotPath,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+				ctl.Stop()
+			})
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+
+			Eventually(events).Should(Receive(HaveField("Name", testing.EvNodeCreated)))
+			failing.fail.Store(false)
+
+			Eventually(events, 5*time.Second).Should(Receive(HaveField("Name", testing.EvNodeCreated)))
+		})
+	})
+
+	When("the controller is restarted with a snapshot of the remote Endpoints", func() {
+		var client dynamic.Interface
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				client = config.Client
+			}
+		})
+
+		It("should only notify the changes since the snapshot", func() {
+			unchanged := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host1"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, unchanged)
+
+			deleted := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host2"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, deleted)
+
+			changed := t.CreateEndpoint(testing.NewEndpoint("remote-cluster3", "host3"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, changed)
+
+			snapshot := t.Controller.Snapshot()
+
+			var restored []submV1.Endpoint
+			Expect(json.Unmarshal(snapshot, &restored)).To(Succeed())
+			Expect(restored).To(HaveLen(3))
+
+			t.DeleteEndpoint(deleted.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, deleted)
+
+			changed.Spec.Hostname = "host3-updated"
+			t.UpdateEndpoint(changed)
+			t.awaitEvent(testing.EvRemoteEndpointUpdated, changed)
+
+			created := t.CreateEndpoint(testing.NewEndpoint("remote-cluster4", "host4"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, created)
+
+			events := make(chan testing.TestEvent, 10)
+			handler := testing.NewTestHandler("restarted-handler", event.AnyNetworkPlugin, events)
+
+			registry, err := event.NewRegistry("restarted-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			ctl, err := controller.New(&controller.Config{
+				RestMapper:       test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:           client,
+				Registry:         registry,
+				InitialEndpoints: snapshot,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+				ctl.Stop()
+			})
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+
+			var received []string
+
+			Eventually(func() []string {
+				select {
+				case e := <-events:
+					received = append(received, e.Name+"/"+e.Parameter.(*submV1.Endpoint).Name)
+				default:
+				}
+
+				return received
+			}).Should(ConsistOf(testing.EvRemoteEndpointRemoved+"/"+deleted.Name,
+				testing.EvRemoteEndpointUpdated+"/"+changed.Name, testing.EvRemoteEndpointCreated+"/"+created.Name))
+			Consistently(events).ShouldNot(Receive())
+
+			Expect(json.Unmarshal(ctl.Snapshot(), &restored)).To(Succeed())
+			Expect(restored).To(HaveLen(3))
+		})
+
+		It("should match the restored Endpoints by identity", func() {
+			original := testing.NewEndpoint("remote-cluster1", "host1")
+			original.Spec.CableName = "cable1"
+			t.CreateEndpoint(original)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, original)
+
+			snapshot := t.Controller.Snapshot()
+
+			t.DeleteEndpoint(original.Name)
+			t.awaitEvent(testing.EvRemoteEndpointRemoved, original)
+
+			replacement := testing.NewEndpoint("remote-cluster1", "host1")
+			replacement.Spec = original.Spec
+			t.CreateEndpoint(replacement)
+			t.awaitEvent(testing.EvRemoteEndpointCreated, replacement)
+
+			events := make(chan testing.TestEvent, 10)
+			handler := testing.NewTestHandler("restarted-handler", event.AnyNetworkPlugin, events)
+
+			registry, err := event.NewRegistry("restarted-registry", event.AnyNetworkPlugin, handler)
+			Expect(err).To(Succeed())
+
+			// Hold back the Endpoint events until the restore is completed.
+			ctl, err := controller.New(&controller.Config{
+				RestMapper:                   test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}),
+				Client:                       client,
+				Registry:                     registry,
+				InitialEndpoints:             snapshot,
+				SuppressEventsWhileNotLeader: true,
+			})
+			Expect(err).To(Succeed())
+
+			stopCh := make(chan struct{})
+			DeferCleanup(func() {
+				close(stopCh)
+				ctl.Stop()
+			})
+
+			Expect(ctl.Start(stopCh)).To(Succeed())
+			Consistently(events).ShouldNot(Receive())
+
+			Expect(ctl.SetLeader(true)).To(Succeed())
+
+			var e testing.TestEvent
+			Eventually(events).Should(Receive(&e))
+			Expect(e.Name).To(Equal(testing.EvRemoteEndpointUpdated))
+			Expect(e.Parameter.(*submV1.Endpoint).Name).To(Equal(replacement.Name))
+			Consistently(events).ShouldNot(Receive())
+		})
+	})
+
+	When("both InitialEndpoints and BulkInitialEndpoints are specified", func() {
+		It("New should return an error", func() {
+			registry, err := event.NewRegistry("invalid-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			_, err = controller.New(&controller.Config{
+				Registry:             registry,
+				Client:               dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				BulkInitialEndpoints: true,
+				InitialEndpoints:     []byte("[]"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("can't be combined")))
+		})
+	})
+
+	When("bulk initial Endpoints is configured and Endpoints pre-exist", func() {
+		var preExisting []*submV1.Endpoint
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.BulkInitialEndpoints = true
+
+				preExisting = []*submV1.Endpoint{
+					t.CreateLocalHostEndpoint(),
+					t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host")),
+					t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host")),
+				}
+			}
+		})
+
+		It("should notify them in a single bulk call and no per-object creates", func() {
+			var e testing.TestEvent
+
+			Eventually(t.testEvents).Should(Receive(&e))
+			Expect(e.Name).To(Equal(testing.EvInitialEndpoints))
+			Expect(e.Parameter).To(ConsistOf(*preExisting[0], *preExisting[1], *preExisting[2]))
+
+			t.awaitEvent(testing.EvTransitionToGateway, nil)
+			t.ensureNoEvents()
+
+			Expect(t.handler.State().IsOnGateway()).To(BeTrue())
+			Expect(t.handler.State().GetRemoteEndpoints()).To(HaveLen(2))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster3", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+
+		Context("and the handler fails the bulk notification", func() {
+			BeforeEach(func() {
+				t.handler.FailOnEvent(testing.EvInitialEndpoints)
+			})
+
+			It("should retry it rather than notify the Endpoints individually", func() {
+				var e testing.TestEvent
+
+				Eventually(t.testEvents).Should(Receive(&e))
+				Expect(e.Name).To(Equal(testing.EvInitialEndpoints))
+				Expect(e.Parameter).To(ConsistOf(*preExisting[0], *preExisting[1], *preExisting[2]))
+
+				t.awaitEvent(testing.EvTransitionToGateway, nil)
+				t.ensureNoEvents()
+			})
+		})
+
+		Context("and a remote Endpoint batch key is configured", func() {
+			var batches chan endpointBatch
+
+			BeforeEach(func() {
+				batches = make(chan endpointBatch, 10)
+				configure := t.Configure
+
+				t.Configure = func(config *controller.Config) {
+					configure(config)
+
+					config.RemoteEndpointBatchKey = controller.ClusterIDBatchKey
+					config.RemoteEndpointBatchWindow = 10 * time.Millisecond
+
+					_, err := config.Registry.AddHandler(&batchHandler{batches: batches})
+					Expect(err).To(Succeed())
+				}
+			})
+
+			It("should notify the batch handler of the remote Endpoints' creation in batches", func() {
+				var received []endpointBatch
+
+				for i := 0; i < 2; i++ {
+					var batch endpointBatch
+
+					Eventually(batches).Should(Receive(&batch))
+					received = append(received, batch)
+				}
+
+				Expect(received).To(ConsistOf(
+					endpointBatch{key: "remote-cluster1", events: []event.EndpointEvent{
+						{Type: event.RemoteEndpointCreated, Endpoint: preExisting[1]},
+					}},
+					endpointBatch{key: "remote-cluster2", events: []event.EndpointEvent{
+						{Type: event.RemoteEndpointCreated, Endpoint: preExisting[2]},
+					}}))
+			})
+		})
+	})
+
+	When("multiple clusters are configured", func() {
+		var otherClusterEndpoints dynamic.ResourceInterface
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				otherClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+				otherClusterEndpoints = otherClient.Resource(*test.GetGroupVersionResourceFor(config.RestMapper,
+					&submV1.Endpoint{})).Namespace(testing.Namespace)
+
+				config.Clusters = []controller.ClusterConfig{
+					{Name: "cluster-a", RestMapper: config.RestMapper, Client: config.Client},
+					{Name: "cluster-b", RestMapper: config.RestMapper, Client: otherClient},
+				}
+			}
+		})
+
+		It("should annotate the notified objects with their origin cluster", func() {
+			endpointA := testing.NewEndpoint("remote-cluster1", "host")
+			endpointA.Labels = map[string]string{federate.ClusterIDLabelKey: "remote-cluster1"}
+			endpointA = t.CreateEndpoint(endpointA)
+			endpointA.Annotations = map[string]string{controller.OriginClusterAnnotation: "cluster-a"}
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpointA)
+			Expect(controller.OriginCluster(endpointA)).To(Equal("cluster-a"))
+
+			endpointB := testing.NewEndpoint("remote-cluster2", "host")
+			Expect(scheme.Scheme.Convert(test.CreateResource(otherClusterEndpoints, endpointB), endpointB, nil)).To(Succeed())
+			endpointB.Annotations = map[string]string{controller.OriginClusterAnnotation: "cluster-b"}
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpointB)
+
+			Expect(t.Controller.SyncStatus()).To(HaveKeyWithValue("cluster-b/"+controller.EndpointResource, true))
+		})
+	})
+
+	When("a maximum object size is configured", func() {
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.MaxObjectBytes = 1000
+			}
+		})
+
+		It("should not notify the handler of oversized objects", func() {
+			oversized := testing.NewEndpoint("remote-cluster1", "host")
+			oversized.Annotations = map[string]string{"blob": strings.Repeat("x", 2000)}
+			t.CreateEndpoint(oversized)
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster2", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+			t.ensureNoEvents()
+		})
+	})
+
+	When("a logger is configured", func() {
+		var (
+			mutex sync.Mutex
+			lines []string
+		)
+
+		BeforeEach(func() {
+			lines = nil
+
+			t.Configure = func(config *controller.Config) {
+				config.Logger = log.Logger{Logger: funcr.New(func(prefix, args string) {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					lines = append(lines, prefix+" "+args)
+				}, funcr.Options{Verbosity: log.DEBUG})}
+			}
+		})
+
+		It("should log via the instance logger", func() {
+			t.Controller.SetHandlerEnabled("unknown", false)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			Expect(lines).To(ContainElement(ContainSubstring("Event controller started")))
+			Expect(lines).To(ContainElement(ContainSubstring(`Event handler \"unknown\" not found`)))
+		})
+	})
+
+	When("watching ClusterGlobalEgressIPs is configured", func() {
+		var (
+			egressIPClient dynamic.NamespaceableResourceInterface
+			egressIPs      dynamic.ResourceInterface
+		)
+
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.WatchClusterGlobalEgressIPs = true
+				config.RestMapper = test.GetRESTMapperFor(&corev1.Node{}, &submV1.Endpoint{}, &submV1.ClusterGlobalEgressIP{})
+				egressIPClient = config.Client.Resource(*test.GetGroupVersionResourceFor(config.RestMapper,
+					&submV1.ClusterGlobalEgressIP{}))
+				egressIPs = egressIPClient.Namespace(testing.Namespace)
+			}
+		})
+
+		It("should notify the handler of each ClusterGlobalEgressIP event", func() {
+			egressIP := &submV1.ClusterGlobalEgressIP{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-egress.submariner.io", Namespace: testing.Namespace},
+				Spec:       submV1.ClusterGlobalEgressIPSpec{NumberOfIPs: ptr.To(1)},
+			}
+
+			Expect(scheme.Scheme.Convert(test.CreateResource(egressIPs, egressIP), egressIP, nil)).To(Succeed())
+			t.awaitEvent(testing.EvClusterGlobalEgressIPCreated, egressIP)
+
+			egressIP.Spec.NumberOfIPs = ptr.To(2)
+			Expect(scheme.Scheme.Convert(test.UpdateResource(egressIPs, egressIP), egressIP, nil)).To(Succeed())
+			t.awaitEvent(testing.EvClusterGlobalEgressIPUpdated, egressIP)
+
+			Expect(egressIPs.Delete(context.TODO(), egressIP.Name, metav1.DeleteOptions{})).To(Succeed())
+			t.awaitEvent(testing.EvClusterGlobalEgressIPRemoved, egressIP)
+
+			Expect(t.Controller.SyncStatus()).To(HaveKeyWithValue(controller.ClusterGlobalEgressIPResource, true))
+		})
+
+		It("should watch ClusterGlobalEgressIPs across all namespaces", func() {
+			egressIP := &submV1.ClusterGlobalEgressIP{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-egress.submariner.io", Namespace: "other-namespace"},
+				Spec:       submV1.ClusterGlobalEgressIPSpec{NumberOfIPs: ptr.To(1)},
+			}
+
+			Expect(scheme.Scheme.Convert(test.CreateResource(egressIPClient.Namespace(egressIP.Namespace), egressIP), egressIP,
+				nil)).To(Succeed())
+			t.awaitEvent(testing.EvClusterGlobalEgressIPCreated, egressIP)
+		})
+	})
+
+	When("watching ClusterGlobalEgressIPs is configured and the CRD isn't installed", func() {
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.WatchClusterGlobalEgressIPs = true
+			}
+		})
+
+		It("should start without watching ClusterGlobalEgressIPs", func() {
+			Expect(t.Controller.SyncStatus()).ToNot(HaveKey(controller.ClusterGlobalEgressIPResource))
+
+			endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+		})
+	})
+
+	When("the RESTMapper can't resolve a required resource type", func() {
+		It("should fail to create the controller", func() {
+			registry, err := event.NewRegistry("required-registry", event.AnyNetworkPlugin)
+			Expect(err).To(Succeed())
+
+			_, err = controller.New(&controller.Config{
+				RestMapper:                  test.GetRESTMapperFor(&corev1.Node{}),
+				Client:                      dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Registry:                    registry,
+				WatchClusterGlobalEgressIPs: true,
+			})
+			Expect(err).To(MatchError(ContainSubstring("error creating the %s watcher", controller.EndpointResource)))
+		})
+	})
+
+	When("event tracing is enabled or disabled", func() {
+		var (
+			mutex sync.Mutex
+			lines []string
+		)
+
+		traceLines := func() []string {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			var traced []string
+
+			for _, l := range lines {
+				if strings.Contains(l, "Trace: ") {
+					traced = append(traced, l)
+				}
+			}
+
+			return traced
+		}
+
+		BeforeEach(func() {
+			lines = nil
+
+			t.Configure = func(config *controller.Config) {
+				config.Logger = log.Logger{Logger: funcr.New(func(prefix, args string) {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					lines = append(lines, prefix+" "+args)
+				}, funcr.Options{Verbosity: log.DEBUG})}
+			}
+		})
+
+		Context("via the SUBMARINER_EVENTTRACE env var", func() {
+			BeforeEach(func() {
+				os.Setenv("SUBMARINER_EVENTTRACE", "true")
+				DeferCleanup(os.Unsetenv, "SUBMARINER_EVENTTRACE")
+			})
+
+			It("should trace the lifecycle of each event", func() {
+				endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+				Eventually(traceLines).Should(ContainElements(
+					ContainSubstring(`Event \"RemoteEndpointCreated\" received`),
+					ContainSubstring(`Event \"RemoteEndpointCreated\" dispatched to handler \"test-handler\"`),
+					ContainSubstring(`Event \"RemoteEndpointCreated\" handled by handler \"test-handler\"`),
+					ContainSubstring("requeue: false")))
+
+				for _, l := range traceLines() {
+					if strings.Contains(l, "RemoteEndpointCreated") || strings.Contains(l, "requeue:") {
+						Expect(l).To(ContainSubstring(`"correlationID"=`))
+					}
+				}
+			})
+		})
 
-	When("remote Endpoints are created, updated and deleted", func() {
-		It("should correctly notify the handler", func() {
-			t.testRemoteEndpoints()
+		Context("by default", func() {
+			It("should not trace events", func() {
+				endpoint := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+				t.awaitEvent(testing.EvRemoteEndpointCreated, endpoint)
+
+				Consistently(traceLines, 300*time.Millisecond).Should(BeEmpty())
+			})
 		})
 	})
 
-	When("the handler returns an error from an event notification", func() {
-		It("should retry the event", func() {
-			t.handler.FailOnEvent(testing.EvLocalEndpointCreated, testing.EvLocalEndpointUpdated, testing.EvLocalEndpointRemoved,
-				testing.EvTransitionToGateway, testing.EvTransitionToNonGateway, testing.EvRemoteEndpointCreated,
-				testing.EvRemoteEndpointUpdated, testing.EvRemoteEndpointRemoved)
+	When("an event filter is configured", func() {
+		const deniedClusterID = "denied-cluster"
 
-			t.testLocalEndpoint()
-			t.testRemoteEndpoints()
+		BeforeEach(func() {
+			t.Configure = func(config *controller.Config) {
+				config.EventFilter = func(eventType event.Type, obj runtime.Object) bool {
+					endpoint, ok := obj.(*submV1.Endpoint)
+					return !ok || eventType != event.RemoteEndpointCreated || endpoint.Spec.ClusterID != deniedClusterID
+				}
+			}
+		})
+
+		It("should not notify the handler of filtered events", func() {
+			t.CreateEndpoint(testing.NewEndpoint(deniedClusterID, "host"))
+
+			allowed := t.CreateEndpoint(testing.NewEndpoint("remote-cluster1", "host"))
+			t.awaitEvent(testing.EvRemoteEndpointCreated, allowed)
+			t.ensureNoEvents()
 		})
 	})
 })
@@ -89,6 +4354,7 @@ func newTestDriver() *testDriver {
 	}
 
 	BeforeEach(func() {
+		t.Configure = nil
 		t.testEvents = make(chan testing.TestEvent, 1000)
 		t.handler = &TestHandler{
 			TestHandler: &testing.TestHandler{
@@ -218,3 +4484,572 @@ func (t *TestHandler) storeRemoteEndpoints() {
 
 	t.remoteEndpoints.Store(eps)
 }
+
+type testClient struct {
+	dynamic.Interface
+	mutex  sync.Mutex
+	limits []int64
+
+	// blockedResource, if set, is the resource whose list requests block until unblock is closed.
+	blockedResource string
+	unblock         chan struct{}
+
+	watches map[string][]watch.Interface
+
+	// failWatchOf, if set, is the resource whose next watch request fails.
+	failWatchOf string
+}
+
+type testResource struct {
+	dynamic.ResourceInterface
+	client   *testClient
+	resource string
+}
+
+type testNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	client   *testClient
+	resource string
+}
+
+func (c *testClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &testNamespaceableResource{
+		NamespaceableResourceInterface: c.Interface.Resource(resource),
+		client:                         c,
+		resource:                       resource.Resource,
+	}
+}
+
+func (c *testClient) onList(resource string, opts metav1.ListOptions) {
+	c.mutex.Lock()
+	c.limits = append(c.limits, opts.Limit)
+	c.mutex.Unlock()
+
+	if resource == c.blockedResource {
+		<-c.unblock
+	}
+}
+
+func (c *testClient) onWatch(resource string, w watch.Interface, err error) (watch.Interface, error) {
+	if err == nil {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if resource == c.failWatchOf {
+			c.failWatchOf = ""
+
+			w.Stop()
+
+			return nil, errors.New("mock watch error")
+		}
+
+		if c.watches == nil {
+			c.watches = map[string][]watch.Interface{}
+		}
+
+		c.watches[resource] = append(c.watches[resource], w)
+	}
+
+	return w, err
+}
+
+// dropWatches stops the active watches for the given resource to simulate a dropped watch connection.
+func (c *testClient) dropWatches(resource string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, w := range c.watches[resource] {
+		w.Stop()
+	}
+
+	c.watches[resource] = nil
+}
+
+// failWatches stops the active watches for the given resource and fails the next watch request to simulate an
+// interrupted watch connection.
+func (c *testClient) failWatches(resource string) {
+	c.mutex.Lock()
+	c.failWatchOf = resource
+	c.mutex.Unlock()
+
+	c.dropWatches(resource)
+}
+
+func (c *testClient) activeWatches(resource string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.watches[resource])
+}
+
+func (c *testClient) listLimits() []int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]int64{}, c.limits...)
+}
+
+func (r *testNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &testResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), client: r.client, resource: r.resource}
+}
+
+func (r *testNamespaceableResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.client.onList(r.resource, opts)
+	return r.NamespaceableResourceInterface.List(ctx, opts)
+}
+
+func (r *testResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.client.onList(r.resource, opts)
+	return r.ResourceInterface.List(ctx, opts)
+}
+
+func (r *testNamespaceableResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.NamespaceableResourceInterface.Watch(ctx, opts)
+	return r.client.onWatch(r.resource, w, err)
+}
+
+func (r *testResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.ResourceInterface.Watch(ctx, opts)
+	return r.client.onWatch(r.resource, w, err)
+}
+
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.String()
+}
+
+type correlatedEvent struct {
+	name          string
+	correlationID string
+}
+
+type correlationHandler struct {
+	event.HandlerBase
+	name   string
+	events chan correlatedEvent
+}
+
+func (h *correlationHandler) GetName() string {
+	return h.name
+}
+
+func (h *correlationHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *correlationHandler) notify(eventName string) error {
+	h.events <- correlatedEvent{name: h.name + "/" + eventName, correlationID: h.State().GetCorrelationID()}
+	return nil
+}
+
+func (h *correlationHandler) TransitionToGateway() error {
+	return h.notify(testing.EvTransitionToGateway)
+}
+
+func (h *correlationHandler) LocalEndpointCreated(_ *submV1.Endpoint) error {
+	return h.notify(testing.EvLocalEndpointCreated)
+}
+
+func (h *correlationHandler) RemoteEndpointCreated(_ *submV1.Endpoint) error {
+	return h.notify(testing.EvRemoteEndpointCreated)
+}
+
+// lengthPrefixedCodec encodes each field of a record as a length-prefixed value.
+type lengthPrefixedCodec struct {
+	encoded atomic.Int32
+	decoded atomic.Int32
+}
+
+func (c *lengthPrefixedCodec) Encode(w io.Writer, record *controller.EventRecord) error {
+	object, err := json.Marshal(record.Object)
+	if err != nil {
+		return err
+	}
+
+	var buf []byte
+
+	for _, field := range [][]byte{
+		[]byte(record.Resource), []byte(record.Cluster), []byte(record.Operation),
+		[]byte(strconv.Itoa(record.NumRequeues)), object,
+	} {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+
+	c.encoded.Add(1)
+
+	_, err = w.Write(buf)
+
+	return err
+}
+
+func (c *lengthPrefixedCodec) Decode(r *bufio.Reader) (*controller.EventRecord, error) {
+	fields := make([][]byte, 5)
+
+	for i := range fields {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = make([]byte, n)
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	numRequeues, err := strconv.Atoi(string(fields[3]))
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(fields[4], &object); err != nil {
+		return nil, err
+	}
+
+	c.decoded.Add(1)
+
+	return &controller.EventRecord{
+		Resource:    string(fields[0]),
+		Cluster:     string(fields[1]),
+		Operation:   string(fields[2]),
+		NumRequeues: numRequeues,
+		Object:      &unstructured.Unstructured{Object: object},
+	}, nil
+}
+
+type drainHandler struct {
+	event.HandlerBase
+	name    string
+	delay   time.Duration
+	drained atomic.Bool
+}
+
+func (h *drainHandler) GetName() string {
+	return h.name
+}
+
+func (h *drainHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *drainHandler) TransitionToNonGateway() error {
+	time.Sleep(h.delay)
+	h.drained.Store(true)
+
+	return nil
+}
+
+// knownClustersStub is a KnownClustersSource returning the clusters and error last set.
+type knownClustersStub struct {
+	mutex      sync.Mutex
+	clusterIDs []string
+	err        error
+}
+
+func (k *knownClustersStub) set(clusterIDs ...string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.clusterIDs = clusterIDs
+}
+
+func (k *knownClustersStub) setErr(err error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.err = err
+}
+
+func (k *knownClustersStub) KnownClusters() ([]string, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	return k.clusterIDs, k.err
+}
+
+// blockingNodeHandler blocks the notification of the created Node with the given name until released.
+type blockingNodeHandler struct {
+	event.HandlerBase
+	blockOn string
+	release chan struct{}
+}
+
+func (h *blockingNodeHandler) GetName() string {
+	return "blocking-node-handler"
+}
+
+func (h *blockingNodeHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *blockingNodeHandler) NodeCreated(node *corev1.Node) error {
+	if node.Name == h.blockOn {
+		<-h.release
+	}
+
+	return nil
+}
+
+type slowNodeHandler struct {
+	event.HandlerBase
+	slowOn string
+	delay  time.Duration
+}
+
+func (h *slowNodeHandler) GetName() string {
+	return "slow-node-handler"
+}
+
+func (h *slowNodeHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *slowNodeHandler) NodeCreated(node *corev1.Node) error {
+	if node.Name == h.slowOn {
+		time.Sleep(h.delay)
+	}
+
+	return nil
+}
+
+type slowGatewayHandler struct {
+	event.HandlerBase
+	name  string
+	delay time.Duration
+}
+
+func (h *slowGatewayHandler) GetName() string {
+	return h.name
+}
+
+func (h *slowGatewayHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *slowGatewayHandler) TransitionToGateway() error {
+	time.Sleep(h.delay)
+	return nil
+}
+
+// gatewayTransitionHistogram returns the sample sum and count of the gateway transition duration histogram metric.
+func gatewayTransitionHistogram() (float64, uint64) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).To(Succeed())
+
+	for _, family := range families {
+		if family.GetName() == "submariner_event_gateway_transition_duration_seconds" && len(family.GetMetric()) > 0 {
+			histogram := family.GetMetric()[0].GetHistogram()
+			return histogram.GetSampleSum(), histogram.GetSampleCount()
+		}
+	}
+
+	return 0, 0
+}
+
+// nodeEventHistogram returns the sample sum and count of the given histogram metric for the Node resource.
+func nodeEventHistogram(name string) (float64, uint64) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).To(Succeed())
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "resource" && label.GetValue() == controller.NodeResource {
+					return metric.GetHistogram().GetSampleSum(), metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	return 0, 0
+}
+
+type leadershipHandler struct {
+	event.HandlerBase
+	changes chan bool
+}
+
+func (h *leadershipHandler) GetName() string {
+	return "leadership-handler"
+}
+
+func (h *leadershipHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *leadershipHandler) OnLeadershipChanged(isLeader bool) error {
+	h.changes <- isLeader
+	return nil
+}
+
+type unstructuredEvent struct {
+	gvr       schema.GroupVersionResource
+	eventType event.Type
+	name      string
+}
+
+type unstructuredHandler struct {
+	event.HandlerBase
+	events chan unstructuredEvent
+}
+
+func (h *unstructuredHandler) GetName() string {
+	return "unstructured-handler"
+}
+
+func (h *unstructuredHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *unstructuredHandler) OnUnstructuredEvent(gvr schema.GroupVersionResource, eventType event.Type,
+	obj *unstructured.Unstructured,
+) error {
+	h.events <- unstructuredEvent{gvr: gvr, eventType: eventType, name: obj.GetName()}
+	return nil
+}
+
+type soleGatewayHandler struct {
+	event.HandlerBase
+	sole chan bool
+}
+
+func (h *soleGatewayHandler) GetName() string {
+	return "sole-gateway-handler"
+}
+
+func (h *soleGatewayHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *soleGatewayHandler) OnSoleGateway(sole bool) error {
+	h.sole <- sole
+	return nil
+}
+
+type endpointBatch struct {
+	key    string
+	events []event.EndpointEvent
+}
+
+type batchHandler struct {
+	event.HandlerBase
+	batches chan endpointBatch
+}
+
+func (h *batchHandler) GetName() string {
+	return "batch-handler"
+}
+
+func (h *batchHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *batchHandler) RemoteEndpointCreated(_ *submV1.Endpoint) error {
+	return errors.New("unexpected individual RemoteEndpointCreated")
+}
+
+func (h *batchHandler) OnRemoteEndpointBatch(key string, batch []event.EndpointEvent) error {
+	h.batches <- endpointBatch{key: key, events: batch}
+	return nil
+}
+
+type failingInitHandler struct {
+	*testing.TestHandler
+}
+
+func (h *failingInitHandler) Init() error {
+	return errors.New("mock init error")
+}
+
+const evPreStart = "PreStart"
+
+const (
+	evControllerStarted  = "ControllerStarted"
+	evControllerStopping = "ControllerStopping"
+)
+
+type lifecycleHandler struct {
+	*testing.TestHandler
+}
+
+func (h *lifecycleHandler) OnControllerStarted() error {
+	h.Events <- testing.TestEvent{Handler: h.Name, Name: evControllerStarted}
+	return nil
+}
+
+func (h *lifecycleHandler) OnControllerStopping() error {
+	h.Events <- testing.TestEvent{Handler: h.Name, Name: evControllerStopping}
+	return nil
+}
+
+type readinessHandler struct {
+	*testing.TestHandler
+	ready chan struct{}
+}
+
+func (h *readinessHandler) Ready() <-chan struct{} {
+	return h.ready
+}
+
+type preStartHandler struct {
+	*testing.TestHandler
+	state      event.HandlerState
+	err        error
+	onPreStart func()
+}
+
+func (h *preStartHandler) PreStart(state event.HandlerState) error {
+	h.state = state
+
+	if h.onPreStart != nil {
+		h.onPreStart()
+	}
+
+	h.Events <- testing.TestEvent{Handler: h.Name, Name: evPreStart}
+
+	return h.err
+}
+
+// failingNodeHandler fails to handle created Nodes while fail is set. If failOnly is set, only the Node with that name
+// fails.
+type failingNodeHandler struct {
+	event.HandlerBase
+	fail     atomic.Bool
+	failOnly string
+}
+
+func (h *failingNodeHandler) GetName() string {
+	return "failing-node-handler"
+}
+
+func (h *failingNodeHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (h *failingNodeHandler) NodeCreated(node *corev1.Node) error {
+	if h.fail.Load() && (h.failOnly == "" || node.Name == h.failOnly) {
+		return errors.New("mock node handler error")
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DropReason identifies why an event received from a watcher was dropped rather than dispatched to the handlers.
+type DropReason string
+
+const (
+	// DropReasonMaxRequeues indicates the event still failed after being requeued the maximum number of times.
+	DropReasonMaxRequeues DropReason = "MaxRequeues"
+
+	// DropReasonFiltered indicates the event was rejected by the EventFilter.
+	DropReasonFiltered DropReason = "Filtered"
+
+	// DropReasonOversized indicates the object's serialized size exceeds MaxObjectBytes.
+	DropReasonOversized DropReason = "Oversized"
+
+	// DropReasonStillExists indicates the delete event of a remote Endpoint was ignored as the Endpoint still exists, if
+	// ConfirmRemoteEndpointDeletes is set.
+	DropReasonStillExists DropReason = "StillExists"
+
+	// DropReasonOrphanRemoved indicates the delete event of a remote Endpoint was ignored as its removal was already
+	// synthesized since its cluster is no longer known, as per KnownClusters.
+	DropReasonOrphanRemoved DropReason = "OrphanRemoved"
+
+	// DropReasonSuperseded indicates the delete event of an Endpoint was ignored as another Endpoint with the same
+	// identity, as per EndpointIdentity, has since replaced it.
+	DropReasonSuperseded DropReason = "Superseded"
+
+	// DropReasonInvalid indicates the event's Endpoint was rejected by the EndpointValidator.
+	DropReasonInvalid DropReason = "Invalid"
+
+	// DropReasonNodeNotReady indicates the event's Endpoint is on a NotReady Node, if SuppressEndpointsOnNotReadyNodes is
+	// set.
+	DropReasonNodeNotReady DropReason = "NodeNotReady"
+)
+
+// dropEvent logs that the given event was dropped, with the reason as a structured value, and notifies the OnEventDropped
+// callback, if any. Must be called with the syncMutex held.
+func (c *Controller) dropEvent(eventType event.Type, obj runtime.Object, reason DropReason, format string, args ...interface{}) {
+	logger := log.Logger{Logger: c.eventLog.WithValues("dropReason", reason)}
+	reasonMsg := fmt.Sprintf(format, args...)
+	msg := fmt.Sprintf("Event %q for %T %q dropped: ", eventType, obj, resourceName(obj)) + reasonMsg
+
+	switch reason {
+	case DropReasonMaxRequeues:
+		logger.Error(nil, msg)
+	case DropReasonFiltered, DropReasonOrphanRemoved, DropReasonSuperseded, DropReasonNodeNotReady:
+		logger.V(log.DEBUG).Info(msg)
+	case DropReasonOversized, DropReasonStillExists, DropReasonInvalid:
+		logger.Warning(msg)
+	}
+
+	c.filteredEndpoints.observe(eventType, obj, reason, reasonMsg)
+
+	if c.onEventDropped != nil {
+		c.onEventDropped(eventType, obj, reason)
+	}
+}
@@ -20,6 +20,7 @@ package controller
 
 import (
 	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -28,49 +29,87 @@ func (c *Controller) handleCreatedEndpoint(obj runtime.Object, requeueCount int)
 
 	endpoint := obj.(*smv1.Endpoint)
 
+	eventType := event.LocalEndpointCreated
+	if endpoint.Spec.ClusterID != c.env.ClusterID {
+		eventType = event.RemoteEndpointCreated
+	}
+
 	if requeueCount > maxRequeues {
-		logger.Errorf(nil, "Ignoring create event for endpoint %q, as its requeued for more than %d times",
-			endpoint.Spec.ClusterID, maxRequeues)
+		c.dropEvent(eventType, endpoint, DropReasonMaxRequeues, "requeued more than %d times", maxRequeues)
 		return false
 	}
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	if !c.isValidEndpoint(eventType, endpoint) {
+		return false
+	}
 
-	if endpoint.Spec.ClusterID != c.env.ClusterID {
+	if !c.shouldDispatch(eventType, endpoint) {
+		return false
+	}
+
+	c.filteredEndpoints.forget(endpoint)
+
+	if c.deferInitialEndpointEvent(eventType, endpoint) {
+		return false
+	}
+
+	if eventType == event.RemoteEndpointCreated {
 		err = c.handleCreatedRemoteEndpoint(endpoint)
 	} else {
 		err = c.handleCreatedLocalEndpoint(endpoint)
 	}
 
 	if err != nil {
-		logger.Error(err, "Error handling created endpoint")
+		c.eventLog.Error(err, "Error handling created endpoint")
 	}
 
 	return err != nil
 }
 
 func (c *Controller) handleCreatedLocalEndpoint(endpoint *smv1.Endpoint) error {
-	if endpoint.Spec.Hostname == c.hostname {
+	if c.isLocalHostEndpoint(endpoint) && c.isLocalNodeGatewayEligible() {
 		c.handlerState.setIsOnGateway(true)
 	}
 
+	c.localEndpoints[endpoint.Name] = endpoint
+
 	err := c.handlers.LocalEndpointCreated(endpoint)
 
-	if err == nil && !c.handlerState.wasOnGateway && c.handlerState.IsOnGateway() {
-		logger.Infof("Transitioned to gateway node %q with endpoint private IP %s", c.hostname, endpoint.Spec.PrivateIP)
+	if err == nil && !c.handlerState.wasOnGateway.Load() && c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Transitioned to gateway node %q with endpoint private IP %s", c.hostname, endpoint.Spec.PrivateIP)
 
-		err = c.handlers.TransitionToGateway()
+		err = c.transitionToGateway()
 	}
 
 	if err == nil {
-		c.handlerState.wasOnGateway = c.handlerState.IsOnGateway()
+		c.handlerState.wasOnGateway.Store(c.handlerState.IsOnGateway())
 	}
 
 	return err //nolint:wrapcheck  // Let the caller wrap it
 }
 
 func (c *Controller) handleCreatedRemoteEndpoint(endpoint *smv1.Endpoint) error {
-	c.handlerState.remoteEndpoints.Store(endpoint.Name, endpoint)
-	return c.handlers.RemoteEndpointCreated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	if restored, err := c.handleRestoredRemoteEndpoint(endpoint); restored {
+		return err
+	}
+
+	c.orphanedEndpoints.Delete(endpoint.Name)
+
+	if c.isDuplicateRemoteEndpoint(endpoint) {
+		c.eventLog.Infof("Remote endpoint %q has the same identity as a tracked endpoint - handling as an update", endpoint.Name)
+		return c.handleUpdatedRemoteEndpoint(endpoint)
+	}
+
+	c.handlerState.remoteEndpoints.Store(c.endpointIdentity(endpoint), endpoint)
+	c.metrics.recordRemoteEndpointEvent(event.RemoteEndpointCreated, endpoint.Spec.ClusterID, true)
+
+	err := c.handlers.RemoteEndpointCreated(endpoint)
+	if err == nil {
+		c.batchRemoteEndpoint(event.RemoteEndpointCreated, endpoint)
+		c.detectSubnetConflicts(endpoint)
+
+		err = c.updatePreferredGateway(endpoint, false)
+	}
+
+	return err //nolint:wrapcheck  // Let the caller wrap it
 }
@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+// ReportEndpointHealth reports the reachability of the gateway Endpoint of the given remote cluster, eg from a liveness
+// probe. If the health status changes, the tracked EndpointInfo is updated and the handlers are notified via
+// EndpointHealthChanged.
+func (c *Controller) ReportEndpointHealth(clusterID string, healthy bool) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	endpoint, found := c.handlerState.GetGatewayEndpoint(clusterID)
+	if !found {
+		c.eventLog.Warningf("Ignoring health report for remote cluster %q as it has no Endpoint", clusterID)
+		return
+	}
+
+	var changed bool
+
+	if healthy {
+		_, changed = c.handlerState.unhealthyClusters.LoadAndDelete(clusterID)
+	} else {
+		_, loaded := c.handlerState.unhealthyClusters.LoadOrStore(clusterID, true)
+		changed = !loaded
+	}
+
+	if !changed {
+		return
+	}
+
+	c.eventLog.Infof("Endpoint %q for remote cluster %q is now healthy: %v", endpoint.Name, clusterID, healthy)
+
+	if err := c.handlers.EndpointHealthChanged(endpoint, healthy); err != nil {
+		c.eventLog.Error(err, "Error handling Endpoint health change")
+	}
+}
@@ -0,0 +1,44 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+// DefaultEndpointIdentity is the EndpointIdentity used if none is configured. It identifies an Endpoint by its cluster ID
+// and cable name, falling back to its name if it has no cable name.
+func DefaultEndpointIdentity(endpoint *smv1.Endpoint) string {
+	if endpoint.Spec.CableName == "" {
+		return endpoint.Name
+	}
+
+	return endpoint.Spec.ClusterID + "/" + endpoint.Spec.CableName
+}
+
+func (c *Controller) endpointIdentity(endpoint *smv1.Endpoint) string {
+	return c.identityFunc(endpoint)
+}
+
+// isDuplicateRemoteEndpoint returns true if another remote Endpoint with the same identity as the given Endpoint, but a
+// different name, is currently tracked. Local Endpoints are tracked by name as each node's Endpoint is distinct.
+func (c *Controller) isDuplicateRemoteEndpoint(endpoint *smv1.Endpoint) bool {
+	tracked, found := c.handlerState.remoteEndpoints.Load(c.endpointIdentity(endpoint))
+	return found && tracked.(*smv1.Endpoint).Name != endpoint.Name
+}
@@ -19,57 +19,161 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
 	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 )
 
+// deleteConfirmationTimeout is the maximum time to wait for the API server to confirm a remote Endpoint removal.
+const deleteConfirmationTimeout = 5 * time.Second
+
 func (c *Controller) handleRemovedEndpoint(obj runtime.Object, requeueCount int) bool {
 	endpoint := obj.(*smv1.Endpoint)
 
+	eventType := event.LocalEndpointRemoved
+	if endpoint.Spec.ClusterID != c.env.ClusterID {
+		eventType = event.RemoteEndpointRemoved
+	}
+
+	c.filteredEndpoints.forget(endpoint)
+
 	if requeueCount > maxRequeues {
-		logger.Errorf(nil, "Ignoring delete event for endpoint %q, as its requeued for more than %d times",
-			endpoint.Spec.ClusterID, maxRequeues)
+		c.dropEvent(eventType, endpoint, DropReasonMaxRequeues, "requeued more than %d times", maxRequeues)
 		return false
 	}
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	if eventType == event.RemoteEndpointRemoved && c.orphanedEndpoints.Has(endpoint.Name) {
+		c.orphanedEndpoints.Delete(endpoint.Name)
+		c.dropEvent(eventType, endpoint, DropReasonOrphanRemoved, "its removal was already synthesized")
+
+		return false
+	}
+
+	if !c.isValidEndpoint(eventType, endpoint) {
+		return false
+	}
+
+	if !c.shouldDispatch(eventType, endpoint) {
+		return false
+	}
+
+	if eventType == event.RemoteEndpointRemoved && c.isDuplicateRemoteEndpoint(endpoint) {
+		c.dropEvent(eventType, endpoint, DropReasonSuperseded, "another Endpoint with the same identity %q is tracked",
+			c.endpointIdentity(endpoint))
+
+		return false
+	}
+
+	if c.deferInitialEndpointEvent(eventType, endpoint) {
+		return false
+	}
 
 	var err error
-	if endpoint.Spec.ClusterID != c.env.ClusterID {
+	if eventType == event.RemoteEndpointRemoved {
 		err = c.handleRemovedRemoteEndpoint(endpoint)
 	} else {
 		err = c.handleRemovedLocalEndpoint(endpoint)
 	}
 
 	if err != nil {
-		logger.Error(err, "Error handling removed endpoint")
+		c.eventLog.Error(err, "Error handling removed endpoint")
 	}
 
 	return err != nil
 }
 
 func (c *Controller) handleRemovedLocalEndpoint(endpoint *smv1.Endpoint) error {
-	if endpoint.Spec.Hostname == c.hostname {
+	if c.isLocalHostEndpoint(endpoint) {
 		c.handlerState.setIsOnGateway(false)
 	}
 
+	delete(c.localEndpoints, endpoint.Name)
+
 	err := c.handlers.LocalEndpointRemoved(endpoint)
 
-	if err == nil && c.handlerState.wasOnGateway && !c.handlerState.IsOnGateway() {
-		logger.Infof("Transitioned to non-gateway node %q", endpoint.Spec.Hostname)
+	if err == nil && c.handlerState.wasOnGateway.Load() && !c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Transitioned to non-gateway node %q", endpoint.Spec.Hostname)
 
-		err = c.handlers.TransitionToNonGateway()
+		err = c.transitionToNonGateway()
 	}
 
 	if err == nil {
-		c.handlerState.wasOnGateway = c.handlerState.IsOnGateway()
+		c.handlerState.wasOnGateway.Store(c.handlerState.IsOnGateway())
 	}
 
 	return err //nolint:wrapcheck  // Let the caller wrap it
 }
 
 func (c *Controller) handleRemovedRemoteEndpoint(endpoint *smv1.Endpoint) error {
-	c.handlerState.remoteEndpoints.Delete(endpoint.Name)
-	return c.handlers.RemoteEndpointRemoved(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	c.metrics.recordRemoteEndpointEvent(event.RemoteEndpointRemoved, endpoint.Spec.ClusterID,
+		c.handlerState.HasRemoteCluster(endpoint.Spec.ClusterID))
+
+	c.handlerState.remoteEndpoints.Delete(c.endpointIdentity(endpoint))
+
+	if c.handlerState.addresses != nil {
+		c.handlerState.addresses.forget(endpoint.Name)
+	}
+
+	if _, found := c.handlerState.GetGatewayEndpoint(endpoint.Spec.ClusterID); !found {
+		c.handlerState.unhealthyClusters.Delete(endpoint.Spec.ClusterID)
+		c.metrics.forgetCluster(endpoint.Spec.ClusterID)
+	}
+
+	err := c.handlers.RemoteEndpointRemoved(endpoint)
+	if err == nil {
+		c.batchRemoteEndpoint(event.RemoteEndpointRemoved, endpoint)
+
+		err = c.updatePreferredGateway(endpoint, true)
+	}
+
+	return err //nolint:wrapcheck  // Let the caller wrap it
+}
+
+// confirmingRemoteEndpointRemoval wraps the given Endpoint delete function to first confirm via a direct Get that a remote
+// Endpoint was actually deleted. If it still exists, eg the delete was caused by a transient watch error, the removal
+// is skipped. If the removal can't be confirmed, it's requeued.
+func (c *Controller) confirmingRemoteEndpointRemoval(client dynamic.Interface, f func(runtime.Object, int) bool,
+) func(runtime.Object, int) bool {
+	return func(obj runtime.Object, requeueCount int) bool {
+		endpoint := obj.(*smv1.Endpoint)
+
+		if endpoint.Spec.ClusterID != c.env.ClusterID && requeueCount <= maxRequeues {
+			exists, err := endpointExists(client, endpoint)
+			if err != nil {
+				c.eventLog.Error(err, "Error confirming the removal of remote endpoint")
+				return true
+			}
+
+			if exists {
+				c.dropEvent(event.RemoteEndpointRemoved, endpoint, DropReasonStillExists, "the Endpoint still exists")
+				return false
+			}
+		}
+
+		return f(obj, requeueCount)
+	}
+}
+
+func endpointExists(client dynamic.Interface, endpoint *smv1.Endpoint) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), deleteConfirmationTimeout)
+	defer cancel()
+
+	_, err := client.Resource(smv1.SchemeGroupVersion.WithResource("endpoints")).Namespace(endpoint.Namespace).Get(ctx,
+		endpoint.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errors.Wrapf(err, "error retrieving Endpoint %q", endpoint.Name)
+	}
+
+	return true, nil
 }
@@ -19,41 +19,132 @@ limitations under the License.
 package controller
 
 import (
+	"reflect"
+
+	"github.com/submariner-io/admiral/pkg/syncer"
 	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DefaultIgnoredEndpointAnnotations are the keys of the heartbeat and timestamp annotations that are periodically
+// refreshed on Endpoints and are ignored by default when determining whether an Endpoint update should be dispatched.
+var DefaultIgnoredEndpointAnnotations = []string{
+	"submariner.io/last-heartbeat",
+	"submariner.io/heartbeat-timestamp",
+}
+
 func (c *Controller) handleUpdatedEndpoint(obj runtime.Object, requeueCount int) bool {
 	endpoint := obj.(*smv1.Endpoint)
 
+	eventType := event.LocalEndpointUpdated
+	if endpoint.Spec.ClusterID != c.env.ClusterID {
+		eventType = event.RemoteEndpointUpdated
+	}
+
 	if requeueCount > maxRequeues {
-		logger.Errorf(nil, "Ignoring update event for endpoint %q, as its requeued for more than %d times",
-			endpoint.Spec.ClusterID, maxRequeues)
+		c.dropEvent(eventType, endpoint, DropReasonMaxRequeues, "requeued more than %d times", maxRequeues)
+		return false
+	}
+
+	if !c.isValidEndpoint(eventType, endpoint) {
+		return false
+	}
+
+	if !c.shouldDispatch(eventType, endpoint) {
 		return false
 	}
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	c.filteredEndpoints.forget(endpoint)
+
+	if c.deferInitialEndpointEvent(eventType, endpoint) {
+		return false
+	}
 
 	var err error
-	if endpoint.Spec.ClusterID != c.env.ClusterID {
+	if eventType == event.RemoteEndpointUpdated {
 		err = c.handleUpdatedRemoteEndpoint(endpoint)
 	} else {
 		err = c.handleUpdatedLocalEndpoint(endpoint)
 	}
 
 	if err != nil {
-		logger.Error(err, "Error handling updated endpoint")
+		c.eventLog.Error(err, "Error handling updated endpoint")
 	}
 
 	return err != nil
 }
 
+// isEndpointEquivalent returns whether the given Endpoints differ only by the ignored annotations, in which case the
+// update isn't dispatched.
+func (c *Controller) isEndpointEquivalent(obj1, obj2 *unstructured.Unstructured) bool {
+	return reflect.DeepEqual(obj1.GetLabels(), obj2.GetLabels()) &&
+		reflect.DeepEqual(c.withoutIgnoredAnnotations(obj1.GetAnnotations()), c.withoutIgnoredAnnotations(obj2.GetAnnotations())) &&
+		syncer.AreSpecsEquivalent(obj1, obj2)
+}
+
+func (c *Controller) withoutIgnoredAnnotations(annotations map[string]string) map[string]string {
+	filtered := map[string]string{}
+
+	for k, v := range annotations {
+		if !c.ignoredAnnotations.Has(k) {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
 func (c *Controller) handleUpdatedLocalEndpoint(endpoint *smv1.Endpoint) error {
-	return c.handlers.LocalEndpointUpdated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	oldEndpoint := c.localEndpoints[endpoint.Name]
+	c.localEndpoints[endpoint.Name] = endpoint
+
+	err := c.handlers.LocalEndpointUpdated(endpoint)
+
+	if err == nil && oldEndpoint != nil && (oldEndpoint.Spec.PublicIP != endpoint.Spec.PublicIP ||
+		oldEndpoint.Spec.PrivateIP != endpoint.Spec.PrivateIP) {
+		c.eventLog.Infof("The IPs of local endpoint %q changed from public %q, private %q to public %q, private %q",
+			endpoint.Name, oldEndpoint.Spec.PublicIP, oldEndpoint.Spec.PrivateIP, endpoint.Spec.PublicIP, endpoint.Spec.PrivateIP)
+
+		err = c.handlers.LocalEndpointIPChanged(oldEndpoint, endpoint)
+	}
+
+	if err == nil && oldEndpoint != nil {
+		err = c.notifyBackendConfigChanges(oldEndpoint, endpoint)
+	}
+
+	// Restore the previous Endpoint on failure so an IP or backend config change is detected again when retried.
+	if err != nil && oldEndpoint != nil {
+		c.localEndpoints[endpoint.Name] = oldEndpoint
+	}
+
+	return err //nolint:wrapcheck  // Let the caller wrap it
 }
 
 func (c *Controller) handleUpdatedRemoteEndpoint(endpoint *smv1.Endpoint) error {
-	c.handlerState.remoteEndpoints.Store(endpoint.Name, endpoint)
-	return c.handlers.RemoteEndpointUpdated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	id := c.endpointIdentity(endpoint)
+	oldEndpoint, _ := c.handlerState.remoteEndpoints.Load(id)
+
+	c.handlerState.remoteEndpoints.Store(id, endpoint)
+	c.metrics.recordRemoteEndpointEvent(event.RemoteEndpointUpdated, endpoint.Spec.ClusterID, true)
+
+	err := c.handlers.RemoteEndpointUpdated(endpoint)
+	if err == nil {
+		c.batchRemoteEndpoint(event.RemoteEndpointUpdated, endpoint)
+		c.detectSubnetConflicts(endpoint)
+
+		err = c.updatePreferredGateway(endpoint, false)
+	}
+
+	if err == nil && oldEndpoint != nil {
+		err = c.notifyBackendConfigChanges(oldEndpoint.(*smv1.Endpoint), endpoint)
+
+		// Restore the previous Endpoint on failure so the backend config change is detected again when retried.
+		if err != nil {
+			c.handlerState.remoteEndpoints.Store(id, oldEndpoint)
+		}
+	}
+
+	return err //nolint:wrapcheck  // Let the caller wrap it
 }
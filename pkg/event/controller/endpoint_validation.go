@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+)
+
+// DefaultEndpointValidator is the EndpointValidator used if none is configured. It rejects Endpoints without a cluster ID
+// or with a malformed subnet CIDR.
+func DefaultEndpointValidator(endpoint *smv1.Endpoint) error {
+	if endpoint.Spec.ClusterID == "" {
+		return errors.New("the cluster ID is empty")
+	}
+
+	for _, subnet := range endpoint.Spec.Subnets {
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			return errors.Errorf("the subnet %q is not a valid CIDR", subnet)
+		}
+	}
+
+	return nil
+}
+
+// isValidEndpoint returns whether the given Endpoint passes the EndpointValidator. If not, the event is dropped and
+// counted as invalid. Must be called with the syncMutex held.
+func (c *Controller) isValidEndpoint(eventType event.Type, endpoint *smv1.Endpoint) bool {
+	err := c.endpointValidator(endpoint)
+	if err == nil {
+		return true
+	}
+
+	c.metrics.recordInvalidEndpoint(eventType)
+	c.dropEvent(eventType, endpoint, DropReasonInvalid, "%v", err)
+
+	return false
+}
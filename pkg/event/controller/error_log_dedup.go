@@ -0,0 +1,128 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
+)
+
+// eventKeyLogKey is the log value that identifies the event being processed, by which repeated errors are deduplicated.
+const eventKeyLogKey = "eventKey"
+
+// repeatedErrors tracks the error lines logged within the current ErrorLogDedupInterval, keyed by event key, message and
+// error, shared by all the loggers derived from a dedupingSink.
+type repeatedErrors struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	clock    clock.WithTickerAndDelayedExecution
+	entries  map[string]*repeatedError
+}
+
+type repeatedError struct {
+	sink          logr.LogSink
+	err           error
+	msg           string
+	keysAndValues []any
+	suppressed    int
+}
+
+// dedupingSink wraps a LogSink such that identical error lines for the same event key are collapsed: the first is logged
+// and the repeats within the interval are counted and suppressed. Once the interval elapses, the number of suppressed
+// repeats, if any, is logged with the last repeated line.
+type dedupingSink struct {
+	logr.LogSink
+	eventKey string
+	errors   *repeatedErrors
+}
+
+func newDedupingSink(sink logr.LogSink, interval time.Duration, clk clock.WithTickerAndDelayedExecution) logr.LogSink {
+	return &dedupingSink{
+		LogSink: sink,
+		errors: &repeatedErrors{
+			interval: interval,
+			clock:    clk,
+			entries:  map[string]*repeatedError{},
+		},
+	}
+}
+
+func (s *dedupingSink) Init(info logr.RuntimeInfo) {
+	// Account for the frame added by this sink.
+	info.CallDepth++
+	s.LogSink.Init(info)
+}
+
+func (s *dedupingSink) Error(err error, msg string, keysAndValues ...any) {
+	key := fmt.Sprintf("%s|%s|%v", s.eventKey, msg, err)
+
+	s.errors.mutex.Lock()
+	defer s.errors.mutex.Unlock()
+
+	if entry, found := s.errors.entries[key]; found {
+		entry.sink, entry.err, entry.keysAndValues = s.LogSink, err, keysAndValues
+		entry.suppressed++
+
+		return
+	}
+
+	s.LogSink.Error(err, msg, keysAndValues...)
+
+	s.errors.entries[key] = &repeatedError{sink: s.LogSink, err: err, msg: msg, keysAndValues: keysAndValues}
+
+	s.errors.clock.AfterFunc(s.errors.interval, func() {
+		s.errors.flush(key)
+	})
+}
+
+// flush ends the interval of the repeated error with the given key and logs the number of suppressed repeats, if any.
+func (r *repeatedErrors) flush(key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry := r.entries[key]
+	delete(r.entries, key)
+
+	if entry == nil || entry.suppressed == 0 {
+		return
+	}
+
+	entry.sink.Error(entry.err, fmt.Sprintf("%s (repeated %d more times in the last %v)", entry.msg, entry.suppressed, r.interval),
+		append(append([]any{}, entry.keysAndValues...), "repeated", entry.suppressed)...)
+}
+
+func (s *dedupingSink) WithValues(keysAndValues ...any) logr.LogSink {
+	derived := &dedupingSink{LogSink: s.LogSink.WithValues(keysAndValues...), eventKey: s.eventKey, errors: s.errors}
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i] == eventKeyLogKey {
+			derived.eventKey = fmt.Sprint(keysAndValues[i+1])
+		}
+	}
+
+	return derived
+}
+
+func (s *dedupingSink) WithName(name string) logr.LogSink {
+	return &dedupingSink{LogSink: s.LogSink.WithName(name), eventKey: s.eventKey, errors: s.errors}
+}
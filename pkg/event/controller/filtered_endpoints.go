@@ -0,0 +1,107 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"sync"
+
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FilteredEndpoint is an observed Endpoint whose last event was filtered out rather than dispatched to the handlers, eg
+// to debug why a handler isn't reacting to it.
+type FilteredEndpoint struct {
+	// Endpoint is a copy of the Endpoint as last observed.
+	Endpoint *smv1.Endpoint
+
+	// EventType is the type of the filtered event.
+	EventType event.Type
+
+	// Reason identifies why the event was filtered, ie DropReasonFiltered, DropReasonOversized, DropReasonInvalid or
+	// DropReasonNodeNotReady.
+	Reason DropReason
+
+	// Message describes the reason, eg the error returned by the EndpointValidator.
+	Message string
+}
+
+// filteredEndpoints tracks the FilteredEndpoints by name until an event for the Endpoint is dispatched or it's removed.
+type filteredEndpoints struct {
+	mutex     sync.Mutex
+	endpoints map[string]FilteredEndpoint
+}
+
+// FilteredEndpoints returns the Endpoints whose last event was filtered out, sorted by name. An Endpoint is no longer
+// returned once a subsequent event for it is dispatched or it's removed.
+func (c *Controller) FilteredEndpoints() []FilteredEndpoint {
+	c.filteredEndpoints.mutex.Lock()
+	defer c.filteredEndpoints.mutex.Unlock()
+
+	filtered := make([]FilteredEndpoint, 0, len(c.filteredEndpoints.endpoints))
+	for _, f := range c.filteredEndpoints.endpoints {
+		f.Endpoint = f.Endpoint.DeepCopy()
+		filtered = append(filtered, f)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Endpoint.Name < filtered[j].Endpoint.Name
+	})
+
+	return filtered
+}
+
+// observe tracks the given Endpoint as filtered if the given reason filters it out. The removal of an Endpoint isn't
+// tracked, as it no longer exists.
+func (f *filteredEndpoints) observe(eventType event.Type, obj runtime.Object, reason DropReason, msg string) {
+	endpoint, ok := obj.(*smv1.Endpoint)
+	if !ok || eventType == event.LocalEndpointRemoved || eventType == event.RemoteEndpointRemoved {
+		return
+	}
+
+	switch reason {
+	case DropReasonFiltered, DropReasonOversized, DropReasonInvalid, DropReasonNodeNotReady:
+	case DropReasonMaxRequeues, DropReasonStillExists, DropReasonOrphanRemoved, DropReasonSuperseded:
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.endpoints == nil {
+		f.endpoints = map[string]FilteredEndpoint{}
+	}
+
+	f.endpoints[endpoint.Name] = FilteredEndpoint{
+		Endpoint:  endpoint.DeepCopy(),
+		EventType: eventType,
+		Reason:    reason,
+		Message:   msg,
+	}
+}
+
+// forget stops tracking the given Endpoint as filtered, if it was.
+func (f *filteredEndpoints) forget(endpoint *smv1.Endpoint) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.endpoints, endpoint.Name)
+}
@@ -0,0 +1,53 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// WatchGVR registers a watch for the resource type identified by the given GroupVersionResource, eg parsed from a
+// "group/version/resource" string, without requiring its typed Go struct. Its events are dispatched to the handlers
+// implementing event.UnstructuredResourceHandler as generic unstructured objects. The resource is watched in the given
+// namespace or, if empty, across the cluster. Its kind is resolved via the RESTMapper, so it must be known to the
+// cluster, eg its CRD is installed, and mustn't clash with another watched resource type. As for WatchResource, it must
+// be called before the controller is started.
+func (c *Controller) WatchGVR(gvr schema.GroupVersionResource, namespace string) error {
+	if c.lifecycle.get() != LifecycleNew {
+		return errors.New("resources can only be watched before the controller is started")
+	}
+
+	config, found := c.watcherConfigs[""]
+	if !found {
+		return errors.New("WatchGVR can't be used with multiple Clusters - use WatchResource instead")
+	}
+
+	gvk, err := config.RestMapper.KindFor(gvr)
+	if err != nil {
+		return errors.Wrapf(err, "the %s resource is not known", gvr)
+	}
+
+	return c.watchUnstructured(gvk, "", clusterScoped, namespace, config,
+		func(_ string, eventType event.Type, obj *unstructured.Unstructured) error {
+			return c.handlers.UnstructuredEvent(gvr, eventType, obj) //nolint:wrapcheck  // Let the caller wrap it
+		})
+}
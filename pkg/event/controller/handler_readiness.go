@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHandlersReadyTimeout is the default maximum time Start waits for the handlers to become ready if
+// WaitForHandlersReady is set.
+const DefaultHandlersReadyTimeout = time.Minute
+
+// waitForHandlersReady waits for the Ready channels of the handlers implementing event.ReadinessHandler to be closed,
+// bounded by the configured timeout. If the timeout elapses and FailOnHandlerInitError is set, an error naming the
+// handlers that aren't ready is returned, otherwise it's logged.
+func (c *Controller) waitForHandlersReady(stopCh <-chan struct{}) error {
+	pending := c.handlers.ReadyChannels()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	c.log.Infof("Waiting for %d event handler(s) to be ready", len(pending))
+
+	timeout := c.clock.After(c.handlersReadyTimeout)
+
+	for name, ready := range pending {
+		select {
+		case <-ready:
+			delete(pending, name)
+		case <-stopCh:
+			return errors.New("the event controller was stopped while waiting for the handlers to be ready")
+		case <-timeout:
+			return c.handlersNotReady(pending)
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) handlersNotReady(pending map[string]<-chan struct{}) error {
+	var names []string
+
+	for name, ready := range pending {
+		select {
+		case <-ready:
+		default:
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	err := errors.Errorf("timed out after %v waiting for the event handlers to be ready: %s", c.handlersReadyTimeout,
+		strings.Join(names, ", "))
+
+	if c.failOnInitErr {
+		return err
+	}
+
+	c.log.Error(err, "Not all event handlers are ready - continuing")
+
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Handler state", func() {
+	When("accessed concurrently from many goroutines", func() {
+		It("should be race-free", func() {
+			const numGoroutines = 50
+
+			state := &handlerStateImpl{}
+
+			var wg sync.WaitGroup
+
+			for i := 0; i < numGoroutines; i++ {
+				wg.Add(4)
+
+				go func(i int) {
+					defer wg.Done()
+
+					state.setIsOnGateway(i%2 == 0)
+					state.wasOnGateway.Store(state.IsOnGateway())
+				}(i)
+
+				go func(i int) {
+					defer wg.Done()
+
+					name := fmt.Sprintf("endpoint-%d", i)
+					state.remoteEndpoints.Store(name, &subv1.Endpoint{
+						ObjectMeta: metav1.ObjectMeta{Name: name},
+						Spec:       subv1.EndpointSpec{ClusterID: "remote-cluster"},
+					})
+				}(i)
+
+				go func() {
+					defer wg.Done()
+
+					_ = state.GetRemoteEndpoints()
+				}()
+
+				go func() {
+					defer wg.Done()
+
+					_, _ = state.GetGatewayEndpoint("remote-cluster")
+				}()
+			}
+
+			wg.Wait()
+
+			Expect(state.GetRemoteEndpoints()).To(HaveLen(numGoroutines))
+		})
+	})
+
+	When("the remote Endpoints are retrieved", func() {
+		var state *handlerStateImpl
+
+		BeforeEach(func() {
+			state = &handlerStateImpl{}
+
+			for _, ep := range []struct{ name, clusterID string }{
+				{"ep-b", "cluster-2"},
+				{"ep-c", "cluster-1"},
+				{"ep-a", "cluster-3"},
+				{"ep-a", "cluster-1"},
+			} {
+				state.remoteEndpoints.Store(ep.clusterID+"/"+ep.name, &subv1.Endpoint{
+					ObjectMeta: metav1.ObjectMeta{Name: ep.name},
+					Spec:       subv1.EndpointSpec{ClusterID: ep.clusterID},
+				})
+			}
+		})
+
+		names := func(endpoints []subv1.Endpoint) []string {
+			var n []string
+			for i := range endpoints {
+				n = append(n, endpoints[i].Spec.ClusterID+"/"+endpoints[i].Name)
+			}
+
+			return n
+		}
+
+		It("should return them sorted by cluster ID and name", func() {
+			for i := 0; i < 5; i++ {
+				Expect(names(state.GetRemoteEndpoints())).To(Equal([]string{
+					"cluster-1/ep-a", "cluster-1/ep-c", "cluster-2/ep-b", "cluster-3/ep-a",
+				}))
+			}
+		})
+
+		It("should return them sorted by a custom less function", func() {
+			byNameDescending := func(a, b *subv1.Endpoint) bool {
+				if a.Name != b.Name {
+					return a.Name > b.Name
+				}
+
+				return a.Spec.ClusterID < b.Spec.ClusterID
+			}
+
+			Expect(names(state.GetRemoteEndpointsSortedBy(byNameDescending))).To(Equal([]string{
+				"cluster-1/ep-c", "cluster-2/ep-b", "cluster-1/ep-a", "cluster-3/ep-a",
+			}))
+		})
+	})
+})
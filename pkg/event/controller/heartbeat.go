@@ -0,0 +1,47 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "time"
+
+// runHeartbeats notifies the handlers via OnHeartbeat at the given interval until the stop channel is closed.
+func (c *Controller) runHeartbeats(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.heartbeat()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *Controller) heartbeat() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	if err := c.handlers.Heartbeat(); err != nil {
+		c.eventLog.Error(err, "Error handling heartbeat")
+	}
+}
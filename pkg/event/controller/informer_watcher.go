@@ -0,0 +1,246 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type informerEventType int
+
+const (
+	informerCreate informerEventType = iota
+	informerUpdate
+	informerDelete
+)
+
+// informerEvent is queued for each notification from the shared informer. Each event is queued by pointer so requeues
+// of an event retain its identity and thus its requeue count.
+type informerEvent struct {
+	eventType informerEventType
+	obj       runtime.Object
+}
+
+// informerWatcher implements watcher.Interface for a single resource type on top of an informer obtained from an
+// externally supplied DynamicSharedInformerFactory, rather than an informer owned by the watcher.
+type informerWatcher struct {
+	factory        dynamicinformer.DynamicSharedInformerFactory
+	informer       cache.SharedIndexInformer
+	resourceConfig watcher.ResourceConfig
+	scheme         *runtime.Scheme
+	queue          workqueue.RateLimitingInterface
+	log            log.Logger
+}
+
+func newInformerWatcher(factory dynamicinformer.DynamicSharedInformerFactory, config *watcher.Config, logger log.Logger,
+) (*informerWatcher, error) {
+	resourceConfig := config.ResourceConfigs[0]
+
+	gvk, err := objectKind(config.Scheme, resourceConfig.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := config.RestMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error mapping %s", gvk)
+	}
+
+	objScheme := config.Scheme
+	if objScheme == nil {
+		objScheme = scheme.Scheme
+	}
+
+	w := &informerWatcher{
+		factory:        factory,
+		informer:       factory.ForResource(mapping.Resource).Informer(),
+		resourceConfig: resourceConfig,
+		scheme:         objScheme,
+		queue:          workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		log:            logger,
+	}
+
+	_, err = w.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.enqueue(informerCreate, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if w.equivalent(oldObj, newObj) {
+				return
+			}
+
+			w.enqueue(informerUpdate, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			w.enqueue(informerDelete, obj)
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error adding the %s informer event handler", gvk.Kind)
+	}
+
+	return w, nil
+}
+
+// Start starts the informer factory, waits for the informer cache to sync and starts processing its notifications. The
+// factory's other informers are also started, which is a no-op for those already running.
+func (w *informerWatcher) Start(stopCh <-chan struct{}) error {
+	w.factory.Start(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, w.informer.HasSynced) {
+		return errors.New("failed to wait for the informer cache to sync")
+	}
+
+	go wait.Until(w.processNextEvents, 0, stopCh)
+
+	go func() {
+		<-stopCh
+		w.queue.ShutDown()
+	}()
+
+	return nil
+}
+
+func (w *informerWatcher) ListResources(ofType runtime.Object, bySelector labels.Selector) []runtime.Object {
+	var objs []runtime.Object
+
+	for _, item := range w.informer.GetStore().List() {
+		obj := w.toResourceType(item, ofType)
+		if obj == nil || !w.inSourceNamespace(obj) {
+			continue
+		}
+
+		if bySelector != nil {
+			if accessor, ok := obj.(interface{ GetLabels() map[string]string }); ok &&
+				!bySelector.Matches(labels.Set(accessor.GetLabels())) {
+				continue
+			}
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs
+}
+
+func (w *informerWatcher) enqueue(eventType informerEventType, obj interface{}) {
+	converted := w.toResourceType(obj, w.resourceConfig.ResourceType)
+	if converted == nil || !w.inSourceNamespace(converted) {
+		return
+	}
+
+	w.queue.Add(&informerEvent{eventType: eventType, obj: converted})
+}
+
+func (w *informerWatcher) processNextEvents() {
+	for w.processNextEvent() {
+	}
+}
+
+func (w *informerWatcher) processNextEvent() bool {
+	item, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer w.queue.Done(item)
+
+	event := item.(*informerEvent)
+	numRequeues := w.queue.NumRequeues(item)
+
+	var requeue bool
+
+	switch event.eventType {
+	case informerCreate:
+		requeue = w.resourceConfig.Handler.OnCreate(event.obj, numRequeues)
+	case informerUpdate:
+		requeue = w.resourceConfig.Handler.OnUpdate(event.obj, numRequeues)
+	case informerDelete:
+		requeue = w.resourceConfig.Handler.OnDelete(event.obj, numRequeues)
+	}
+
+	if requeue {
+		w.queue.AddRateLimited(item)
+	} else {
+		w.queue.Forget(item)
+	}
+
+	return true
+}
+
+func (w *informerWatcher) equivalent(oldObj, newObj interface{}) bool {
+	if w.resourceConfig.ResourcesEquivalent == nil {
+		return false
+	}
+
+	oldUnstructured, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	newUnstructured, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	return w.resourceConfig.ResourcesEquivalent(oldUnstructured, newUnstructured)
+}
+
+// toResourceType converts the given unstructured object from the informer to a new instance of the given type. Nil is
+// returned if the object isn't unstructured or can't be converted.
+func (w *informerWatcher) toResourceType(obj interface{}, ofType runtime.Object) runtime.Object {
+	from, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	to := ofType.DeepCopyObject()
+	if err := w.scheme.Convert(from, to, nil); err != nil {
+		w.log.Errorf(err, "Error converting %s %q", from.GetKind(), from.GetName())
+		return nil
+	}
+
+	return to
+}
+
+// inSourceNamespace returns whether or not the given object is in the configured source namespace, as the factory's
+// informers may watch all namespaces.
+func (w *informerWatcher) inSourceNamespace(obj runtime.Object) bool {
+	if w.resourceConfig.SourceNamespace == "" {
+		return true
+	}
+
+	accessor, ok := obj.(interface{ GetNamespace() string })
+
+	return !ok || accessor.GetNamespace() == w.resourceConfig.SourceNamespace
+}
@@ -0,0 +1,222 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// initialSync tracks the Endpoints received during the initial sync when BulkInitialEndpoints is configured. It's guarded
+// by the controller's syncMutex.
+type initialSync struct {
+	done   bool
+	local  map[string]*smv1.Endpoint
+	remote map[string]*smv1.Endpoint
+	// pending holds the resource versions of the Endpoints that were notified in bulk but whose create events were still
+	// queued at that time, so those events can be ignored.
+	pending map[string]string
+	// retries tracks the failed attempts to notify the Endpoints in bulk.
+	retries workqueue.RateLimiter
+}
+
+func newInitialSync() *initialSync {
+	return &initialSync{
+		local:   map[string]*smv1.Endpoint{},
+		remote:  map[string]*smv1.Endpoint{},
+		pending: map[string]string{},
+		retries: workqueue.DefaultControllerRateLimiter(),
+	}
+}
+
+// deferInitialEndpointEvent returns true if the given Endpoint event is part of the initial sync and thus deferred until
+// the bulk notification, in which case only the controller's state is updated. Must be called with the syncMutex held.
+func (c *Controller) deferInitialEndpointEvent(eventType event.Type, endpoint *smv1.Endpoint) bool {
+	s := c.initialSync
+	if s == nil {
+		return false
+	}
+
+	if s.done {
+		if eventType != event.LocalEndpointCreated && eventType != event.RemoteEndpointCreated {
+			return false
+		}
+
+		version, found := s.pending[endpoint.Name]
+		delete(s.pending, endpoint.Name)
+
+		return found && version == endpoint.ResourceVersion
+	}
+
+	switch eventType {
+	case event.LocalEndpointCreated, event.RemoteEndpointCreated:
+		c.trackInitialEndpoint(eventType == event.LocalEndpointCreated, endpoint)
+	case event.LocalEndpointUpdated, event.RemoteEndpointUpdated:
+		if !s.isTracked(c.endpointIdentity(endpoint), endpoint) {
+			return false
+		}
+
+		c.trackInitialEndpoint(eventType == event.LocalEndpointUpdated, endpoint)
+	case event.LocalEndpointRemoved, event.RemoteEndpointRemoved:
+		if !s.isTracked(c.endpointIdentity(endpoint), endpoint) {
+			return false
+		}
+
+		c.untrackInitialEndpoint(endpoint)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (s *initialSync) isTracked(id string, endpoint *smv1.Endpoint) bool {
+	_, local := s.local[endpoint.Name]
+	remote, found := s.remote[id]
+
+	return local || (found && remote.Name == endpoint.Name)
+}
+
+func (c *Controller) trackInitialEndpoint(isLocal bool, endpoint *smv1.Endpoint) {
+	if isLocal {
+		if c.isLocalHostEndpoint(endpoint) && c.isLocalNodeGatewayEligible() {
+			c.handlerState.setIsOnGateway(true)
+		}
+
+		c.localEndpoints[endpoint.Name] = endpoint
+		c.initialSync.local[endpoint.Name] = endpoint
+	} else {
+		id := c.endpointIdentity(endpoint)
+		c.handlerState.remoteEndpoints.Store(id, endpoint)
+		c.initialSync.remote[id] = endpoint
+	}
+}
+
+func (c *Controller) untrackInitialEndpoint(endpoint *smv1.Endpoint) {
+	if _, isLocal := c.initialSync.local[endpoint.Name]; isLocal {
+		if c.isLocalHostEndpoint(endpoint) {
+			c.handlerState.setIsOnGateway(false)
+		}
+
+		delete(c.localEndpoints, endpoint.Name)
+		delete(c.initialSync.local, endpoint.Name)
+	} else {
+		id := c.endpointIdentity(endpoint)
+		c.handlerState.remoteEndpoints.Delete(id)
+		delete(c.initialSync.remote, id)
+	}
+}
+
+// completeInitialSync notifies the handlers of the Endpoints received during the initial sync, including those whose
+// create events are still queued, and resumes the individual notification of Endpoint events. If the handlers fail, the
+// Endpoint events remain deferred and the bulk notification is retried with backoff.
+func (c *Controller) completeInitialSync() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	s := c.initialSync
+	if s == nil || s.done {
+		return
+	}
+
+	if state := c.lifecycle.get(); state == LifecycleStopping || state == LifecycleStopped {
+		return
+	}
+
+	defer c.beginEvent()()
+
+	for _, obj := range c.listResources(EndpointResource, &smv1.Endpoint{}) {
+		endpoint := obj.(*smv1.Endpoint)
+
+		eventType := event.LocalEndpointCreated
+		if endpoint.Spec.ClusterID != c.env.ClusterID {
+			eventType = event.RemoteEndpointCreated
+		}
+
+		// Skip the Endpoints already tracked, including remote Endpoints superseded by another with the same identity.
+		if s.isTracked(c.endpointIdentity(endpoint), endpoint) ||
+			(eventType == event.RemoteEndpointCreated && c.isDuplicateRemoteEndpoint(endpoint)) {
+			continue
+		}
+
+		// Invalid Endpoints are rejected once their queued create events are handled.
+		if c.endpointValidator(endpoint) == nil && c.shouldDispatch(eventType, endpoint) {
+			c.trackInitialEndpoint(eventType == event.LocalEndpointCreated, endpoint)
+			s.pending[endpoint.Name] = endpoint.ResourceVersion
+		}
+	}
+
+	local, remote := sortedEndpoints(s.local), sortedEndpoints(s.remote)
+
+	c.eventLog.Infof("Initial sync complete with %d local and %d remote Endpoints", len(local), len(remote))
+
+	err := c.handlers.InitialEndpoints(local, remote)
+
+	if err == nil && !c.handlerState.wasOnGateway.Load() && c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Transitioned to gateway node %q", c.hostname)
+
+		err = c.transitionToGateway()
+	}
+
+	if err != nil {
+		c.eventLog.Error(err, "Error handling the initial Endpoints")
+
+		// As for the buffered events, the retry is run in its own goroutine in case the clock invokes the function with its
+		// own lock held.
+		c.clock.AfterFunc(s.retries.When(EndpointResource), func() {
+			go c.completeInitialSync()
+		})
+
+		return
+	}
+
+	s.retries.Forget(EndpointResource)
+	s.done = true
+	s.local, s.remote = nil, nil
+
+	c.handlerState.wasOnGateway.Store(c.handlerState.IsOnGateway())
+
+	for i := range remote {
+		for j := 0; j < i; j++ {
+			c.notifySubnetConflicts(remote[i], remote[j])
+		}
+
+		c.batchRemoteEndpoint(event.RemoteEndpointCreated, remote[i])
+
+		if err := c.updatePreferredGateway(remote[i], false); err != nil {
+			c.eventLog.Error(err, "Error handling the preferred gateway Endpoint")
+		}
+	}
+}
+
+func sortedEndpoints(endpoints map[string]*smv1.Endpoint) []*smv1.Endpoint {
+	sorted := make([]*smv1.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		sorted = append(sorted, endpoint)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
@@ -0,0 +1,252 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// keyedQueue queues the events delivered by the watchers by the key derived via a key function and dispatches them from
+// a single worker. Events with the same key are dispatched in order, one at a time. An event queued while another
+// with the same key is still pending is merged with it: a pending create followed by an update remains a create carrying
+// the latest object, a pending create followed by a delete cancels out and a pending event of the same type is
+// superseded. If bounded, queuing an event with a new key blocks the watcher delivering it while the maximum number of
+// keys have pending events, thereby slowing the intake of watch events.
+type keyedQueue struct {
+	keyFunc    func(obj runtime.Object) string
+	queue      workqueue.RateLimitingInterface
+	mutex      sync.Mutex
+	pending    map[string][]*keyedEvent
+	maxPending int
+	// spaceAvailable is signaled when a pending event is dequeued or the queue is shut down.
+	spaceAvailable *sync.Cond
+	shutDown       bool
+	// onSuperseded if set, is invoked with the object of a pending event that's superseded, ie won't be dispatched.
+	onSuperseded func(obj runtime.Object)
+}
+
+type keyedEvent struct {
+	// operation is one of CreateOperation, UpdateOperation or DeleteOperation.
+	operation string
+	obj       runtime.Object
+	dispatch  func(obj runtime.Object, numRequeues int) bool
+}
+
+func newKeyedQueue(keyFunc func(obj runtime.Object) string, maxPending int) *keyedQueue {
+	if keyFunc == nil {
+		keyFunc = objectKey
+	}
+
+	q := &keyedQueue{
+		keyFunc:    keyFunc,
+		queue:      workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		pending:    map[string][]*keyedEvent{},
+		maxPending: maxPending,
+	}
+
+	q.spaceAvailable = sync.NewCond(&q.mutex)
+
+	return q
+}
+
+// objectKey is the default key function which identifies an object by its type and namespace/name.
+func objectKey(obj runtime.Object) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+
+	return fmt.Sprintf("%T/%s", obj, key)
+}
+
+// queuingHandler wraps the given watcher event handler to queue each notified object rather than dispatching it
+// directly.
+func (q *keyedQueue) queuingHandler(handler watcher.EventHandler) watcher.EventHandler {
+	queuing := func(operation string, f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, _ int) bool {
+			q.add(&keyedEvent{operation: operation, obj: obj, dispatch: f})
+			return false
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: queuing(CreateOperation, handler.OnCreate),
+		OnUpdateFunc: queuing(UpdateOperation, handler.OnUpdate),
+		OnDeleteFunc: queuing(DeleteOperation, handler.OnDelete),
+	}
+}
+
+func (q *keyedQueue) add(e *keyedEvent) {
+	key := q.keyFunc(e.obj)
+
+	q.mutex.Lock()
+
+	for q.maxPending > 0 && len(q.pending) >= q.maxPending && q.pending[key] == nil && !q.shutDown {
+		q.spaceAvailable.Wait()
+	}
+
+	q.setPending(key, mergeEvent(q.pending[key], e, q.superseded))
+	q.mutex.Unlock()
+
+	q.queue.Add(key)
+}
+
+// mergeEvent returns the given pending events for an object with the given subsequent event merged into the last one, if
+// possible, otherwise appended. The given superseded function is invoked with the object of each event that's merged away.
+func mergeEvent(pending []*keyedEvent, e *keyedEvent, superseded func(obj runtime.Object)) []*keyedEvent {
+	if len(pending) == 0 {
+		return append(pending, e)
+	}
+
+	last := pending[len(pending)-1]
+
+	switch {
+	case last.operation == CreateOperation && e.operation == UpdateOperation:
+		superseded(last.obj)
+
+		pending[len(pending)-1] = &keyedEvent{operation: CreateOperation, obj: e.obj, dispatch: last.dispatch}
+	case last.operation == CreateOperation && e.operation == DeleteOperation:
+		superseded(last.obj)
+		superseded(e.obj)
+
+		pending = pending[:len(pending)-1]
+	case last.operation == e.operation:
+		superseded(last.obj)
+
+		pending[len(pending)-1] = e
+	default:
+		pending = append(pending, e)
+	}
+
+	return pending
+}
+
+func (q *keyedQueue) superseded(obj runtime.Object) {
+	if q.onSuperseded != nil {
+		q.onSuperseded(obj)
+	}
+}
+
+// setPending sets the pending events for the given key. Must be called with the mutex held.
+func (q *keyedQueue) setPending(key string, pending []*keyedEvent) {
+	if len(pending) > 0 {
+		q.pending[key] = pending
+		return
+	}
+
+	delete(q.pending, key)
+	q.spaceAvailable.Broadcast()
+}
+
+// len returns the number of pending events.
+func (q *keyedQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n := 0
+	for _, pending := range q.pending {
+		n += len(pending)
+	}
+
+	return n
+}
+
+func (q *keyedQueue) run(stopCh <-chan struct{}) {
+	go func() {
+		<-stopCh
+
+		q.mutex.Lock()
+		q.shutDown = true
+		q.spaceAvailable.Broadcast()
+		q.mutex.Unlock()
+
+		q.queue.ShutDown()
+	}()
+
+	go func() {
+		for q.processNext() {
+		}
+	}()
+}
+
+func (q *keyedQueue) processNext() bool {
+	item, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer q.queue.Done(item)
+
+	key := item.(string)
+
+	q.mutex.Lock()
+
+	pending := q.pending[key]
+	if len(pending) == 0 {
+		q.mutex.Unlock()
+		return true
+	}
+
+	e := pending[0]
+	q.setPending(key, pending[1:])
+	q.mutex.Unlock()
+
+	if !e.dispatch(e.obj, q.queue.NumRequeues(key)) {
+		q.queue.Forget(key)
+
+		// The work queue re-adds the key once it's done if events were queued during the dispatch but not if they were
+		// already pending before it.
+		if q.hasPending(key) {
+			q.queue.Add(key)
+		}
+
+		return true
+	}
+
+	// The failed event is put back in front of the events queued since, with which it's merged. The bound doesn't apply
+	// to requeued events as the worker mustn't block.
+	q.mutex.Lock()
+
+	requeued := []*keyedEvent{e}
+	for _, subsequent := range q.pending[key] {
+		requeued = mergeEvent(requeued, subsequent, q.superseded)
+	}
+
+	q.setPending(key, requeued)
+	q.mutex.Unlock()
+
+	if len(requeued) == 0 {
+		q.queue.Forget(key)
+		return true
+	}
+
+	q.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (q *keyedQueue) hasPending(key string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.pending[key]) > 0
+}
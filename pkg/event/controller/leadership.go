@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SetLeader tells the controller its leadership status, eg from the callbacks of a leader elector. If it changed, the
+// handlers implementing event.LeadershipHandler are notified via OnLeadershipChanged. If SuppressEventsWhileNotLeader is
+// set, the events of the watched resources are buffered while it isn't the leader and the buffered events are
+// dispatched, after the handlers are notified, once it becomes the leader. As with ResumeWatcher, failed buffered events
+// are retried with backoff. Must not be called from a Handler.
+func (c *Controller) SetLeader(isLeader bool) error {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	if isLeader == c.isLeader {
+		return nil
+	}
+
+	c.isLeader = isLeader
+
+	if isLeader {
+		c.log.Info("The controller is now the leader")
+	} else {
+		c.log.Info("The controller is no longer the leader")
+
+		c.setWatchersGated(true)
+	}
+
+	err := c.notifyLeadershipChanged(isLeader)
+
+	if isLeader {
+		c.setWatchersGated(false)
+	}
+
+	return err
+}
+
+func (c *Controller) notifyLeadershipChanged(isLeader bool) error {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	return errors.Wrap(c.handlers.LeadershipChanged(isLeader), "error handling the leadership change")
+}
+
+// setWatchersGated gates or ungates the events of the watchers if SuppressEventsWhileNotLeader is set. Once ungated, the
+// events buffered by the watchers that aren't paused are dispatched and those that fail are retried. Must be called with
+// the leaderMutex held.
+func (c *Controller) setWatchersGated(gated bool) {
+	if !c.gateOnLeadership {
+		return
+	}
+
+	for _, w := range c.resourceWatchers {
+		w.pauseMutex.Lock()
+
+		w.gated = gated
+
+		if !gated && !w.paused {
+			if n := w.buffered.len(); n > 0 {
+				c.log.Infof("Dispatching %d events of the %s watcher%s buffered while not the leader", n, w.resource,
+					forCluster(w.cluster))
+			}
+
+			c.dispatchBuffered(w)
+		}
+
+		w.pauseMutex.Unlock()
+	}
+}
+
+// isGatedOnLeadership returns whether the events of a new watcher are initially gated, ie SuppressEventsWhileNotLeader is
+// set and the controller isn't the leader.
+func (c *Controller) isGatedOnLeadership() bool {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	return c.gateOnLeadership && !c.isLeader
+}
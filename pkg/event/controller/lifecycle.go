@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "sync"
+
+// LifecycleState is the state of a Controller in its lifecycle.
+type LifecycleState string
+
+const (
+	// LifecycleNew indicates the Controller was created but not yet started.
+	LifecycleNew LifecycleState = "New"
+
+	// LifecycleStarting indicates Start is in progress.
+	LifecycleStarting LifecycleState = "Starting"
+
+	// LifecycleRunning indicates the Controller was started successfully and is dispatching events.
+	LifecycleRunning LifecycleState = "Running"
+
+	// LifecycleStopping indicates Stop is in progress.
+	LifecycleStopping LifecycleState = "Stopping"
+
+	// LifecycleStopped indicates the Controller was stopped or failed to start. It can't be restarted.
+	LifecycleStopped LifecycleState = "Stopped"
+)
+
+// lifecycle is the state machine governing the transitions of a Controller through its LifecycleStates. It's safe for
+// concurrent use.
+type lifecycle struct {
+	mutex sync.Mutex
+	state LifecycleState
+	// stopInvoked indicates whether Stop was invoked, which may only proceed once.
+	stopInvoked bool
+}
+
+// State returns the current lifecycle state of the controller, eg for supervisors to determine whether it's running.
+func (c *Controller) State() LifecycleState {
+	return c.lifecycle.get()
+}
+
+func (l *lifecycle) get() LifecycleState {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.state == "" {
+		return LifecycleNew
+	}
+
+	return l.state
+}
+
+// transition moves to the given state if the current state is the given from state and returns whether it did.
+func (l *lifecycle) transition(from, to LifecycleState) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	current := l.state
+	if current == "" {
+		current = LifecycleNew
+	}
+
+	if current != from {
+		return false
+	}
+
+	l.state = to
+
+	return true
+}
+
+// beginStop moves to LifecycleStopping and returns true on the first invocation, ie false if Stop was already invoked.
+func (l *lifecycle) beginStop() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.stopInvoked {
+		return false
+	}
+
+	l.stopInvoked = true
+	l.state = LifecycleStopping
+
+	return true
+}
@@ -0,0 +1,268 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	clusterIDLabel = "cluster_id"
+	eventTypeLabel = "event_type"
+	resourceLabel  = "resource"
+
+	// UnknownClusterID is the cluster ID with which remote Endpoint events are counted if their cluster isn't known, ie
+	// it has no cached Endpoints, so the cardinality of the per-cluster metrics is bounded by the known clusters.
+	UnknownClusterID = "unknown"
+)
+
+var remoteEndpointEventsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "submariner_event_remote_endpoint_events_total",
+		Help: "Number of remote Endpoint events handled (by remote cluster ID and event type)",
+	},
+	[]string{
+		clusterIDLabel,
+		eventTypeLabel,
+	},
+)
+
+var invalidEndpointsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "submariner_event_invalid_endpoints_total",
+		Help: "Number of Endpoint events rejected by the EndpointValidator (by event type)",
+	},
+	[]string{
+		eventTypeLabel,
+	},
+)
+
+var eventQueueWaitHistogram = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "submariner_event_queue_wait_duration_seconds",
+		Help:    "Time watched resource events waited from their receipt until their dispatch began (by resource type)",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{
+		resourceLabel,
+	},
+)
+
+var eventProcessingHistogram = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "submariner_event_processing_duration_seconds",
+		Help:    "Time spent processing watched resource events once dispatched (by resource type)",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{
+		resourceLabel,
+	},
+)
+
+var gatewayTransitionHistogram = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "submariner_event_gateway_transition_duration_seconds",
+		Help:    "Time from the start of a transition of the local node to a gateway until the last handler completed it",
+		Buckets: prometheus.DefBuckets,
+	},
+)
+
+func init() {
+	prometheus.MustRegister(remoteEndpointEventsCounter, invalidEndpointsCounter, eventQueueWaitHistogram, eventProcessingHistogram,
+		gatewayTransitionHistogram)
+}
+
+// MetricsSnapshot is a point-in-time copy of the controller's counters, eg for custom exporters.
+type MetricsSnapshot struct {
+	// EventsProcessed is the number of watched resource events that were handled successfully.
+	EventsProcessed uint64
+
+	// EventErrors is the number of failed attempts to handle watched resource events.
+	EventErrors uint64
+
+	// Retries is the number of attempts to handle watched resource events that had previously failed.
+	Retries uint64
+
+	// GatewayTransitions is the number of successful transitions of the local node to a gateway.
+	GatewayTransitions uint64
+
+	// NonGatewayTransitions is the number of successful transitions of the local node to a non-gateway.
+	NonGatewayTransitions uint64
+
+	// StalledEvents is the number of events detected by the watchdog as in flight for longer than the
+	// StalledEventThreshold.
+	StalledEvents uint64
+
+	// InvalidEndpoints is the number of Endpoint events rejected by the EndpointValidator.
+	InvalidEndpoints uint64
+
+	// RemoteEndpointEvents is the number of remote Endpoint events handled, including retries, keyed by remote cluster ID
+	// and event type. Events for clusters that aren't known are counted under UnknownClusterID and the counts for a
+	// cluster are discarded once it has no remaining Endpoints.
+	RemoteEndpointEvents map[string]map[event.Type]uint64
+}
+
+type metrics struct {
+	eventsProcessed       atomic.Uint64
+	eventErrors           atomic.Uint64
+	retries               atomic.Uint64
+	gatewayTransitions    atomic.Uint64
+	nonGatewayTransitions atomic.Uint64
+	stalledEvents         atomic.Uint64
+	invalidEndpoints      atomic.Uint64
+
+	mutex                sync.Mutex
+	remoteEndpointEvents map[string]map[event.Type]uint64
+
+	// received holds the time each received event's object, pending dispatch, was received from its watcher.
+	received sync.Map
+}
+
+// recordEvent records an attempt to handle a watched resource event.
+func (m *metrics) recordEvent(retried, failed bool) {
+	if retried {
+		m.retries.Add(1)
+	}
+
+	if failed {
+		m.eventErrors.Add(1)
+	} else {
+		m.eventsProcessed.Add(1)
+	}
+}
+
+// recordReceived records the time the given event object was received from its watcher in order to observe its queue
+// wait once dispatched.
+func (m *metrics) recordReceived(obj runtime.Object, at time.Time) {
+	m.received.Store(obj, at)
+}
+
+// forgetReceived discards the receipt time of the given event object if it won't be dispatched, eg it was superseded.
+func (m *metrics) forgetReceived(obj runtime.Object) {
+	m.received.Delete(obj)
+}
+
+// forgetAllReceived discards the receipt times of all the event objects, eg once stopped as the events still buffered or
+// queued won't be dispatched.
+func (m *metrics) forgetAllReceived() {
+	m.received.Range(func(obj, _ any) bool {
+		m.received.Delete(obj)
+		return true
+	})
+}
+
+// recordDispatched observes the time the given event object waited from its receipt until its dispatch began at the given
+// time. Events not received from a watcher, eg replayed or requeued by the keyed queue, aren't observed.
+func (m *metrics) recordDispatched(resource string, obj runtime.Object, at time.Time) {
+	if received, found := m.received.LoadAndDelete(obj); found {
+		eventQueueWaitHistogram.With(prometheus.Labels{resourceLabel: resource}).Observe(at.Sub(received.(time.Time)).Seconds())
+	}
+}
+
+// recordProcessing observes the time spent processing a dispatched event.
+func (m *metrics) recordProcessing(resource string, duration time.Duration) {
+	eventProcessingHistogram.With(prometheus.Labels{resourceLabel: resource}).Observe(duration.Seconds())
+}
+
+// recordGatewayTransition records a successful transition of the local node to a gateway that took the given time for
+// all the handlers to complete.
+func (m *metrics) recordGatewayTransition(latency time.Duration) {
+	m.gatewayTransitions.Add(1)
+	gatewayTransitionHistogram.Observe(latency.Seconds())
+}
+
+// recordInvalidEndpoint records an Endpoint event rejected by the EndpointValidator.
+func (m *metrics) recordInvalidEndpoint(eventType event.Type) {
+	m.invalidEndpoints.Add(1)
+	invalidEndpointsCounter.WithLabelValues(string(eventType)).Inc()
+}
+
+// recordRemoteEndpointEvent records an attempt to handle a remote Endpoint event for the given cluster. If the cluster
+// isn't known, the event is counted under UnknownClusterID. The counts for a cluster are removed via forgetCluster.
+func (m *metrics) recordRemoteEndpointEvent(eventType event.Type, clusterID string, known bool) {
+	if !known {
+		clusterID = UnknownClusterID
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.remoteEndpointEvents == nil {
+		m.remoteEndpointEvents = map[string]map[event.Type]uint64{}
+	}
+
+	if m.remoteEndpointEvents[clusterID] == nil {
+		m.remoteEndpointEvents[clusterID] = map[event.Type]uint64{}
+	}
+
+	m.remoteEndpointEvents[clusterID][eventType]++
+
+	remoteEndpointEventsCounter.With(prometheus.Labels{clusterIDLabel: clusterID, eventTypeLabel: string(eventType)}).Inc()
+}
+
+// forgetCluster removes the remote Endpoint event counts for the given cluster once it's no longer known.
+func (m *metrics) forgetCluster(clusterID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.remoteEndpointEvents, clusterID)
+
+	remoteEndpointEventsCounter.DeletePartialMatch(prometheus.Labels{clusterIDLabel: clusterID})
+}
+
+func (m *metrics) remoteEndpointEventsSnapshot() map[string]map[event.Type]uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.remoteEndpointEvents) == 0 {
+		return nil
+	}
+
+	snapshot := make(map[string]map[event.Type]uint64, len(m.remoteEndpointEvents))
+
+	for clusterID, counts := range m.remoteEndpointEvents {
+		snapshot[clusterID] = make(map[event.Type]uint64, len(counts))
+
+		for eventType, count := range counts {
+			snapshot[clusterID][eventType] = count
+		}
+	}
+
+	return snapshot
+}
+
+// Metrics returns a snapshot of the controller's counters.
+func (c *Controller) Metrics() MetricsSnapshot {
+	return MetricsSnapshot{
+		EventsProcessed:       c.metrics.eventsProcessed.Load(),
+		EventErrors:           c.metrics.eventErrors.Load(),
+		Retries:               c.metrics.retries.Load(),
+		GatewayTransitions:    c.metrics.gatewayTransitions.Load(),
+		NonGatewayTransitions: c.metrics.nonGatewayTransitions.Load(),
+		StalledEvents:         c.metrics.stalledEvents.Load(),
+		InvalidEndpoints:      c.metrics.invalidEndpoints.Load(),
+		RemoteEndpointEvents:  c.metrics.remoteEndpointEventsSnapshot(),
+	}
+}
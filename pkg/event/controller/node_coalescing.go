@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/submariner-io/admiral/pkg/log"
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// coalesceNodeUpdate defers the dispatch of the given updated Node until the NodeUpdateCoalescingWindow elapses. If an
+// update for the Node is already pending, it's superseded by the given Node so only the latest is dispatched. Must be
+// called with the syncMutex held.
+func (c *Controller) coalesceNodeUpdate(node *k8sv1.Node) {
+	_, pending := c.pendingNodeUpdates[node.Name]
+	c.pendingNodeUpdates[node.Name] = node
+
+	if pending {
+		c.eventLog.V(log.DEBUG).Infof("Coalescing update for Node %q", node.Name)
+		return
+	}
+
+	c.clock.AfterFunc(c.nodeUpdateWindow, func() {
+		c.flushNodeUpdate(node.Name)
+	})
+}
+
+// flushNodeUpdate dispatches the pending update for the given Node, if any.
+func (c *Controller) flushNodeUpdate(name string) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	node, pending := c.pendingNodeUpdates[name]
+	if !pending {
+		return
+	}
+
+	delete(c.pendingNodeUpdates, name)
+
+	if err := c.dispatchUpdatedNode(node); err != nil {
+		c.eventLog.Error(err, "Error handling coalesced Node update")
+	}
+}
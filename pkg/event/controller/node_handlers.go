@@ -19,19 +19,34 @@ limitations under the License.
 package controller
 
 import (
+	"github.com/pkg/errors"
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// GatewayLabel is the label set to "true" on the Nodes eligible to be gateways.
+const GatewayLabel = "submariner.io/gateway"
+
 func (c *Controller) handleRemovedNode(obj runtime.Object, _ int) bool {
 	node := obj.(*k8sv1.Node)
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	delete(c.pendingNodeUpdates, node.Name)
+	c.handlerState.nodes.remove(node.Name)
+
+	if !c.shouldDispatch(event.NodeRemoved, node) {
+		return false
+	}
 
 	if err := c.handlers.NodeRemoved(node); err != nil {
-		logger.Error(err, "Error handling removed Node")
+		c.eventLog.Error(err, "Error handling removed Node")
+		return true
+	}
+
+	if err := c.updateSoleGateway(); err != nil {
+		c.eventLog.Error(err, "Error updating the sole gateway state")
 		return true
 	}
 
@@ -40,12 +55,24 @@ func (c *Controller) handleRemovedNode(obj runtime.Object, _ int) bool {
 
 func (c *Controller) handleCreatedNode(obj runtime.Object, _ int) bool {
 	node := obj.(*k8sv1.Node)
+	c.handlerState.nodes.set(node)
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	if !c.shouldDispatch(event.NodeCreated, node) {
+		return false
+	}
 
 	if err := c.handlers.NodeCreated(node); err != nil {
-		logger.Error(err, "Error handling created Node")
+		c.eventLog.Error(err, "Error handling created Node")
+		return true
+	}
+
+	if err := c.updateGatewayEligibility(node); err != nil {
+		c.eventLog.Error(err, "Error updating the gateway eligibility")
+		return true
+	}
+
+	if err := c.updateSoleGateway(); err != nil {
+		c.eventLog.Error(err, "Error updating the sole gateway state")
 		return true
 	}
 
@@ -54,19 +81,203 @@ func (c *Controller) handleCreatedNode(obj runtime.Object, _ int) bool {
 
 func (c *Controller) handleUpdatedNode(obj runtime.Object, _ int) bool {
 	node := obj.(*k8sv1.Node)
+	c.handlerState.nodes.set(node)
 
-	c.syncMutex.Lock()
-	defer c.syncMutex.Unlock()
+	if !c.shouldDispatch(event.NodeUpdated, node) {
+		return false
+	}
 
-	if err := c.handlers.NodeUpdated(node); err != nil {
-		logger.Error(err, "Error handling updated Node")
+	if c.nodeUpdateWindow > 0 {
+		c.coalesceNodeUpdate(node)
+		return false
+	}
+
+	if err := c.dispatchUpdatedNode(node); err != nil {
+		c.eventLog.Error(err, "Error handling updated Node")
 		return true
 	}
 
 	return false
 }
 
+func (c *Controller) dispatchUpdatedNode(node *k8sv1.Node) error {
+	if err := c.handlers.NodeUpdated(node); err != nil {
+		return err
+	}
+
+	if err := c.updateGatewayEligibility(node); err != nil {
+		return errors.Wrap(err, "error updating the gateway eligibility")
+	}
+
+	return c.updateSoleGateway()
+}
+
 func (c *Controller) isNodeEquivalent(_, _ *unstructured.Unstructured) bool {
 	// TODO: filter on changes for labels, annotations, podcidr, podcidrs, addresses
 	return false
 }
+
+// isGatewayEligible returns whether the given Node doesn't carry the configured IneligibleGatewayTaint.
+func (c *Controller) isGatewayEligible(node *k8sv1.Node) bool {
+	if c.gatewayTaint == "" {
+		return true
+	}
+
+	for i := range node.Spec.Taints {
+		if node.Spec.Taints[i].Key == c.gatewayTaint {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLocalNode returns whether the given Node is the local Node on which the controller runs, as opposed to the other
+// gateway candidates.
+func (c *Controller) isLocalNode(node *k8sv1.Node) bool {
+	return node.Name == c.hostname
+}
+
+func (c *Controller) isLocalNodeGatewayEligible() bool {
+	if c.gatewayTaint == "" {
+		return true
+	}
+
+	for _, obj := range c.listResources(NodeResource, &k8sv1.Node{}) {
+		if node := obj.(*k8sv1.Node); c.isLocalNode(node) {
+			return c.isGatewayEligible(node)
+		}
+	}
+
+	return true
+}
+
+// updateGatewayEligibility re-evaluates the gateway state if the given Node is the local Node and its eligibility to be
+// a gateway changed. If it's tainted as ineligible, it transitions to non-gateway. If the taint is removed and one of the
+// local Endpoints belongs to this node, it transitions back to gateway.
+func (c *Controller) updateGatewayEligibility(node *k8sv1.Node) error {
+	if c.gatewayTaint == "" || !c.isLocalNode(node) {
+		return nil
+	}
+
+	eligible := c.isGatewayEligible(node)
+
+	if !eligible {
+		c.handlerState.setIsOnGateway(false)
+	} else if !c.handlerState.IsOnGateway() && c.hasLocalHostEndpoint() {
+		c.handlerState.setIsOnGateway(true)
+	}
+
+	var err error
+
+	if !c.handlerState.wasOnGateway.Load() && c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Node %q is no longer tainted as gateway-ineligible - transitioned to gateway node", c.hostname)
+
+		err = c.transitionToGateway()
+	} else if c.handlerState.wasOnGateway.Load() && !c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Node %q is tainted as gateway-ineligible - transitioned to non-gateway node", c.hostname)
+
+		err = c.transitionToNonGateway()
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error handling the gateway eligibility change")
+	}
+
+	c.handlerState.wasOnGateway.Store(c.handlerState.IsOnGateway())
+
+	return nil
+}
+
+func (c *Controller) hasLocalHostEndpoint() bool {
+	for _, endpoint := range c.localEndpoints {
+		if c.isLocalHostEndpoint(endpoint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLocalHostEndpoint returns whether the given local Endpoint belongs to the gateway running on this node, as per the
+// configured PreferredNodeAddressType.
+func (c *Controller) isLocalHostEndpoint(endpoint *smv1.Endpoint) bool {
+	if c.nodeAddrType != "" {
+		if address, found := c.localNodeAddress(c.nodeAddrType); found {
+			if c.nodeAddrType == k8sv1.NodeHostName {
+				return endpoint.Spec.Hostname == address
+			}
+
+			return endpoint.Spec.PrivateIP == address
+		}
+	}
+
+	return endpoint.Spec.Hostname == c.hostname
+}
+
+func (c *Controller) localNodeAddress(addrType k8sv1.NodeAddressType) (string, bool) {
+	for _, obj := range c.listResources(NodeResource, &k8sv1.Node{}) {
+		if node := obj.(*k8sv1.Node); c.isLocalNode(node) {
+			return nodeAddress(node, addrType)
+		}
+	}
+
+	return "", false
+}
+
+// nodeAddress returns the first address of the given type in the Node's status.
+func nodeAddress(node *k8sv1.Node, addrType k8sv1.NodeAddressType) (string, bool) {
+	for i := range node.Status.Addresses {
+		if node.Status.Addresses[i].Type == addrType {
+			return node.Status.Addresses[i].Address, true
+		}
+	}
+
+	return "", false
+}
+
+// RefreshGatewayState re-evaluates whether the local node is a gateway, ie the cached local Node has the GatewayLabel and
+// one of the local Endpoints belongs to this node, and, if it differs from the current state, notifies the handlers of the
+// transition. This is useful to recover from failure modes where the current state lags reality.
+func (c *Controller) RefreshGatewayState() error {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	var localNode *k8sv1.Node
+
+	for _, obj := range c.listResources(NodeResource, &k8sv1.Node{}) {
+		if node := obj.(*k8sv1.Node); c.isLocalNode(node) {
+			localNode = node
+			break
+		}
+	}
+
+	if localNode == nil {
+		return errors.Errorf("the local Node %q was not found", c.hostname)
+	}
+
+	c.handlerState.setIsOnGateway(localNode.Labels[GatewayLabel] == "true" && c.isGatewayEligible(localNode) &&
+		c.hasLocalHostEndpoint())
+
+	var err error
+
+	if !c.handlerState.wasOnGateway.Load() && c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Refreshed state - transitioned to gateway node %q", c.hostname)
+
+		err = c.transitionToGateway()
+	} else if c.handlerState.wasOnGateway.Load() && !c.handlerState.IsOnGateway() {
+		c.eventLog.Infof("Refreshed state - transitioned to non-gateway node %q", c.hostname)
+
+		err = c.transitionToNonGateway()
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error refreshing the gateway state")
+	}
+
+	c.handlerState.wasOnGateway.Store(c.handlerState.IsOnGateway())
+
+	return nil
+}
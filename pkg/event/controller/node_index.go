@@ -0,0 +1,93 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// nodeIndex indexes the watched Nodes by hostname, ie their name and their addresses of type Hostname, for O(1) lookup
+// of the Node referenced by an Endpoint's hostname. It's updated as the Node events are received, regardless of whether
+// they're dispatched to the handlers.
+type nodeIndex struct {
+	mutex      sync.RWMutex
+	byHostname map[string]*k8sv1.Node
+	// hostnames are the hostnames under which each Node, by name, is indexed.
+	hostnames map[string][]string
+}
+
+// set indexes the given Node, replacing its previous version.
+func (i *nodeIndex) set(node *k8sv1.Node) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.removeLocked(node.Name)
+
+	if i.byHostname == nil {
+		i.byHostname = map[string]*k8sv1.Node{}
+		i.hostnames = map[string][]string{}
+	}
+
+	hostnames := []string{node.Name}
+
+	for j := range node.Status.Addresses {
+		if node.Status.Addresses[j].Type == k8sv1.NodeHostName && node.Status.Addresses[j].Address != node.Name {
+			hostnames = append(hostnames, node.Status.Addresses[j].Address)
+		}
+	}
+
+	for _, hostname := range hostnames {
+		i.byHostname[hostname] = node
+	}
+
+	i.hostnames[node.Name] = hostnames
+}
+
+// remove stops indexing the Node with the given name.
+func (i *nodeIndex) remove(name string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.removeLocked(name)
+}
+
+func (i *nodeIndex) removeLocked(name string) {
+	for _, hostname := range i.hostnames[name] {
+		if i.byHostname[hostname].Name == name {
+			delete(i.byHostname, hostname)
+		}
+	}
+
+	delete(i.hostnames, name)
+}
+
+// get returns a copy of the Node indexed under the given hostname, if any.
+func (i *nodeIndex) get(hostname string) (*k8sv1.Node, bool) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	node, found := i.byHostname[hostname]
+	if !found {
+		return nil, false
+	}
+
+	return node.DeepCopy(), true
+}
@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// isOnNotReadyNode returns whether the given event is the create or update of an Endpoint whose Node, correlated via its
+// hostname, is NotReady, along with the Node's name, if SuppressEndpointsOnNotReadyNodes is set.
+func (c *Controller) isOnNotReadyNode(eventType event.Type, obj runtime.Object) (string, bool) {
+	if !c.skipNotReady {
+		return "", false
+	}
+
+	switch eventType {
+	case event.LocalEndpointCreated, event.LocalEndpointUpdated, event.RemoteEndpointCreated, event.RemoteEndpointUpdated:
+	default:
+		return "", false
+	}
+
+	endpoint, ok := obj.(*smv1.Endpoint)
+	if !ok {
+		return "", false
+	}
+
+	for _, obj := range c.listResources(NodeResource, &k8sv1.Node{}) {
+		if node := obj.(*k8sv1.Node); node.Name == endpoint.Spec.Hostname {
+			return node.Name, !isNodeReady(node)
+		}
+	}
+
+	return "", false
+}
+
+// isNodeReady returns whether the given Node's Ready condition is true. A Node that doesn't report the condition yet is
+// considered ready.
+func isNodeReady(node *k8sv1.Node) bool {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == k8sv1.NodeReady {
+			return node.Status.Conditions[i].Status == k8sv1.ConditionTrue
+		}
+	}
+
+	return true
+}
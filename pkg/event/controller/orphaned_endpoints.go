@@ -0,0 +1,94 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"time"
+
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"k8s.io/utils/set"
+)
+
+// DefaultOrphanedEndpointsInterval is the default interval at which the remote Endpoints are cross-checked against the
+// KnownClusters.
+const DefaultOrphanedEndpointsInterval = 5 * time.Minute
+
+// KnownClustersSource provides the IDs of the remote clusters currently known to exist, eg as per a cluster membership
+// registry.
+type KnownClustersSource interface {
+	KnownClusters() ([]string, error)
+}
+
+// runOrphanedEndpointsReconciler cross-checks the remote Endpoints against the KnownClusters at the configured interval
+// until the stop channel is closed.
+func (c *Controller) runOrphanedEndpointsReconciler(stopCh <-chan struct{}) {
+	ticker := c.clock.NewTicker(c.orphanedEndpointsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.removeOrphanedEndpoints()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// removeOrphanedEndpoints synthesizes the removal of the tracked remote Endpoints whose cluster is no longer known.
+func (c *Controller) removeOrphanedEndpoints() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	clusterIDs, err := c.knownClusters.KnownClusters()
+	if err != nil {
+		c.eventLog.Error(err, "Error retrieving the known clusters - not checking for orphaned endpoints")
+		return
+	}
+
+	known := set.New(clusterIDs...)
+
+	var orphaned []*subv1.Endpoint
+
+	c.handlerState.remoteEndpoints.Range(func(_, value any) bool {
+		if endpoint := value.(*subv1.Endpoint); !known.Has(endpoint.Spec.ClusterID) {
+			orphaned = append(orphaned, endpoint)
+		}
+
+		return true
+	})
+
+	sort.Slice(orphaned, func(i, j int) bool {
+		return orphaned[i].Name < orphaned[j].Name
+	})
+
+	for _, endpoint := range orphaned {
+		c.eventLog.Warningf("Remote cluster %q is no longer known - synthesizing the removal of its endpoint %q",
+			endpoint.Spec.ClusterID, endpoint.Name)
+
+		c.orphanedEndpoints.Insert(endpoint.Name)
+
+		if err := c.handleRemovedRemoteEndpoint(endpoint); err != nil {
+			c.eventLog.Error(err, "Error handling removed orphaned endpoint")
+		}
+	}
+}
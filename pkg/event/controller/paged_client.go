@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// pagedClient wraps a dynamic client such that list requests are limited to a fixed page size. The informers
+// follow the continue token returned by the API server so the initial list is retrieved in chunks.
+type pagedClient struct {
+	dynamic.Interface
+	pageSize int64
+}
+
+type pagedNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	pageSize int64
+}
+
+type pagedResource struct {
+	dynamic.ResourceInterface
+	pageSize int64
+}
+
+func newPagedClient(client dynamic.Interface, pageSize int64) dynamic.Interface {
+	return &pagedClient{Interface: client, pageSize: pageSize}
+}
+
+func (c *pagedClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &pagedNamespaceableResource{NamespaceableResourceInterface: c.Interface.Resource(resource), pageSize: c.pageSize}
+}
+
+func (r *pagedNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &pagedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), pageSize: r.pageSize}
+}
+
+func (r *pagedNamespaceableResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	opts.Limit = r.pageSize
+	return r.NamespaceableResourceInterface.List(ctx, opts) //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *pagedResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	opts.Limit = r.pageSize
+	return r.ResourceInterface.List(ctx, opts) //nolint:wrapcheck // This is a wrapper function.
+}
@@ -0,0 +1,72 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+// PreferredGatewayAnnotation is the annotation that marks a remote Endpoint as the preferred gateway for its cluster, eg
+// set on the surviving Endpoint on failover. Its value must be "true".
+const PreferredGatewayAnnotation = "submariner.io/preferred-gateway"
+
+func isPreferredGateway(endpoint *smv1.Endpoint) bool {
+	return endpoint.Annotations[PreferredGatewayAnnotation] == "true"
+}
+
+// updatePreferredGateway updates the tracked preferred Endpoint of the given remote Endpoint's cluster and notifies the
+// handlers if it changed. The tracked Endpoint is only updated if the handlers succeed so the change is detected again
+// when retried. Must be called with the syncMutex held.
+func (c *Controller) updatePreferredGateway(endpoint *smv1.Endpoint, removed bool) error {
+	clusterID := endpoint.Spec.ClusterID
+	oldPreferred := c.preferredGateways[clusterID]
+	newPreferred := oldPreferred
+
+	switch {
+	case !removed && isPreferredGateway(endpoint):
+		newPreferred = endpoint
+	case oldPreferred != nil && oldPreferred.Name == endpoint.Name:
+		newPreferred = nil
+	}
+
+	if endpointName(oldPreferred) != endpointName(newPreferred) {
+		c.eventLog.Infof("The preferred gateway Endpoint for cluster %q changed from %q to %q", clusterID,
+			endpointName(oldPreferred), endpointName(newPreferred))
+
+		if err := c.handlers.PreferredGatewayChanged(clusterID, oldPreferred, newPreferred); err != nil {
+			return err //nolint:wrapcheck  // Let the caller wrap it
+		}
+	}
+
+	if newPreferred == nil {
+		delete(c.preferredGateways, clusterID)
+	} else {
+		c.preferredGateways[clusterID] = newPreferred
+	}
+
+	return nil
+}
+
+func endpointName(endpoint *smv1.Endpoint) string {
+	if endpoint == nil {
+		return ""
+	}
+
+	return endpoint.Name
+}
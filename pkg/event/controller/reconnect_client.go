@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// reconnectDetectingClient wraps the dynamic client used by a single watcher to detect when its informer re-establishes
+// the watch connection after it was interrupted, ie when a watch request succeeds after a failed watch request or a
+// relist, which the informer issues when the prior watch failed or expired. Routine watch restarts, eg on server-side
+// timeouts, resume from the last resource version without a relist so they aren't considered reconnections.
+type reconnectDetectingClient struct {
+	dynamic.Interface
+	state *watchState
+}
+
+type reconnectDetectingNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	state *watchState
+}
+
+type reconnectDetectingResource struct {
+	dynamic.ResourceInterface
+	state *watchState
+}
+
+type watchState struct {
+	listed      atomic.Bool
+	interrupted atomic.Bool
+	reconnected func()
+}
+
+func newReconnectDetectingClient(client dynamic.Interface, reconnected func()) dynamic.Interface {
+	return &reconnectDetectingClient{Interface: client, state: &watchState{reconnected: reconnected}}
+}
+
+func (c *reconnectDetectingClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &reconnectDetectingNamespaceableResource{NamespaceableResourceInterface: c.Interface.Resource(resource), state: c.state}
+}
+
+func (r *reconnectDetectingNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &reconnectDetectingResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), state: r.state}
+}
+
+func (r *reconnectDetectingNamespaceableResource) List(ctx context.Context, opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	r.state.listStarted(opts)
+
+	return r.NamespaceableResourceInterface.List(ctx, opts) //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *reconnectDetectingNamespaceableResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.NamespaceableResourceInterface.Watch(ctx, opts)
+	r.state.watchStarted(err)
+
+	return w, err //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *reconnectDetectingResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.state.listStarted(opts)
+
+	return r.ResourceInterface.List(ctx, opts) //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *reconnectDetectingResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.ResourceInterface.Watch(ctx, opts)
+	r.state.watchStarted(err)
+
+	return w, err //nolint:wrapcheck // This is a wrapper function.
+}
+
+// listStarted records a relist, ie a list request after the initial one. The subsequent pages of a paginated list, which
+// specify a continue token, are part of the same list.
+func (s *watchState) listStarted(opts metav1.ListOptions) {
+	if opts.Continue == "" && s.listed.Swap(true) {
+		s.interrupted.Store(true)
+	}
+}
+
+func (s *watchState) watchStarted(err error) {
+	if err != nil {
+		s.interrupted.Store(true)
+		return
+	}
+
+	if s.interrupted.Swap(false) {
+		go s.reconnected()
+	}
+}
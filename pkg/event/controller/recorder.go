@@ -0,0 +1,264 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Operations of a recorded event.
+const (
+	CreateOperation = "Create"
+	UpdateOperation = "Update"
+	DeleteOperation = "Delete"
+)
+
+// EventRecord is an event received from a watcher, as recorded to the AuditWriter.
+type EventRecord struct {
+	// Resource is the type name of the watched resource, eg EndpointResource.
+	Resource string
+
+	// Cluster is the name of the cluster from which the event originated if multiple Clusters are configured.
+	Cluster string
+
+	// Operation is one of CreateOperation, UpdateOperation or DeleteOperation.
+	Operation string
+
+	// NumRequeues is the number of times the event had been requeued when it was delivered.
+	NumRequeues int
+
+	// Object is the watched object.
+	Object runtime.Object
+}
+
+type eventRecorder struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	codec  EventCodec
+	log    log.Logger
+	// compressor is the gzip writer wrapping the AuditWriter if AuditCompression is set. Guarded by mutex.
+	compressor *gzip.Writer
+	// closed indicates the compressed stream was terminated on stop so further events aren't recorded. Guarded by mutex.
+	closed bool
+}
+
+func newEventRecorder(writer io.Writer, codec EventCodec, compress bool, logger log.Logger) *eventRecorder {
+	r := &eventRecorder{writer: writer, codec: codec, log: logger}
+	if r.codec == nil {
+		r.codec = JSONCodec{}
+	}
+
+	if compress {
+		r.compressor = gzip.NewWriter(writer)
+		r.writer = r.compressor
+	}
+
+	return r
+}
+
+// recordingHandler wraps the given watcher event handler to record each received event, including each redelivery of a
+// requeued event.
+func (r *eventRecorder) recordingHandler(resource, cluster string, handler watcher.EventHandler) watcher.EventHandler {
+	recording := func(op string, f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			r.record(&EventRecord{Resource: resource, Cluster: cluster, Operation: op, NumRequeues: numRequeues, Object: obj})
+
+			return f(obj, numRequeues)
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: recording(CreateOperation, handler.OnCreate),
+		OnUpdateFunc: recording(UpdateOperation, handler.OnUpdate),
+		OnDeleteFunc: recording(DeleteOperation, handler.OnDelete),
+	}
+}
+
+func (r *eventRecorder) record(record *EventRecord) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	err := r.codec.Encode(r.writer, record)
+	if err == nil && r.compressor != nil {
+		// Flush each record so the compressed stream is always decodable up to the last recorded event.
+		err = r.compressor.Flush()
+	}
+
+	if err != nil {
+		r.log.Errorf(err, "Error recording %s event for %T %q", record.Operation, record.Object, resourceName(record.Object))
+	}
+}
+
+// close terminates the compressed stream, if any. The AuditWriter itself isn't closed.
+func (r *eventRecorder) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.compressor == nil || r.closed {
+		return
+	}
+
+	r.closed = true
+
+	if err := r.compressor.Close(); err != nil {
+		r.log.Errorf(err, "Error terminating the compressed audit log")
+	}
+}
+
+// ReplaySource reads back the events recorded to an AuditWriter. A gzip compressed recording, as per AuditCompression, is
+// decompressed transparently.
+type ReplaySource struct {
+	reader *bufio.Reader
+	codec  EventCodec
+	err    error
+}
+
+// NewReplaySource returns a ReplaySource that reads events recorded with the default JSONCodec.
+func NewReplaySource(r io.Reader) *ReplaySource {
+	return NewReplaySourceWithCodec(r, JSONCodec{})
+}
+
+// NewReplaySourceWithCodec returns a ReplaySource that reads events recorded with the given EventCodec.
+func NewReplaySourceWithCodec(r io.Reader, codec EventCodec) *ReplaySource {
+	s := &ReplaySource{reader: bufio.NewReader(r), codec: codec}
+
+	if magic, err := s.reader.Peek(2); err == nil && magic[0] == gzipMagic[0] && magic[1] == gzipMagic[1] {
+		gz, err := gzip.NewReader(s.reader)
+		if err != nil {
+			s.err = errors.Wrap(err, "error reading the compressed recording")
+		} else {
+			s.reader = bufio.NewReader(unterminatedReader{gz})
+		}
+	}
+
+	return s
+}
+
+// gzipMagic is the header identifying a gzip compressed stream.
+var gzipMagic = [2]byte{0x1f, 0x8b}
+
+// unterminatedReader reads a compressed recording which may not yet be terminated, eg if the recording controller is still
+// running. As the compressed stream is flushed after each record, its unexpected end is treated as the end of the records.
+type unterminatedReader struct {
+	io.Reader
+}
+
+func (r unterminatedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+
+	return n, err //nolint:wrapcheck  // Let the caller wrap it
+}
+
+// Next returns the next recorded event or io.EOF if there are no more.
+func (s *ReplaySource) Next() (*EventRecord, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.codec.Decode(s.reader) //nolint:wrapcheck  // Let the caller wrap it
+}
+
+// Replay feeds the events read from the given ReplaySource, in order, through the same dispatch path as the events received
+// from the watchers. As each redelivery of a requeued event is recorded, requeues requested during replay are ignored. The
+// controller need not be started.
+func (c *Controller) Replay(source *ReplaySource) error {
+	for {
+		record, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "error reading the next recorded event")
+		}
+
+		if err := c.replay(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Controller) replay(record *EventRecord) error {
+	var w *resourceWatcher
+
+	for _, rw := range c.resourceWatchers {
+		if rw.resource == record.Resource && rw.cluster == record.Cluster {
+			w = rw
+			break
+		}
+	}
+
+	if w == nil {
+		return errors.Errorf("no watcher found for recorded %s resource in cluster %q", record.Resource, record.Cluster)
+	}
+
+	var dispatch func(obj runtime.Object, numRequeues int) bool
+
+	switch record.Operation {
+	case CreateOperation:
+		dispatch = w.handler.OnCreate
+	case UpdateOperation:
+		dispatch = w.handler.OnUpdate
+	case DeleteOperation:
+		dispatch = w.handler.OnDelete
+	default:
+		return errors.Errorf("invalid recorded operation %q", record.Operation)
+	}
+
+	obj, err := toResourceType(record.Object, w.resourceType)
+	if err != nil {
+		return errors.Wrapf(err, "error converting the recorded %s resource", record.Resource)
+	}
+
+	dispatch(obj, record.NumRequeues)
+
+	return nil
+}
+
+func toResourceType(obj, resourceType runtime.Object) (runtime.Object, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		typed := resourceType.DeepCopyObject()
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed)
+
+		return typed, err //nolint:wrapcheck  // Let the caller wrap it
+	}
+
+	if reflect.TypeOf(obj) != reflect.TypeOf(resourceType) {
+		return nil, errors.Errorf("unexpected object type %T", obj)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,289 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// registries fans out event notifications to one or more event registries, in order. The first registry is considered
+// the primary registry.
+type registries []*event.Registry
+
+func (r registries) GetName() string {
+	names := make([]string, len(r))
+	for i := range r {
+		names[i] = r[i].GetName()
+	}
+
+	return strings.Join(names, ",")
+}
+
+func (r registries) AddHandler(h event.Handler) (bool, error) {
+	return r[0].AddHandler(h) //nolint:wrapcheck  // Let the caller wrap it
+}
+
+func (r registries) RemoveHandler(name string) bool {
+	return r[0].RemoveHandler(name)
+}
+
+func (r registries) SetHandlerEnabled(name string, enabled bool) bool {
+	found := false
+
+	for _, registry := range r {
+		found = registry.SetHandlerEnabled(name, enabled) || found
+	}
+
+	return found
+}
+
+func (r registries) SetHandlerState(handlerState event.HandlerState) {
+	for _, registry := range r {
+		registry.SetHandlerState(handlerState)
+	}
+}
+
+func (r registries) PreStart(handlerState event.HandlerState) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.PreStart(handlerState) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) ControllerStarted() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.ControllerStarted() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) ControllerStopping() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.ControllerStopping() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+// ReadyChannels returns the Ready channels of the handlers in all registries, keyed by "<registry>/<handler>".
+func (r registries) ReadyChannels() map[string]<-chan struct{} {
+	channels := map[string]<-chan struct{}{}
+
+	for _, registry := range r {
+		for name, ch := range registry.ReadyChannels() {
+			channels[registry.GetName()+"/"+name] = ch
+		}
+	}
+
+	return channels
+}
+
+// InFlightHandlers returns the names of the handlers in all registries currently processing an event, as
+// "<registry>/<handler>".
+func (r registries) InFlightHandlers() []string {
+	var names []string
+
+	for _, registry := range r {
+		for _, name := range registry.InFlightHandlers() {
+			names = append(names, registry.GetName()+"/"+name)
+		}
+	}
+
+	return names
+}
+
+func (r registries) StopHandlers() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.StopHandlers() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) TransitionToNonGateway() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.TransitionToNonGateway() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) TransitionToGateway() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.TransitionToGateway() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) LocalEndpointCreated(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.LocalEndpointCreated(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) LocalEndpointUpdated(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.LocalEndpointUpdated(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) LocalEndpointRemoved(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.LocalEndpointRemoved(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) RemoteEndpointCreated(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.RemoteEndpointCreated(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) RemoteEndpointUpdated(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.RemoteEndpointUpdated(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) RemoteEndpointRemoved(endpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.RemoteEndpointRemoved(endpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) RemoteEndpointBatch(key string, batch []event.EndpointEvent) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.RemoteEndpointBatch(key, batch) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) NodeCreated(node *k8sv1.Node) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.NodeCreated(node) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) NodeUpdated(node *k8sv1.Node) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.NodeUpdated(node) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) NodeRemoved(node *k8sv1.Node) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.NodeRemoved(node) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) SubnetConflictDetected(a, b *subv1.Endpoint, overlap string) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.SubnetConflictDetected(a, b, overlap) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) EndpointHealthChanged(endpoint *subv1.Endpoint, healthy bool) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.EndpointHealthChanged(endpoint, healthy) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) LocalEndpointIPChanged(oldEndpoint, newEndpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.LocalEndpointIPChanged(oldEndpoint, newEndpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) EndpointBackendConfigChanged(clusterID string, changed map[string]string) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.EndpointBackendConfigChanged(clusterID, changed) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) PreferredGatewayChanged(clusterID string, oldEndpoint, newEndpoint *subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.PreferredGatewayChanged(clusterID, oldEndpoint, newEndpoint) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) ClusterGlobalEgressIPCreated(egressIP *subv1.ClusterGlobalEgressIP) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.ClusterGlobalEgressIPCreated(egressIP) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) ClusterGlobalEgressIPUpdated(egressIP *subv1.ClusterGlobalEgressIP) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.ClusterGlobalEgressIPUpdated(egressIP) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) ClusterGlobalEgressIPRemoved(egressIP *subv1.ClusterGlobalEgressIP) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.ClusterGlobalEgressIPRemoved(egressIP) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) UnstructuredEvent(gvr schema.GroupVersionResource, eventType event.Type, obj *unstructured.Unstructured) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.UnstructuredEvent(gvr, eventType, obj) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) SoleGatewayChanged(sole bool) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.SoleGatewayChanged(sole) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) LeadershipChanged(isLeader bool) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.LeadershipChanged(isLeader) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) WatchReconnected(resource string) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.WatchReconnected(resource) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) Heartbeat() error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.Heartbeat() //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+func (r registries) InitialEndpoints(local, remote []*subv1.Endpoint) error {
+	return r.invoke(func(registry *event.Registry) error {
+		return registry.InitialEndpoints(local, remote) //nolint:wrapcheck  // Wrapped by invoke
+	})
+}
+
+// invoke calls the given function for each registry, regardless of errors returned by prior registries. Errors are
+// attributed to the registry that returned them.
+func (r registries) invoke(f func(registry *event.Registry) error) error {
+	var errs []error
+
+	for _, registry := range r {
+		if err := f(registry); err != nil {
+			errs = append(errs, errors.Wrapf(err, "registry %q", registry.GetName()))
+		}
+	}
+
+	return k8serrors.NewAggregate(errs)
+}
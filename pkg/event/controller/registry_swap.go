@@ -0,0 +1,128 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+)
+
+// SwapRegistry atomically replaces the controller's primary Registry with the given one, eg to swap the entire set of
+// Handlers without downtime. The event in flight is drained first and no further events are dispatched until the swap
+// completes, so each event is dispatched to either the old or the new Handlers. The new Handlers are given the handler
+// state, PreStart is invoked if the controller was started, the current state is replayed to them, as per AddHandler,
+// and OnControllerStarted is invoked if the controller is running. The old Handlers are then stopped. If the new
+// Handlers fail to be wired, the old Registry is restored, the new Handlers are stopped and the error is returned.
+func (c *Controller) SwapRegistry(registry *event.Registry) error {
+	if registry == nil {
+		return errors.New("a Registry is required")
+	}
+
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	old := c.handlers[0]
+
+	c.eventLog.Infof("Swapping registry %q for registry %q", old.GetName(), registry.GetName())
+
+	registry.SetDeepCopyObjects(c.deepCopyObjects)
+	registry.SetReverseTeardownOrder(c.reverseTeardown)
+
+	if c.env.EventTrace {
+		registry.SetTracer(&c.log)
+	}
+
+	registry.SetHandlerState(&c.handlerState)
+
+	c.handlers[0] = registry
+
+	if err := c.wireRegistry(registry); err != nil {
+		c.handlers[0] = old
+
+		if stopErr := registry.StopHandlers(); stopErr != nil {
+			c.eventLog.Warningf("Error stopping the handlers of registry %q: %v", registry.GetName(), stopErr)
+		}
+
+		return errors.Wrapf(err, "error swapping in registry %q", registry.GetName())
+	}
+
+	// Batching is enabled once the state was replayed as the replayed Endpoints are notified individually.
+	registry.SetBatchRemoteEndpoints(c.batchKeyFunc != nil)
+	registry.SetObserver(c.capture.record)
+	old.SetObserver(nil)
+
+	if err := old.StopHandlers(); err != nil {
+		c.eventLog.Warningf("Error stopping the handlers of swapped out registry %q: %v", old.GetName(), err)
+	}
+
+	return nil
+}
+
+// wireRegistry brings the Handlers of the given swapped in Registry up to date with the controller. Must be called with
+// the syncMutex held.
+func (c *Controller) wireRegistry(registry *event.Registry) error {
+	if c.preStarted {
+		if err := registry.PreStart(&c.handlerState); err != nil {
+			return errors.Wrap(err, "error invoking PreStart")
+		}
+	}
+
+	if err := c.replayStateTo(registry); err != nil {
+		return err
+	}
+
+	if c.lifecycle.get() == LifecycleRunning {
+		return errors.Wrap(registry.ControllerStarted(), "error invoking OnControllerStarted")
+	}
+
+	return nil
+}
+
+// replayStateTo notifies the Handlers of the given Registry of copies of the tracked Endpoints, as per replayState.
+func (c *Controller) replayStateTo(registry *event.Registry) error {
+	for _, endpoint := range c.localEndpoints {
+		if err := registry.LocalEndpointCreated(endpoint.DeepCopy()); err != nil {
+			return errors.Wrapf(err, "error replaying local Endpoint %q", endpoint.Name)
+		}
+	}
+
+	if c.handlerState.wasOnGateway.Load() {
+		if err := registry.TransitionToGateway(); err != nil {
+			return errors.Wrap(err, "error replaying TransitionToGateway")
+		}
+	}
+
+	var err error
+
+	c.handlerState.remoteEndpoints.Range(func(_, value any) bool {
+		endpoint := value.(*subv1.Endpoint)
+
+		err = registry.RemoteEndpointCreated(endpoint.DeepCopy())
+		if err != nil {
+			err = errors.Wrapf(err, "error replaying remote Endpoint %q", endpoint.Name)
+		}
+
+		return err == nil
+	})
+
+	return err
+}
@@ -0,0 +1,77 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/log"
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+)
+
+// DefaultRemoteEndpointBatchWindow is the RemoteEndpointBatchWindow used if none is configured.
+const DefaultRemoteEndpointBatchWindow = time.Second
+
+// ClusterIDBatchKey is a RemoteEndpointBatchKey that batches the events of the Endpoints of the same remote cluster.
+func ClusterIDBatchKey(endpoint *smv1.Endpoint) string {
+	return endpoint.Spec.ClusterID
+}
+
+// batchRemoteEndpoint adds the given remote Endpoint event to the pending batch for the Endpoint's batch key, if batching
+// is configured. The first event of a batch schedules its dispatch at the end of the batching window. Must be called with
+// the syncMutex held.
+func (c *Controller) batchRemoteEndpoint(eventType event.Type, endpoint *smv1.Endpoint) {
+	if c.batchKeyFunc == nil {
+		return
+	}
+
+	key := c.batchKeyFunc(endpoint)
+
+	batch, pending := c.pendingBatches[key]
+	c.pendingBatches[key] = append(batch, event.EndpointEvent{Type: eventType, Endpoint: endpoint})
+
+	if pending {
+		c.eventLog.V(log.DEBUG).Infof("Batching %s event for Endpoint %q with key %q", eventType, endpoint.Name, key)
+		return
+	}
+
+	c.clock.AfterFunc(c.batchWindow, func() {
+		c.flushRemoteEndpointBatch(key)
+	})
+}
+
+// flushRemoteEndpointBatch dispatches the pending batch with the given key, if any.
+func (c *Controller) flushRemoteEndpointBatch(key string) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	batch, pending := c.pendingBatches[key]
+	if !pending {
+		return
+	}
+
+	delete(c.pendingBatches, key)
+
+	if err := c.handlers.RemoteEndpointBatch(key, batch); err != nil {
+		c.eventLog.Errorf(err, "Error handling the batch of %d remote Endpoint events with key %q", len(batch), key)
+	}
+}
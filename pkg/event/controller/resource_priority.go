@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/set"
+)
+
+// initialEventsPollInterval is the interval at which the dispatch of the initial events of a prioritized resource type is
+// polled.
+const initialEventsPollInterval = 10 * time.Millisecond
+
+// startWatchersByPriority starts the watchers of the prioritized resource types first, in order of priority, each once
+// the initial events of the previous resource type were dispatched. The remaining watchers are then started.
+func (c *Controller) startWatchersByPriority(stopCh <-chan struct{}) error {
+	prioritized := set.New(c.resourcePriority...)
+
+	for _, resource := range c.resourcePriority {
+		watchers := c.watchersOf(resource)
+
+		for _, w := range watchers {
+			if err := c.startWatcher(w, stopCh); err != nil {
+				return err
+			}
+		}
+
+		for _, w := range watchers {
+			if err := w.awaitInitialEvents(stopCh); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, w := range c.resourceWatchers {
+		if prioritized.Has(w.resource) {
+			continue
+		}
+
+		if err := c.startWatcher(w, stopCh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) isPrioritized(resource string) bool {
+	for _, r := range c.resourcePriority {
+		if r == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Controller) watchersOf(resource string) []*resourceWatcher {
+	var watchers []*resourceWatcher
+
+	for _, w := range c.resourceWatchers {
+		if w.resource == resource {
+			watchers = append(watchers, w)
+		}
+	}
+
+	return watchers
+}
+
+// awaitInitialEvents waits until the events of the objects in the synced informer cache have been dispatched, ie
+// processed once, regardless of the outcome.
+func (w *resourceWatcher) awaitInitialEvents(stopCh <-chan struct{}) error {
+	initial := set.New[string]()
+	for _, obj := range w.ListResources(w.resourceType, nil) {
+		initial.Insert(objectKey(obj))
+	}
+
+	err := wait.PollUntilContextCancel(wait.ContextForChannel(stopCh), initialEventsPollInterval, true,
+		func(_ context.Context) (bool, error) {
+			w.processedMutex.Lock()
+			defer w.processedMutex.Unlock()
+
+			return w.processed.IsSuperset(initial), nil
+		})
+
+	w.processedMutex.Lock()
+	w.processed = nil
+	w.processedMutex.Unlock()
+
+	return errors.Wrapf(err, "error awaiting the dispatch of the initial %s events", w.resource)
+}
+
+// processedTrackingHandler wraps the given watcher event handler to track the keys of the processed objects until the
+// initial events have been awaited.
+func (w *resourceWatcher) processedTrackingHandler(handler watcher.EventHandler) watcher.EventHandler {
+	w.processed = set.New[string]()
+
+	tracking := func(f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			requeue := f(obj, numRequeues)
+
+			w.processedMutex.Lock()
+			if w.processed != nil {
+				w.processed.Insert(objectKey(obj))
+			}
+			w.processedMutex.Unlock()
+
+			return requeue
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: tracking(handler.OnCreate),
+		OnUpdateFunc: tracking(handler.OnUpdate),
+		OnDeleteFunc: tracking(handler.OnDelete),
+	}
+}
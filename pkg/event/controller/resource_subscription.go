@@ -0,0 +1,147 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceEventHandler handles the events of a resource type watched via WatchResource.
+type ResourceEventHandler interface {
+	// OnResourceEvent is called with the watched kind, the action, ie one of CreateOperation, UpdateOperation or
+	// DeleteOperation, and the object. If an error is returned, the event is retried.
+	OnResourceEvent(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error
+}
+
+// ResourceEventHandlerFunc is an adapter to allow the use of an ordinary function as a ResourceEventHandler.
+type ResourceEventHandlerFunc func(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error
+
+func (f ResourceEventHandlerFunc) OnResourceEvent(gvk schema.GroupVersionKind, action string, obj *unstructured.Unstructured) error {
+	return f(gvk, action, obj)
+}
+
+// ResourceSubscription specifies a resource type to watch via WatchResource.
+type ResourceSubscription struct {
+	// GVK is the kind of the resource to watch. It must be known to the cluster's RESTMapper, eg its CRD is installed.
+	GVK schema.GroupVersionKind
+
+	// Cluster is the name of the cluster in which to watch the resource, as per ClusterConfig. By default, the cluster
+	// configured via the Config's RestConfig or Client is used.
+	Cluster string
+
+	// AllNamespaces if true, the resource is watched across the cluster, otherwise only in the controller's namespace.
+	AllNamespaces bool
+
+	// Handler is notified of the events of the watched resource.
+	Handler ResourceEventHandler
+}
+
+// WatchResource registers a watch for the resource type specified by the given subscription, whose events are dispatched
+// to its Handler as generic unstructured objects, with the UnstructuredCreated, UnstructuredUpdated or UnstructuredRemoved
+// event type passed to the EventFilter. As for the built-in resource types, the events are serialized with the
+// controller's own events and retried on error, and Start waits for the resource's informer cache to sync. It must be
+// called before the controller is started. The resource is identified by its kind, eg for Resync, so it mustn't clash
+// with another watched resource type.
+func (c *Controller) WatchResource(sub ResourceSubscription) error {
+	if c.lifecycle.get() != LifecycleNew {
+		return errors.New("resources can only be watched before the controller is started")
+	}
+
+	if sub.Handler == nil {
+		return errors.New("a Handler must be specified")
+	}
+
+	config, found := c.watcherConfigs[sub.Cluster]
+	if !found {
+		return errors.Errorf("unknown cluster %q", sub.Cluster)
+	}
+
+	if !hasResource(config.RestMapper, sub.GVK) {
+		return errors.Errorf("the %s resource is not known%s", sub.GVK, forCluster(sub.Cluster))
+	}
+
+	scope := namespaceScoped
+	if sub.AllNamespaces {
+		scope = clusterScoped
+	}
+
+	return c.watchUnstructured(sub.GVK, sub.Cluster, scope, "", config,
+		func(action string, _ event.Type, obj *unstructured.Unstructured) error {
+			return sub.Handler.OnResourceEvent(sub.GVK, action, obj) //nolint:wrapcheck  // Let the caller wrap it
+		})
+}
+
+// watchUnstructured adds a watcher for the given kind in the given cluster whose events are passed as unstructured objects
+// to the given dispatch function, along with the operation and the event type. The resource is identified by its kind,
+// so it returns an error if the kind is already watched in the cluster.
+func (c *Controller) watchUnstructured(gvk schema.GroupVersionKind, cluster string, scope resourceScope, namespace string,
+	config watcher.Config, dispatch func(action string, eventType event.Type, obj *unstructured.Unstructured) error,
+) error {
+	resource := gvk.Kind
+
+	for _, rw := range c.resourceWatchers {
+		if rw.resource == resource && rw.cluster == cluster {
+			return errors.Errorf("the %s resource is already watched%s", resource, forCluster(cluster))
+		}
+	}
+
+	resourceType := &unstructured.Unstructured{}
+	resourceType.SetGroupVersionKind(gvk)
+
+	eventFunc := func(action string, eventType event.Type) func(runtime.Object, int) bool {
+		return withOriginCluster(cluster, c.unstructuredEventFunc(resource, action, eventType, dispatch))
+	}
+
+	return c.addResourceWatcher(resource, cluster, scope, &watcher.ResourceConfig{
+		ResourceType:    resourceType,
+		SourceNamespace: namespace,
+		Handler: watcher.EventHandlerFuncs{
+			OnCreateFunc: eventFunc(CreateOperation, event.UnstructuredCreated),
+			OnUpdateFunc: eventFunc(UpdateOperation, event.UnstructuredUpdated),
+			OnDeleteFunc: eventFunc(DeleteOperation, event.UnstructuredRemoved),
+		},
+	}, config)
+}
+
+func (c *Controller) unstructuredEventFunc(resource, action string, eventType event.Type,
+	dispatch func(action string, eventType event.Type, obj *unstructured.Unstructured) error,
+) func(runtime.Object, int) bool {
+	return func(obj runtime.Object, requeueCount int) bool {
+		if requeueCount > maxRequeues {
+			c.dropEvent(eventType, obj, DropReasonMaxRequeues, "requeued more than %d times", maxRequeues)
+			return false
+		}
+
+		if !c.shouldDispatch(eventType, obj) {
+			return false
+		}
+
+		if err := dispatch(action, eventType, obj.(*unstructured.Unstructured)); err != nil {
+			c.eventLog.Errorf(err, "Error handling %s event for %s %q", action, resource, resourceName(obj))
+			return true
+		}
+
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// retryTracker tracks the number of failed attempts of the events being retried, keyed by event key.
+type retryTracker struct {
+	mutex   sync.Mutex
+	retries map[string]int
+	metrics *metrics
+}
+
+// trackingHandler wraps the given watcher event handler to count the failed attempts of each event until it's handled
+// successfully or dropped. Each attempt is also recorded in the metrics.
+func (r *retryTracker) trackingHandler(resourceKey string, handler watcher.EventHandler) watcher.EventHandler {
+	tracking := func(f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			requeue := f(obj, numRequeues)
+
+			retried := false
+
+			key, err := cache.MetaNamespaceKeyFunc(obj)
+			if err == nil {
+				retried = r.update(resourceKey+"/"+key, requeue)
+			}
+
+			r.metrics.recordEvent(retried, requeue)
+
+			return requeue
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: tracking(handler.OnCreate),
+		OnUpdateFunc: tracking(handler.OnUpdate),
+		OnDeleteFunc: tracking(handler.OnDelete),
+	}
+}
+
+// update updates the failed attempts of the given event key and returns whether the event had previously failed.
+func (r *retryTracker) update(key string, requeue bool) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, retried := r.retries[key]
+
+	if requeue {
+		r.retries[key]++
+	} else {
+		delete(r.retries, key)
+	}
+
+	return retried
+}
+
+// PendingRetries returns the keys of the events currently being retried mapped to the number of failed attempts so far.
+// Each key is the watched resource type name, as per SyncStatus, followed by the object's namespace/name key, eg
+// "Endpoint/submariner-operator/cluster1-endpoint".
+func (c *Controller) PendingRetries() map[string]int {
+	c.retryTracker.mutex.Lock()
+	defer c.retryTracker.mutex.Unlock()
+
+	retries := make(map[string]int, len(c.retryTracker.retries))
+	for k, v := range c.retryTracker.retries {
+		retries[k] = v
+	}
+
+	return retries
+}
@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+)
+
+// RunHandle tracks a controller started via StartAsync.
+type RunHandle struct {
+	done chan struct{}
+	err  error
+}
+
+// StartAsync starts the controller in the background and returns immediately. The returned RunHandle surfaces a failure
+// to start and allows waiting for the controller to stop, which occurs when the given stop channel is closed.
+func (c *Controller) StartAsync(stopCh <-chan struct{}) (*RunHandle, error) {
+	if stopCh == nil {
+		return nil, errors.New("a stop channel is required")
+	}
+
+	h := &RunHandle{done: make(chan struct{})}
+
+	go func() {
+		defer close(h.done)
+
+		h.err = c.Start(stopCh)
+		if h.err != nil {
+			c.log.Error(h.err, "Error starting the event controller")
+			return
+		}
+
+		<-stopCh
+	}()
+
+	return h, nil
+}
+
+// Err returns the error that caused the controller to fail to start, or nil if it hasn't failed or hasn't finished
+// starting yet.
+func (h *RunHandle) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the controller has stopped, either because it failed to start or because its stop channel was closed,
+// and returns the start error, if any.
+func (h *RunHandle) Wait() error {
+	<-h.done
+	return h.err
+}
@@ -0,0 +1,153 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/event"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// Snapshot returns the serialized remote Endpoints currently tracked in the handler state, suitable for restoring via
+// Config.InitialEndpoints, eg across a controlled restart. Returns nil if they can't be serialized.
+func (c *Controller) Snapshot() []byte {
+	data, err := c.handlerState.snapshot()
+	if err != nil {
+		c.log.Error(err, "Error creating the handler state snapshot")
+		return nil
+	}
+
+	return data
+}
+
+func (s *handlerStateImpl) snapshot() ([]byte, error) {
+	endpoints := []*smv1.Endpoint{}
+
+	s.remoteEndpoints.Range(func(_, value any) bool {
+		endpoints = append(endpoints, value.(*smv1.Endpoint))
+		return true
+	})
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Name < endpoints[j].Name
+	})
+
+	data, err := json.Marshal(endpoints)
+
+	return data, errors.Wrap(err, "error marshalling the remote Endpoints")
+}
+
+// restoreSnapshot restores the remote Endpoints from the given snapshot into the handler state, recording them until
+// they're confirmed by the initial sync.
+func (c *Controller) restoreSnapshot(data []byte) error {
+	var endpoints []*smv1.Endpoint
+
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return errors.Wrap(err, "error unmarshalling the InitialEndpoints snapshot")
+	}
+
+	c.restoredEndpoints = map[string]*smv1.Endpoint{}
+
+	for _, endpoint := range endpoints {
+		c.handlerState.remoteEndpoints.Store(c.endpointIdentity(endpoint), endpoint)
+		c.restoredEndpoints[c.endpointIdentity(endpoint)] = endpoint
+
+		if isPreferredGateway(endpoint) {
+			c.preferredGateways[endpoint.Spec.ClusterID] = endpoint
+		}
+	}
+
+	return nil
+}
+
+// handleRestoredRemoteEndpoint handles the creation of a remote Endpoint with the identity of one that was restored from a
+// snapshot. The handlers were already notified of the restored Endpoint prior to the restart so they're only notified of
+// an update if it changed, including if it was replaced by an Endpoint with another name. Returns false if no Endpoint
+// with its identity was restored. Must be called with the syncMutex held.
+func (c *Controller) handleRestoredRemoteEndpoint(endpoint *smv1.Endpoint) (bool, error) {
+	id := c.endpointIdentity(endpoint)
+
+	restored, found := c.restoredEndpoints[id]
+	if !found {
+		return false, nil
+	}
+
+	c.handlerState.remoteEndpoints.Store(id, endpoint)
+
+	if restored.Name != endpoint.Name || !equality.Semantic.DeepEqual(restored.Spec, endpoint.Spec) {
+		if err := c.handlers.RemoteEndpointUpdated(endpoint); err != nil {
+			return true, err //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		c.batchRemoteEndpoint(event.RemoteEndpointUpdated, endpoint)
+	}
+
+	if err := c.updatePreferredGateway(endpoint, false); err != nil {
+		return true, err
+	}
+
+	delete(c.restoredEndpoints, id)
+
+	return true, nil
+}
+
+// completeRestore notifies the handlers of the removal of the restored remote Endpoints for which no Endpoint with the same
+// identity exists once the Endpoint informer caches have synced.
+func (c *Controller) completeRestore() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	if len(c.restoredEndpoints) == 0 {
+		return
+	}
+
+	defer c.beginEvent()()
+
+	existing := map[string]bool{}
+	for _, obj := range c.listResources(EndpointResource, &smv1.Endpoint{}) {
+		existing[c.endpointIdentity(obj.(*smv1.Endpoint))] = true
+	}
+
+	for _, endpoint := range sortedEndpoints(c.restoredEndpoints) {
+		id := c.endpointIdentity(endpoint)
+		if existing[id] {
+			continue
+		}
+
+		c.eventLog.Infof("Restored remote Endpoint %q no longer exists", endpoint.Name)
+
+		c.handlerState.remoteEndpoints.Delete(id)
+		delete(c.restoredEndpoints, id)
+
+		err := c.handlers.RemoteEndpointRemoved(endpoint)
+		if err == nil {
+			c.batchRemoteEndpoint(event.RemoteEndpointRemoved, endpoint)
+
+			err = c.updatePreferredGateway(endpoint, true)
+		}
+
+		if err != nil {
+			c.eventLog.Error(err, "Error handling the removal of a restored remote Endpoint")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// updateSoleGateway re-evaluates whether the local Node is the only Node labeled as a gateway and, if that changed,
+// notifies the handlers via OnSoleGateway. Nodes tainted as gateway-ineligible aren't counted. It's deferred until the
+// Node informer caches have synced, via refreshSoleGateway, as they can't be listed before.
+func (c *Controller) updateSoleGateway() error {
+	if !c.isSynced(NodeResource) {
+		return nil
+	}
+
+	sole := c.isSoleGateway()
+	if sole == c.soleGateway {
+		return nil
+	}
+
+	if err := c.handlers.SoleGatewayChanged(sole); err != nil {
+		return errors.Wrap(err, "error handling the sole gateway change")
+	}
+
+	if sole {
+		c.eventLog.Infof("Node %q is now the sole gateway node", c.hostname)
+	} else {
+		c.eventLog.Infof("Node %q is no longer the sole gateway node", c.hostname)
+	}
+
+	c.soleGateway = sole
+
+	return nil
+}
+
+// refreshSoleGateway re-evaluates whether the local Node is the sole gateway once the Node informer caches have synced.
+func (c *Controller) refreshSoleGateway() {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	if err := c.updateSoleGateway(); err != nil {
+		c.eventLog.Error(err, "Error refreshing the sole gateway state")
+	}
+}
+
+func (c *Controller) isSoleGateway() bool {
+	gateways := 0
+	isLocalGateway := false
+
+	for _, obj := range c.listResources(NodeResource, &k8sv1.Node{}) {
+		node := obj.(*k8sv1.Node)
+		if node.Labels[GatewayLabel] != "true" || !c.isGatewayEligible(node) {
+			continue
+		}
+
+		gateways++
+
+		if c.isLocalNode(node) {
+			isLocalGateway = true
+		}
+	}
+
+	return isLocalGateway && gateways == 1
+}
@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+
+	smv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+// detectSubnetConflicts compares the subnets advertised by the given remote Endpoint with those of the tracked Endpoints
+// of other remote clusters and notifies the handlers of each overlap.
+func (c *Controller) detectSubnetConflicts(endpoint *smv1.Endpoint) {
+	c.handlerState.remoteEndpoints.Range(func(_, value any) bool {
+		c.notifySubnetConflicts(endpoint, value.(*smv1.Endpoint))
+
+		return true
+	})
+}
+
+func (c *Controller) notifySubnetConflicts(endpoint, other *smv1.Endpoint) {
+	if other.Spec.ClusterID == endpoint.Spec.ClusterID {
+		return
+	}
+
+	for _, overlap := range overlappingSubnets(endpoint.Spec.Subnets, other.Spec.Subnets) {
+		c.eventLog.Warningf("Subnet %q of remote cluster %q overlaps with remote cluster %q", overlap,
+			endpoint.Spec.ClusterID, other.Spec.ClusterID)
+
+		if err := c.handlers.SubnetConflictDetected(endpoint, other, overlap); err != nil {
+			c.eventLog.Error(err, "Error handling subnet conflict")
+		}
+	}
+}
+
+// overlappingSubnets returns the more specific subnet of each overlapping pair in the given lists. Invalid CIDRs are
+// ignored.
+func overlappingSubnets(subnetsA, subnetsB []string) []string {
+	var overlaps []string
+
+	for _, a := range subnetsA {
+		_, netA, err := net.ParseCIDR(a)
+		if err != nil {
+			continue
+		}
+
+		for _, b := range subnetsB {
+			_, netB, err := net.ParseCIDR(b)
+			if err != nil {
+				continue
+			}
+
+			if !netA.Contains(netB.IP) && !netB.Contains(netA.IP) {
+				continue
+			}
+
+			onesA, _ := netA.Mask.Size()
+			onesB, _ := netB.Mask.Size()
+
+			if onesA >= onesB {
+				overlaps = append(overlaps, a)
+			} else {
+				overlaps = append(overlaps, b)
+			}
+		}
+	}
+
+	return overlaps
+}
@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// withSyncTimeout returns the stop channel for the watchers, which is closed when the given stop channel is closed or, if
+// a SyncTimeout is configured, when it elapses before the informer caches have synced, thereby aborting the wait for the
+// sync. The returned function must be invoked once the watchers have started and reports whether the timeout elapsed.
+func (c *Controller) withSyncTimeout(stopCh <-chan struct{}) (<-chan struct{}, func() bool) {
+	if c.syncTimeout == 0 {
+		return stopCh, func() bool {
+			return false
+		}
+	}
+
+	watchStopCh := make(chan struct{})
+	synced := make(chan struct{})
+	timedOut := make(chan struct{})
+	timer := c.clock.NewTimer(c.syncTimeout)
+
+	go func() {
+		defer close(watchStopCh)
+
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C():
+			close(timedOut)
+			return
+		case <-synced:
+			timer.Stop()
+		}
+
+		<-stopCh
+	}()
+
+	return watchStopCh, func() bool {
+		select {
+		case synced <- struct{}{}:
+			return false
+		case <-timedOut:
+			return true
+		case <-stopCh:
+			return false
+		}
+	}
+}
+
+// syncTimedOut returns the error naming the watched resource types whose informer caches didn't sync in time.
+func (c *Controller) syncTimedOut() error {
+	var unsynced []string
+
+	for resource, synced := range c.SyncStatus() {
+		if !synced {
+			unsynced = append(unsynced, resource)
+		}
+	}
+
+	sort.Strings(unsynced)
+
+	return errors.Errorf("timed out after %v waiting for the informer caches to sync: %s - check that the watched resources "+
+		"are accessible", c.syncTimeout, strings.Join(unsynced, ", "))
+}
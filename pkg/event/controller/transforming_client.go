@@ -0,0 +1,133 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// StripManagedFields removes the managedFields from the given resource, which are never used by the handlers but make up a
+// significant part of the size of each cached resource.
+func StripManagedFields(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+}
+
+// cacheTransformFor returns the transform applied to the resources of the given watched resource type before they're
+// cached, ie its entry in CacheTransforms, if any, otherwise the CacheTransform.
+func (c *Controller) cacheTransformFor(resource string) func(obj *unstructured.Unstructured) {
+	if transform, found := c.cacheTransforms[resource]; found {
+		return transform
+	}
+
+	return c.cacheTransform
+}
+
+// transformingClient wraps a dynamic client such that the resources returned by list and watch requests are transformed
+// before they're cached by the informers.
+type transformingClient struct {
+	dynamic.Interface
+	transform func(obj *unstructured.Unstructured)
+}
+
+type transformingNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	transform func(obj *unstructured.Unstructured)
+}
+
+type transformingResource struct {
+	dynamic.ResourceInterface
+	transform func(obj *unstructured.Unstructured)
+}
+
+func newTransformingClient(client dynamic.Interface, transform func(obj *unstructured.Unstructured)) dynamic.Interface {
+	return &transformingClient{Interface: client, transform: transform}
+}
+
+func (c *transformingClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &transformingNamespaceableResource{
+		NamespaceableResourceInterface: c.Interface.Resource(resource),
+		transform:                      c.transform,
+	}
+}
+
+func (r *transformingNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &transformingResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), transform: r.transform}
+}
+
+func (r *transformingNamespaceableResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList,
+	error,
+) {
+	list, err := r.NamespaceableResourceInterface.List(ctx, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // This is a wrapper function.
+	}
+
+	return transformList(list, r.transform), nil
+}
+
+func (r *transformingNamespaceableResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.NamespaceableResourceInterface.Watch(ctx, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // This is a wrapper function.
+	}
+
+	return transformWatch(w, r.transform), nil
+}
+
+func (r *transformingResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := r.ResourceInterface.List(ctx, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // This is a wrapper function.
+	}
+
+	return transformList(list, r.transform), nil
+}
+
+func (r *transformingResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.ResourceInterface.Watch(ctx, opts)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // This is a wrapper function.
+	}
+
+	return transformWatch(w, r.transform), nil
+}
+
+func transformList(list *unstructured.UnstructuredList, transform func(obj *unstructured.Unstructured)) *unstructured.UnstructuredList {
+	for i := range list.Items {
+		transform(&list.Items[i])
+	}
+
+	return list
+}
+
+func transformWatch(w watch.Interface, transform func(obj *unstructured.Unstructured)) watch.Interface {
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		if obj, ok := in.Object.(*unstructured.Unstructured); ok {
+			transform(obj)
+		}
+
+		return in, true
+	})
+}
@@ -0,0 +1,190 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// cacheSnapshot is the persisted form of the informer caches, keyed by the watched resource type name as per SyncStatus.
+type cacheSnapshot map[string][]json.RawMessage
+
+// SaveCacheSnapshot persists the current contents of the informer caches to the configured CacheSnapshotPath so a
+// subsequent controller can start from them.
+func (c *Controller) SaveCacheSnapshot() error {
+	if c.cacheSnapshotPath == "" {
+		return errors.New("no CacheSnapshotPath is configured")
+	}
+
+	snapshot := cacheSnapshot{}
+
+	for _, w := range c.resourceWatchers {
+		key := resourceKey(w.resource, w.cluster)
+
+		for _, obj := range w.ListResources(w.resourceType, nil) {
+			data, err := json.Marshal(obj)
+			if err != nil {
+				return errors.Wrapf(err, "error marshalling a cached %s resource", key)
+			}
+
+			snapshot[key] = append(snapshot[key], data)
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling the cache snapshot")
+	}
+
+	tmpPath := c.cacheSnapshotPath + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return errors.Wrapf(err, "error writing the cache snapshot to %q", tmpPath)
+	}
+
+	return errors.Wrapf(os.Rename(tmpPath, c.cacheSnapshotPath), "error renaming the cache snapshot to %q", c.cacheSnapshotPath)
+}
+
+// loadCacheSnapshot notifies the handlers of the creation of the objects in the persisted cache snapshot, if any, and
+// tracks those successfully notified in the warm cache until they're reconciled with the live state. A missing or invalid
+// snapshot results in a cold start.
+func (c *Controller) loadCacheSnapshot() {
+	data, err := os.ReadFile(c.cacheSnapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		c.log.Infof("No cache snapshot found at %q", c.cacheSnapshotPath)
+		return
+	}
+
+	if err != nil {
+		c.log.Error(err, "Error reading the cache snapshot")
+		return
+	}
+
+	snapshot := cacheSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		c.log.Error(err, "Error unmarshalling the cache snapshot")
+		return
+	}
+
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	defer c.beginEvent()()
+
+	for _, w := range c.resourceWatchers {
+		key := resourceKey(w.resource, w.cluster)
+		objects := map[string]runtime.Object{}
+
+		for _, raw := range snapshot[key] {
+			obj := w.resourceType.DeepCopyObject()
+			if err := json.Unmarshal(raw, obj); err != nil {
+				c.eventLog.Errorf(err, "Error unmarshalling a %s resource from the cache snapshot", key)
+				continue
+			}
+
+			objKey, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				c.eventLog.Errorf(err, "Error obtaining the key of a %s resource from the cache snapshot", key)
+				continue
+			}
+
+			warm := obj.DeepCopyObject()
+
+			// If the notification failed, the object isn't tracked so its live event is notified as created and retried.
+			if !w.unserializedHandler.OnCreate(obj, 0) {
+				objects[objKey] = warm
+			}
+		}
+
+		c.eventLog.Infof("Loaded %d %s resources from the cache snapshot", len(objects), key)
+
+		c.warmCache[key] = objects
+	}
+}
+
+// warmCacheHandler wraps the given watcher event handler to reconcile the live objects with those loaded from the cache
+// snapshot. A live object that's unchanged from its snapshot isn't notified again and a changed one is notified as updated.
+// The returned handler must be invoked with the syncMutex held.
+func (c *Controller) warmCacheHandler(key string, handler watcher.EventHandler) watcher.EventHandler {
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: func(obj runtime.Object, numRequeues int) bool {
+			objKey, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return handler.OnCreate(obj, numRequeues)
+			}
+
+			warm, found := c.warmCache[key][objKey]
+			if !found {
+				return handler.OnCreate(obj, numRequeues)
+			}
+
+			if !equality.Semantic.DeepEqual(warm, obj) && handler.OnUpdate(obj, numRequeues) {
+				return true
+			}
+
+			delete(c.warmCache[key], objKey)
+
+			return false
+		},
+		OnUpdateFunc: handler.OnUpdate,
+		OnDeleteFunc: handler.OnDelete,
+	}
+}
+
+// reconcileWarmCache notifies the handlers of the deletion of the objects loaded from the cache snapshot that no longer
+// exist once the given watcher has synced.
+func (c *Controller) reconcileWarmCache(w *resourceWatcher) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	key := resourceKey(w.resource, w.cluster)
+
+	objects := c.warmCache[key]
+	if len(objects) == 0 {
+		return
+	}
+
+	defer c.beginEvent()()
+
+	live := map[string]bool{}
+
+	for _, obj := range w.ListResources(w.resourceType, nil) {
+		if objKey, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			live[objKey] = true
+		}
+	}
+
+	for objKey, obj := range objects {
+		if live[objKey] {
+			continue
+		}
+
+		c.eventLog.Infof("The %s resource %q from the cache snapshot no longer exists", key, objKey)
+
+		delete(objects, objKey)
+		w.unserializedHandler.OnDelete(obj, 0)
+	}
+}
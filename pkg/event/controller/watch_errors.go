@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// errorReportingClient wraps the dynamic client used by a single watcher to report the errors returned by the list and
+// watch requests issued by its informer, as well as the error events received on the watch.
+type errorReportingClient struct {
+	dynamic.Interface
+	report func(err error)
+}
+
+type errorReportingNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	gvr    schema.GroupVersionResource
+	report func(err error)
+}
+
+type errorReportingResource struct {
+	dynamic.ResourceInterface
+	gvr       schema.GroupVersionResource
+	namespace string
+	report    func(err error)
+}
+
+func newErrorReportingClient(client dynamic.Interface, report func(err error)) dynamic.Interface {
+	return &errorReportingClient{Interface: client, report: report}
+}
+
+func (c *errorReportingClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &errorReportingNamespaceableResource{
+		NamespaceableResourceInterface: c.Interface.Resource(resource),
+		gvr:                            resource,
+		report:                         c.report,
+	}
+}
+
+func (r *errorReportingNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &errorReportingResource{
+		ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns),
+		gvr:               r.gvr,
+		namespace:         ns,
+		report:            r.report,
+	}
+}
+
+func (r *errorReportingNamespaceableResource) List(ctx context.Context, opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	list, err := r.NamespaceableResourceInterface.List(ctx, opts)
+	reportListError(err, r.gvr, "", r.report)
+
+	return list, err //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *errorReportingNamespaceableResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.NamespaceableResourceInterface.Watch(ctx, opts)
+
+	return reportingWatch(w, err, r.gvr, "", r.report)
+}
+
+func (r *errorReportingResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := r.ResourceInterface.List(ctx, opts)
+	reportListError(err, r.gvr, r.namespace, r.report)
+
+	return list, err //nolint:wrapcheck // This is a wrapper function.
+}
+
+func (r *errorReportingResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := r.ResourceInterface.Watch(ctx, opts)
+
+	return reportingWatch(w, err, r.gvr, r.namespace, r.report)
+}
+
+func reportListError(err error, gvr schema.GroupVersionResource, namespace string, report func(err error)) {
+	if err != nil {
+		report(errors.Wrap(withRBACGuidance(err, "list", gvr, namespace), "error listing"))
+	}
+}
+
+func reportingWatch(w watch.Interface, err error, gvr schema.GroupVersionResource, namespace string, report func(err error),
+) (watch.Interface, error) {
+	if err != nil {
+		report(errors.Wrap(withRBACGuidance(err, "watch", gvr, namespace), "error watching"))
+		return w, err //nolint:wrapcheck // This is a wrapper function.
+	}
+
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		if in.Type == watch.Error {
+			report(errors.Wrap(withRBACGuidance(apierrors.FromObject(in.Object), "watch", gvr, namespace),
+				"error event received on the watch"))
+		}
+
+		return in, true
+	}), nil
+}
+
+// withRBACGuidance wraps the given error, if it's Forbidden, with guidance on the RBAC rule the controller's service
+// account needs to issue the given request on the given resource. Other errors are returned as is.
+func withRBACGuidance(err error, verb string, gvr schema.GroupVersionResource, namespace string) error {
+	if !apierrors.IsForbidden(err) {
+		return err
+	}
+
+	scope := "cluster-wide"
+	if namespace != "" {
+		scope = fmt.Sprintf("in namespace %q", namespace)
+	}
+
+	return errors.Wrapf(err, "not permitted to %s %q %s - the RBAC rules of the service account must allow the \"list\" "+
+		"and \"watch\" verbs for apiGroups: [%q], resources: [%q]", verb, gvr.Resource, scope, gvr.Group, gvr.Resource)
+}
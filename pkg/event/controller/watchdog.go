@@ -0,0 +1,127 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StalledEvent describes an event that has been in flight for longer than the configured StalledEventThreshold.
+type StalledEvent struct {
+	// Key identifies the event's object as per PendingRetries, eg "Endpoint/submariner-operator/cluster1-endpoint".
+	Key string
+
+	// Handlers are the names of the handlers processing the event, as "<registry>/<handler>". It's empty if the event
+	// is stalled in the controller itself.
+	Handlers []string
+
+	// Duration is the time the event had been in flight when it was detected as stalled.
+	Duration time.Duration
+}
+
+// inFlightEvent tracks the event currently being processed. Guarded by the watchdog's mutex.
+type inFlightEvent struct {
+	key      string
+	since    time.Time
+	reported bool
+}
+
+type watchdog struct {
+	mutex    sync.Mutex
+	inFlight *inFlightEvent
+}
+
+// watchdogHandler wraps the given watcher event handler to track the event in flight for the watchdog.
+func (c *Controller) watchdogHandler(resourceKey string, handler watcher.EventHandler) watcher.EventHandler {
+	tracked := func(f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			key, _ := cache.MetaNamespaceKeyFunc(obj)
+
+			c.watchdog.mutex.Lock()
+			c.watchdog.inFlight = &inFlightEvent{key: resourceKey + "/" + key, since: c.clock.Now()}
+			c.watchdog.mutex.Unlock()
+
+			defer func() {
+				c.watchdog.mutex.Lock()
+				c.watchdog.inFlight = nil
+				c.watchdog.mutex.Unlock()
+			}()
+
+			return f(obj, numRequeues)
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: tracked(handler.OnCreate),
+		OnUpdateFunc: tracked(handler.OnUpdate),
+		OnDeleteFunc: tracked(handler.OnDelete),
+	}
+}
+
+// runWatchdog periodically checks whether the event in flight has exceeded the stalled event threshold, until the given
+// stop channel is closed.
+func (c *Controller) runWatchdog(stopCh <-chan struct{}) {
+	ticker := c.clock.NewTicker(c.stalledEventThreshold / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.checkStalledEvent()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// checkStalledEvent reports the event in flight, once, if it has exceeded the stalled event threshold.
+func (c *Controller) checkStalledEvent() {
+	c.watchdog.mutex.Lock()
+
+	inFlight := c.watchdog.inFlight
+	if inFlight == nil || inFlight.reported || c.clock.Since(inFlight.since) < c.stalledEventThreshold {
+		c.watchdog.mutex.Unlock()
+		return
+	}
+
+	inFlight.reported = true
+
+	c.watchdog.mutex.Unlock()
+
+	stalled := StalledEvent{
+		Key:      inFlight.key,
+		Handlers: c.handlers.InFlightHandlers(),
+		Duration: c.clock.Since(inFlight.since),
+	}
+
+	c.metrics.stalledEvents.Add(1)
+
+	c.log.Warningf("The event for %q has been in flight for %v, exceeding the threshold of %v - in-flight handlers: [%s]",
+		stalled.Key, stalled.Duration, c.stalledEventThreshold, strings.Join(stalled.Handlers, ", "))
+
+	if c.onEventStalled != nil {
+		c.onEventStalled(stalled)
+	}
+}
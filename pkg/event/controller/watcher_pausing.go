@@ -0,0 +1,228 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/set"
+)
+
+// eventBuffer holds the events received by a paused or gated watcher, coalesced by object key as for the keyed queue,
+// until they're dispatched. Events that fail once dispatched remain buffered, ahead of any events received since for the
+// same object, until they're retried.
+type eventBuffer struct {
+	// keys are the keys of the buffered objects in the order they were first buffered.
+	keys   []string
+	events map[string][]*keyedEvent
+	// retries tracks the requeues of the failed events by object key.
+	retries workqueue.RateLimiter
+	// retryScheduled holds the keys of the objects whose retry is scheduled.
+	retryScheduled set.Set[string]
+}
+
+func newEventBuffer() *eventBuffer {
+	return &eventBuffer{
+		events:         map[string][]*keyedEvent{},
+		retries:        workqueue.DefaultControllerRateLimiter(),
+		retryScheduled: set.New[string](),
+	}
+}
+
+func (b *eventBuffer) len() int {
+	n := 0
+	for _, events := range b.events {
+		n += len(events)
+	}
+
+	return n
+}
+
+// has returns whether events are buffered for the object with the given key.
+func (b *eventBuffer) has(key string) bool {
+	_, found := b.events[key]
+	return found
+}
+
+func (b *eventBuffer) add(e *keyedEvent, superseded func(obj runtime.Object)) {
+	key := objectKey(e.obj)
+
+	if !b.has(key) {
+		b.keys = append(b.keys, key)
+	}
+
+	b.events[key] = mergeEvent(b.events[key], e, superseded)
+}
+
+// PauseWatcher pauses the dispatch of the events of the given watched resource type, eg NodeResource, from all clusters
+// while the events of the other resource types keep flowing, eg during a mass migration of Node labels. The events
+// received while paused are buffered until ResumeWatcher is called, coalesced per object as for KeyFunc. Pausing a paused
+// resource type has no effect.
+func (c *Controller) PauseWatcher(resource string) error {
+	return c.forEachWatcherOf(resource, func(w *resourceWatcher) {
+		w.pauseMutex.Lock()
+		defer w.pauseMutex.Unlock()
+
+		w.paused = true
+	})
+}
+
+// ResumeWatcher resumes the dispatch of the events of the given resource type paused via PauseWatcher. The buffered
+// events are dispatched first, in the order they were received, unless the events are still gated as the controller
+// isn't the leader. Failed buffered events are retried with backoff, as are the events received from the watchers, and
+// subsequent events for the same objects are held back until they succeed while the events for other objects flow. Must not be called from a Handler.
+func (c *Controller) ResumeWatcher(resource string) error {
+	return c.forEachWatcherOf(resource, func(w *resourceWatcher) {
+		w.pauseMutex.Lock()
+		defer w.pauseMutex.Unlock()
+
+		w.paused = false
+
+		if !w.gated {
+			if n := w.buffered.len(); n > 0 {
+				c.log.Infof("Dispatching %d buffered events of the resumed %s watcher%s", n, resource, forCluster(w.cluster))
+			}
+
+			c.dispatchBuffered(w)
+		}
+	})
+}
+
+// dispatchBuffered dispatches the buffered events of the given watcher in the order they were received. The failed events
+// remain buffered, along with the subsequent events for the same objects, and their retry is scheduled. Must be called
+// with the watcher's pauseMutex held.
+func (c *Controller) dispatchBuffered(w *resourceWatcher) {
+	keys := w.buffered.keys
+	w.buffered.keys = nil
+
+	for _, key := range keys {
+		if !c.dispatchBufferedOf(w, key) {
+			w.buffered.keys = append(w.buffered.keys, key)
+		}
+	}
+}
+
+// dispatchBufferedOf dispatches the buffered events of the given watcher for the object with the given key and returns
+// whether they were all dispatched. Otherwise the retry of the failed event is scheduled with backoff. Must be called with
+// the watcher's pauseMutex held.
+func (c *Controller) dispatchBufferedOf(w *resourceWatcher, key string) bool {
+	b := w.buffered
+	events := b.events[key]
+
+	for len(events) > 0 && !events[0].dispatch(events[0].obj, b.retries.NumRequeues(key)) {
+		b.retries.Forget(key)
+		events = events[1:]
+	}
+
+	if len(events) == 0 {
+		delete(b.events, key)
+		return true
+	}
+
+	b.events[key] = events
+
+	if !b.retryScheduled.Has(key) {
+		b.retryScheduled.Insert(key)
+
+		// The retry is run in its own goroutine as a clock may invoke the function synchronously with its own lock held, which
+		// the dispatch would then wait for.
+		c.clock.AfterFunc(b.retries.When(key), func() {
+			go c.retryBuffered(w, key)
+		})
+	}
+
+	return false
+}
+
+// retryBuffered re-dispatches the buffered events of the given watcher for the object with the given key whose retry was
+// scheduled, unless the watcher was paused or gated again meanwhile, in which case they're dispatched once it's resumed and
+// ungated.
+func (c *Controller) retryBuffered(w *resourceWatcher, key string) {
+	w.pauseMutex.Lock()
+	defer w.pauseMutex.Unlock()
+
+	w.buffered.retryScheduled.Delete(key)
+
+	if w.paused || w.gated || !w.buffered.has(key) {
+		return
+	}
+
+	if state := c.lifecycle.get(); state == LifecycleStopping || state == LifecycleStopped {
+		return
+	}
+
+	if !c.dispatchBufferedOf(w, key) {
+		return
+	}
+
+	for i, k := range w.buffered.keys {
+		if k == key {
+			w.buffered.keys = append(w.buffered.keys[:i], w.buffered.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (c *Controller) forEachWatcherOf(resource string, f func(w *resourceWatcher)) error {
+	found := false
+
+	for _, w := range c.resourceWatchers {
+		if w.resource == resource {
+			found = true
+
+			f(w)
+		}
+	}
+
+	if !found {
+		return errors.Errorf("the %s resource is not watched", resource)
+	}
+
+	return nil
+}
+
+// pausingHandler wraps the given watcher event handler to buffer the events received while the given watcher is paused
+// or gated, or still has buffered events for the same object awaiting a retry, so the events for an object remain ordered.
+// The buffered events are dispatched to the given handler once it's resumed and ungated.
+func (c *Controller) pausingHandler(w *resourceWatcher, handler watcher.EventHandler) watcher.EventHandler {
+	pausing := func(operation string, f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			w.pauseMutex.Lock()
+
+			if w.paused || w.gated || w.buffered.has(objectKey(obj)) {
+				w.buffered.add(&keyedEvent{operation: operation, obj: obj, dispatch: f}, c.metrics.forgetReceived)
+				w.pauseMutex.Unlock()
+
+				return false
+			}
+
+			w.pauseMutex.Unlock()
+
+			return f(obj, numRequeues)
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: pausing(CreateOperation, handler.OnCreate),
+		OnUpdateFunc: pausing(UpdateOperation, handler.OnUpdate),
+		OnDeleteFunc: pausing(DeleteOperation, handler.OnDelete),
+	}
+}
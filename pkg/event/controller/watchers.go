@@ -0,0 +1,611 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	resourceUtil "github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	"github.com/submariner-io/admiral/pkg/watcher"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/set"
+)
+
+const (
+	EndpointResource              = "Endpoint"
+	NodeResource                  = "Node"
+	ClusterGlobalEgressIPResource = "ClusterGlobalEgressIP"
+)
+
+// resourceScope specifies the namespaces in which a resource type is watched.
+type resourceScope int
+
+const (
+	// namespaceScoped resources are only watched in the namespace specified by the SUBMARINER_NAMESPACE environment variable.
+	namespaceScoped resourceScope = iota
+
+	// clusterScoped resources are watched across the cluster, ie the configured namespace is ignored. This also applies to
+	// namespaced resources that are watched in all namespaces.
+	clusterScoped
+)
+
+// resourceWatcher watches a single resource type in a cluster so each type's informer cache can sync independently.
+type resourceWatcher struct {
+	watcher.Interface
+	resource     string
+	cluster      string
+	synced       atomic.Bool
+	resourceType runtime.Object
+	handler      watcher.EventHandler
+	// unserializedHandler is the handler invoked by handler once the syncMutex is held.
+	unserializedHandler watcher.EventHandler
+
+	// pauseMutex guards paused, gated and buffered. The events are buffered while the watcher is paused via PauseWatcher or
+	// gated as the controller isn't the leader, and while previously buffered events for the same object await a retry.
+	pauseMutex sync.Mutex
+	paused     bool
+	gated      bool
+	buffered   *eventBuffer
+
+	// processed holds the keys of the processed objects until the initial events are awaited if the resource type is
+	// prioritized, otherwise it's nil. Guarded by processedMutex.
+	processed      set.Set[string]
+	processedMutex sync.Mutex
+}
+
+func (c *Controller) addClusterWatchers(cluster *ClusterConfig, config *Config) error {
+	var err error
+
+	if cluster.Client == nil && cluster.RestConfig == nil {
+		return errors.Errorf("either a Client or a RestConfig must be specified%s", forCluster(cluster.Name))
+	}
+
+	if cluster.RestMapper == nil && cluster.RestConfig == nil {
+		return errors.Errorf("either a RestMapper or a RestConfig must be specified%s", forCluster(cluster.Name))
+	}
+
+	restMapper := cluster.RestMapper
+	if restMapper == nil {
+		restMapper, err = util.BuildRestMapper(cluster.RestConfig)
+		if err != nil {
+			return errors.Wrap(err, "error building the REST mapper")
+		}
+	}
+
+	if c.restMapper == nil {
+		c.restMapper = restMapper
+	}
+
+	client := cluster.Client
+	if client == nil {
+		client, err = dynamic.NewForConfig(cluster.RestConfig)
+		if err != nil {
+			return errors.Wrap(err, "error creating dynamic client")
+		}
+	}
+
+	if cluster.InformerFactory != nil {
+		c.informerFactories[cluster.Name] = cluster.InformerFactory
+	}
+
+	if config.ListPageSize > 0 {
+		client = newPagedClient(client, config.ListPageSize)
+	}
+
+	watcherConfig := watcher.Config{
+		Scheme:     config.Scheme,
+		RestConfig: cluster.RestConfig,
+		Client:     client,
+		RestMapper: restMapper,
+	}
+
+	c.watcherConfigs[cluster.Name] = watcherConfig
+
+	handleRemovedEndpoint := c.handleRemovedEndpoint
+	if config.ConfirmRemoteEndpointDeletes {
+		handleRemovedEndpoint = c.confirmingRemoteEndpointRemoval(client, handleRemovedEndpoint)
+	}
+
+	err = c.addResourceWatcher(EndpointResource, cluster.Name, namespaceScoped, &watcher.ResourceConfig{
+		ResourceType:        &subv1.Endpoint{},
+		ResourcesEquivalent: c.isEndpointEquivalent,
+		Handler: watcher.EventHandlerFuncs{
+			OnCreateFunc: withOriginCluster(cluster.Name, c.handleCreatedEndpoint),
+			OnUpdateFunc: withOriginCluster(cluster.Name, c.handleUpdatedEndpoint),
+			OnDeleteFunc: withOriginCluster(cluster.Name, handleRemovedEndpoint),
+		},
+	}, watcherConfig)
+	if err != nil {
+		return err
+	}
+
+	err = c.addResourceWatcher(NodeResource, cluster.Name, clusterScoped, &watcher.ResourceConfig{
+		ResourceType:        &k8sv1.Node{},
+		ResourcesEquivalent: c.isNodeEquivalent,
+		Handler: watcher.EventHandlerFuncs{
+			OnCreateFunc: withOriginCluster(cluster.Name, c.handleCreatedNode),
+			OnUpdateFunc: withOriginCluster(cluster.Name, c.handleUpdatedNode),
+			OnDeleteFunc: withOriginCluster(cluster.Name, c.handleRemovedNode),
+		},
+	}, watcherConfig)
+	if err != nil || !config.WatchClusterGlobalEgressIPs {
+		return err
+	}
+
+	return c.addOptionalResourceWatcher(ClusterGlobalEgressIPResource, cluster.Name, clusterScoped, &watcher.ResourceConfig{
+		ResourceType: &subv1.ClusterGlobalEgressIP{},
+		Handler: watcher.EventHandlerFuncs{
+			OnCreateFunc: withOriginCluster(cluster.Name, c.handleCreatedClusterGlobalEgressIP),
+			OnUpdateFunc: withOriginCluster(cluster.Name, c.handleUpdatedClusterGlobalEgressIP),
+			OnDeleteFunc: withOriginCluster(cluster.Name, c.handleRemovedClusterGlobalEgressIP),
+		},
+	}, watcherConfig)
+}
+
+func forCluster(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" for cluster %q", name)
+}
+
+// hasResource returns whether or not the given kind is known to the RESTMapper, ie its CRD is installed.
+func hasResource(restMapper meta.RESTMapper, gvk schema.GroupVersionKind) bool {
+	_, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
+
+// objectKind returns the kind of the given object as registered in the given scheme or, if nil, the default scheme.
+func objectKind(objScheme *runtime.Scheme, obj runtime.Object) (schema.GroupVersionKind, error) {
+	if objScheme == nil {
+		objScheme = scheme.Scheme
+	}
+
+	gvks, _, err := objScheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, errors.Wrapf(err, "error determining the kind of %T", obj)
+	}
+
+	return gvks[0], nil
+}
+
+// addOptionalResourceWatcher adds a watcher for the given resource, as per addResourceWatcher, unless its type isn't known
+// to the RESTMapper, eg its CRD isn't installed, in which case the watcher is skipped with a warning.
+func (c *Controller) addOptionalResourceWatcher(resource, cluster string, scope resourceScope, resourceConfig *watcher.ResourceConfig,
+	config watcher.Config,
+) error {
+	gvk, err := objectKind(config.Scheme, resourceConfig.ResourceType)
+	if err != nil {
+		return errors.Wrapf(err, "error determining the kind of the %s resource", resource)
+	}
+
+	if !hasResource(config.RestMapper, gvk) {
+		c.log.Warningf("The %s resource is not installed%s - not watching it", resource, forCluster(cluster))
+		return nil
+	}
+
+	return c.addResourceWatcher(resource, cluster, scope, resourceConfig, config)
+}
+
+func (c *Controller) addResourceWatcher(resource, cluster string, scope resourceScope, resourceConfig *watcher.ResourceConfig,
+	config watcher.Config,
+) error {
+	if resourceConfig.SourceNamespace == "" {
+		resourceConfig.SourceNamespace = c.sourceNamespace(scope)
+	}
+	resourceConfig.Name = fmt.Sprintf("%s watcher for %s registry", resource, c.handlers.GetName())
+	if cluster != "" {
+		resourceConfig.Name += " in cluster " + cluster
+	}
+
+	if c.env.EventTrace {
+		resourceConfig.Handler = c.tracedHandler(resourceConfig.Handler)
+	}
+
+	key := resourceKey(resource, cluster)
+
+	if c.warmCache != nil {
+		resourceConfig.Handler = c.warmCacheHandler(key, resourceConfig.Handler)
+	}
+
+	if c.stalledEventThreshold > 0 {
+		resourceConfig.Handler = c.watchdogHandler(key, resourceConfig.Handler)
+	}
+
+	unserializedHandler := resourceConfig.Handler
+	resourceConfig.Handler = c.serializedHandler(resource, resourceConfig.Handler)
+	config.Client = newTransformingClient(config.Client, c.cacheTransformFor(resource))
+	config.Client = newReconnectDetectingClient(config.Client, func() {
+		c.handleWatchReconnected(key)
+	})
+
+	config.Client = newErrorReportingClient(config.Client, func(err error) {
+		c.handleWatchError(key, err)
+	})
+
+	rw := &resourceWatcher{
+		resource:            resource,
+		cluster:             cluster,
+		resourceType:        resourceConfig.ResourceType,
+		handler:             resourceConfig.Handler,
+		unserializedHandler: unserializedHandler,
+		gated:               c.isGatedOnLeadership(),
+		buffered:            newEventBuffer(),
+	}
+
+	if c.isPrioritized(resource) {
+		resourceConfig.Handler = rw.processedTrackingHandler(resourceConfig.Handler)
+	}
+
+	resourceConfig.Handler = c.retryTracker.trackingHandler(key, resourceConfig.Handler)
+
+	if c.keyedQueue != nil {
+		resourceConfig.Handler = c.keyedQueue.queuingHandler(resourceConfig.Handler)
+	}
+
+	resourceConfig.Handler = c.pausingHandler(rw, resourceConfig.Handler)
+
+	if c.recorder != nil {
+		resourceConfig.Handler = c.recorder.recordingHandler(resource, cluster, resourceConfig.Handler)
+	}
+
+	resourceConfig.Handler = c.receivingHandler(resourceConfig.Handler)
+
+	config.ResourceConfigs = []watcher.ResourceConfig{*resourceConfig}
+
+	var err error
+
+	if factory := c.informerFactories[cluster]; factory != nil {
+		rw.Interface, err = newInformerWatcher(factory, &config, c.log)
+	} else {
+		rw.Interface, err = watcher.New(&config)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "error creating the %s watcher", resource)
+	}
+
+	c.resourceWatchers = append(c.resourceWatchers, rw)
+
+	return nil
+}
+
+// sourceNamespace returns the namespace in which resources of the given scope are watched.
+func (c *Controller) sourceNamespace(scope resourceScope) string {
+	if scope == clusterScoped {
+		return k8sv1.NamespaceAll
+	}
+
+	return c.env.Namespace
+}
+
+// serializedHandler wraps the given watcher event handler to process each notified object under the syncMutex as a source
+// event with its own correlation ID. The time each event waited since its receipt and its processing time are observed in
+// the metrics.
+func (c *Controller) serializedHandler(resource string, handler watcher.EventHandler) watcher.EventHandler {
+	serialized := func(f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			c.syncMutex.Lock()
+			defer c.syncMutex.Unlock()
+
+			dispatched := c.clock.Now()
+			c.metrics.recordDispatched(resource, obj, dispatched)
+
+			defer func() {
+				c.metrics.recordProcessing(resource, c.clock.Since(dispatched))
+			}()
+
+			defer c.beginEvent()()
+
+			if c.errorLogDedup > 0 {
+				if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+					c.eventLog = log.Logger{Logger: c.eventLog.WithValues(eventKeyLogKey, resource+"/"+key)}
+				}
+			}
+
+			return f(obj, numRequeues)
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: serialized(handler.OnCreate),
+		OnUpdateFunc: serialized(handler.OnUpdate),
+		OnDeleteFunc: serialized(handler.OnDelete),
+	}
+}
+
+// receivingHandler wraps the given watcher event handler to record the time each object is received from the watcher, in
+// order to observe the time it waits until it's dispatched. The times are no longer recorded once the controller is
+// stopping as the events may not be dispatched.
+func (c *Controller) receivingHandler(handler watcher.EventHandler) watcher.EventHandler {
+	receiving := func(f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			if state := c.lifecycle.get(); state != LifecycleStopping && state != LifecycleStopped {
+				c.metrics.recordReceived(obj, c.clock.Now())
+			}
+
+			return f(obj, numRequeues)
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: receiving(handler.OnCreate),
+		OnUpdateFunc: receiving(handler.OnUpdate),
+		OnDeleteFunc: receiving(handler.OnDelete),
+	}
+}
+
+// tracedHandler wraps the given watcher event handler to trace whether each notified object is requeued.
+func (c *Controller) tracedHandler(handler watcher.EventHandler) watcher.EventHandler {
+	traced := func(op string, f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+		return func(obj runtime.Object, numRequeues int) bool {
+			requeue := f(obj, numRequeues)
+			c.trace("%s event for %T %q processed (requeue count %d) - requeue: %t", op, obj, resourceName(obj), numRequeues, requeue)
+
+			return requeue
+		}
+	}
+
+	return watcher.EventHandlerFuncs{
+		OnCreateFunc: traced("Create", handler.OnCreate),
+		OnUpdateFunc: traced("Update", handler.OnUpdate),
+		OnDeleteFunc: traced("Delete", handler.OnDelete),
+	}
+}
+
+// OriginClusterAnnotation is the annotation set on the notified objects to the name of their origin cluster, if the
+// controller is configured with multiple Clusters.
+const OriginClusterAnnotation = "submariner.io/origin-cluster"
+
+// withOriginCluster wraps the given watcher event function to annotate each notified object with the given cluster name.
+func withOriginCluster(cluster string, f func(obj runtime.Object, numRequeues int) bool) func(runtime.Object, int) bool {
+	if cluster == "" {
+		return f
+	}
+
+	return func(obj runtime.Object, numRequeues int) bool {
+		objMeta := resourceUtil.MustToMeta(obj)
+
+		annotations := objMeta.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[OriginClusterAnnotation] = cluster
+		objMeta.SetAnnotations(annotations)
+
+		return f(obj, numRequeues)
+	}
+}
+
+// OriginCluster returns the name of the cluster from which the given notified object originated, if the controller is
+// configured with multiple Clusters.
+func OriginCluster(obj metav1.Object) string {
+	return obj.GetAnnotations()[OriginClusterAnnotation]
+}
+
+func (w *resourceWatcher) start(stopCh <-chan struct{}) error {
+	if err := w.Start(stopCh); err != nil {
+		return errors.Wrapf(err, "error starting the %s watcher", w.resource)
+	}
+
+	// The watcher returns without error if stopped while waiting for its cache to sync.
+	select {
+	case <-stopCh:
+		return errors.Errorf("the %s watcher was stopped before its cache synced", w.resource)
+	default:
+	}
+
+	w.synced.Store(true)
+
+	return nil
+}
+
+func (c *Controller) startWatcher(w *resourceWatcher, stopCh <-chan struct{}) error {
+	if err := w.start(stopCh); err != nil {
+		return err
+	}
+
+	if c.warmCache != nil {
+		c.reconcileWarmCache(w)
+	}
+
+	if w.resource == EndpointResource && c.isSynced(EndpointResource) {
+		c.completeInitialSync()
+		c.completeRestore()
+	}
+
+	if w.resource == NodeResource && c.isSynced(NodeResource) {
+		c.refreshSoleGateway()
+	}
+
+	return nil
+}
+
+// isSynced returns whether or not the informer caches for the given resource type have synced in all clusters.
+func (c *Controller) isSynced(resource string) bool {
+	for _, w := range c.resourceWatchers {
+		if w.resource == resource && !w.synced.Load() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Controller) startWatchers(stopCh <-chan struct{}) error {
+	for _, w := range c.resourceWatchers {
+		if err := c.startWatcher(w, stopCh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startWatchersPartially starts all the watchers concurrently and returns as soon as one has synced. The remaining
+// watchers continue to sync in the background.
+func (c *Controller) startWatchersPartially(stopCh <-chan struct{}) error {
+	results := make(chan error, len(c.resourceWatchers))
+
+	for _, w := range c.resourceWatchers {
+		go func(w *resourceWatcher) {
+			err := c.startWatcher(w, stopCh)
+			if err != nil {
+				c.log.Error(err, "Error starting watcher")
+			}
+
+			results <- err
+		}(w)
+	}
+
+	var errs []error
+
+	for range c.resourceWatchers {
+		err := <-results
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return k8serrors.NewAggregate(errs)
+}
+
+// SyncStatus returns whether or not the informer cache has synced for each watched resource type, keyed by the
+// resource type name. If multiple Clusters are configured, the keys are prefixed by the cluster name, eg "east/Node".
+func (c *Controller) SyncStatus() map[string]bool {
+	status := map[string]bool{}
+
+	for _, w := range c.resourceWatchers {
+		status[resourceKey(w.resource, w.cluster)] = w.synced.Load()
+	}
+
+	return status
+}
+
+// resourceKey returns the name of the given resource type, prefixed by the given cluster name if specified.
+func resourceKey(resource, cluster string) string {
+	if cluster == "" {
+		return resource
+	}
+
+	return cluster + "/" + resource
+}
+
+func (c *Controller) handleWatchReconnected(key string) {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	// The watchers may still be running while the controller is stopping.
+	if c.lifecycle.get() != LifecycleRunning {
+		return
+	}
+
+	defer c.beginEvent()()
+
+	c.eventLog.Infof("The watch for the %s resource was re-established", key)
+
+	if err := c.handlers.WatchReconnected(key); err != nil {
+		c.eventLog.Error(err, "Error handling the watch reconnection")
+	}
+}
+
+// handleWatchError handles an error from a list or watch request issued by the informer for the given resource type.
+// Forbidden errors are logged, as they persist until the RBAC rules are fixed, and all errors are passed to OnWatchError.
+func (c *Controller) handleWatchError(key string, err error) {
+	if apierrors.IsForbidden(err) {
+		c.log.Errorf(err, "Unable to watch the %s resource", key)
+	}
+
+	if c.onWatchError != nil {
+		c.onWatchError(key, err)
+	}
+}
+
+// listResources returns the cached objects of the given resource type from all clusters.
+func (c *Controller) listResources(resource string, ofType runtime.Object) []runtime.Object {
+	var objs []runtime.Object
+
+	for _, w := range c.resourceWatchers {
+		if w.resource == resource {
+			objs = append(objs, w.ListResources(ofType, nil)...)
+		}
+	}
+
+	return objs
+}
+
+// Resync re-delivers the cached objects of the given watched resource type, eg NodeResource, from all clusters to the
+// handlers as update events. Failed re-deliveries aren't retried but are reported in the returned error.
+func (c *Controller) Resync(resource string) error {
+	found := false
+	failed := 0
+
+	for _, w := range c.resourceWatchers {
+		if w.resource != resource {
+			continue
+		}
+
+		found = true
+
+		for _, obj := range w.ListResources(w.resourceType, nil) {
+			if w.handler.OnUpdate(obj, 0) {
+				failed++
+			}
+		}
+	}
+
+	if !found {
+		return errors.Errorf("the %s resource is not watched", resource)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("failed to handle %d resynced %s resources", failed, resource)
+	}
+
+	return nil
+}
+
+// ResyncEndpoints re-delivers the cached Endpoints to the handlers.
+func (c *Controller) ResyncEndpoints() error {
+	return c.Resync(EndpointResource)
+}
+
+// ResyncNodes re-delivers the cached Nodes to the handlers.
+func (c *Controller) ResyncNodes() error {
+	return c.Resync(NodeResource)
+}
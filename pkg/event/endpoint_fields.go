@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"reflect"
+
+	submV1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"k8s.io/utils/set"
+)
+
+// EndpointField identifies a field of an Endpoint whose changes an EndpointUpdateSubscriber can subscribe to.
+type EndpointField string
+
+const (
+	EndpointSubnets EndpointField = "Subnets"
+	// EndpointAddresses covers the public, private and health check IPs.
+	EndpointAddresses EndpointField = "Addresses"
+	// EndpointBackend covers the backend type and its config.
+	EndpointBackend    EndpointField = "Backend"
+	EndpointNATEnabled EndpointField = "NATEnabled"
+	EndpointCableName  EndpointField = "CableName"
+	EndpointHostname   EndpointField = "Hostname"
+)
+
+// changedEndpointFields returns the fields that differ between the given previous and updated Endpoint.
+func changedEndpointFields(oldEndpoint, newEndpoint *submV1.Endpoint) set.Set[EndpointField] {
+	changed := set.New[EndpointField]()
+	oldSpec, newSpec := &oldEndpoint.Spec, &newEndpoint.Spec
+
+	if !reflect.DeepEqual(oldSpec.Subnets, newSpec.Subnets) {
+		changed.Insert(EndpointSubnets)
+	}
+
+	if oldSpec.PublicIP != newSpec.PublicIP || oldSpec.PrivateIP != newSpec.PrivateIP || oldSpec.HealthCheckIP != newSpec.HealthCheckIP {
+		changed.Insert(EndpointAddresses)
+	}
+
+	if oldSpec.Backend != newSpec.Backend || !reflect.DeepEqual(oldSpec.BackendConfig, newSpec.BackendConfig) {
+		changed.Insert(EndpointBackend)
+	}
+
+	if oldSpec.NATEnabled != newSpec.NATEnabled {
+		changed.Insert(EndpointNATEnabled)
+	}
+
+	if oldSpec.CableName != newSpec.CableName {
+		changed.Insert(EndpointCableName)
+	}
+
+	if oldSpec.Hostname != newSpec.Hostname {
+		changed.Insert(EndpointHostname)
+	}
+
+	return changed
+}
+
+// isSubscribedToEndpointUpdate returns whether the given Handler should be notified of an Endpoint update with the given
+// changed fields. A nil set indicates the changes are unknown.
+func isSubscribedToEndpointUpdate(h Handler, changed set.Set[EndpointField]) bool {
+	sh, ok := h.(EndpointUpdateSubscriber)
+	if !ok || changed == nil {
+		return true
+	}
+
+	for _, field := range sh.EndpointUpdateFields() {
+		if changed.Has(field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -19,19 +19,167 @@ limitations under the License.
 package event
 
 import (
+	"context"
+	"regexp"
+
 	submV1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const AnyNetworkPlugin = ""
 
+// Type identifies the kind of an event notification.
+type Type string
+
+const (
+	TransitionToNonGateway Type = "TransitionToNonGateway"
+	TransitionToGateway    Type = "TransitionToGateway"
+	LocalEndpointCreated   Type = "LocalEndpointCreated"
+	LocalEndpointUpdated   Type = "LocalEndpointUpdated"
+	LocalEndpointRemoved   Type = "LocalEndpointRemoved"
+	RemoteEndpointCreated  Type = "RemoteEndpointCreated"
+	RemoteEndpointUpdated  Type = "RemoteEndpointUpdated"
+	RemoteEndpointRemoved  Type = "RemoteEndpointRemoved"
+	NodeCreated            Type = "NodeCreated"
+	NodeUpdated            Type = "NodeUpdated"
+	NodeRemoved            Type = "NodeRemoved"
+	SubnetConflictDetected Type = "SubnetConflictDetected"
+	EndpointHealthChanged  Type = "EndpointHealthChanged"
+
+	ClusterGlobalEgressIPCreated Type = "ClusterGlobalEgressIPCreated"
+	ClusterGlobalEgressIPUpdated Type = "ClusterGlobalEgressIPUpdated"
+	ClusterGlobalEgressIPRemoved Type = "ClusterGlobalEgressIPRemoved"
+
+	WatchReconnected Type = "WatchReconnected"
+	InitialEndpoints Type = "InitialEndpoints"
+
+	LocalEndpointIPChanged  Type = "LocalEndpointIPChanged"
+	PreferredGatewayChanged Type = "PreferredGatewayChanged"
+	Heartbeat               Type = "Heartbeat"
+
+	EndpointBackendConfigChanged Type = "EndpointBackendConfigChanged"
+	SoleGatewayChanged           Type = "SoleGatewayChanged"
+	RemoteEndpointBatch          Type = "RemoteEndpointBatch"
+
+	LeadershipChanged   Type = "LeadershipChanged"
+	UnstructuredCreated Type = "UnstructuredCreated"
+	UnstructuredUpdated Type = "UnstructuredUpdated"
+	UnstructuredRemoved Type = "UnstructuredRemoved"
+)
+
+// NodeInfo contains a Node along with its topology information parsed from the well-known topology labels. The Zone and
+// Region are empty if the Node lacks the corresponding label.
+type NodeInfo struct {
+	Node   *k8sV1.Node
+	Zone   string
+	Region string
+
+	// IsLocal indicates whether the Node is the local Node on which the controller runs, which is also a gateway
+	// candidate, as opposed to another Node of the cluster.
+	IsLocal bool
+}
+
+// NewNodeInfo returns the NodeInfo for the given Node.
+func NewNodeInfo(node *k8sV1.Node) *NodeInfo {
+	return &NodeInfo{
+		Node:   node,
+		Zone:   node.Labels[k8sV1.LabelTopologyZone],
+		Region: node.Labels[k8sV1.LabelTopologyRegion],
+	}
+}
+
+// EndpointInfo contains the information tracked for the gateway Endpoint of a remote cluster.
+type EndpointInfo struct {
+	Endpoint *submV1.Endpoint
+
+	// Healthy indicates whether or not the Endpoint was last reported as reachable. Endpoints are considered healthy
+	// until reported otherwise.
+	Healthy bool
+
+	// Annotations are a copy of the Endpoint's annotations, eg routing preference hints.
+	Annotations map[string]string
+
+	// ResolvedAddress is the externally-reachable address of the Endpoint as computed by the controller's configured
+	// address resolver. It's empty if no resolver is configured or the resolution failed.
+	ResolvedAddress string
+}
+
+// EndpointEvent describes a creation, update or removal of an Endpoint.
+type EndpointEvent struct {
+	// Type is the type of the event, eg RemoteEndpointCreated.
+	Type Type
+
+	Endpoint *submV1.Endpoint
+}
+
 type HandlerState interface {
+	// GetClusterID returns the ID of the local cluster.
+	GetClusterID() string
+
+	// GetCorrelationID returns the ID of the source event whose notifications are being dispatched, eg an Endpoint
+	// creation, which is shared by all the handler notifications resulting from it. Returns empty if no event is being
+	// dispatched.
+	GetCorrelationID() string
+
 	IsOnGateway() bool
+
+	// GetRemoteEndpoints returns the remote Endpoints, sorted by cluster ID and then by name.
 	GetRemoteEndpoints() []submV1.Endpoint
+
+	// GetRemoteEndpointsSortedBy returns the remote Endpoints, sorted by the given less function.
+	GetRemoteEndpointsSortedBy(less func(a, b *submV1.Endpoint) bool) []submV1.Endpoint
+
+	// GetRemoteSubnets returns the union of the subnets advertised by the remote Endpoints, deduplicated and sorted.
+	GetRemoteSubnets() []string
+
+	// GetNodes returns copies of the Nodes in the controller's cache, sorted by name.
+	GetNodes() []k8sV1.Node
+
+	// GetNodeByHostname returns a copy of the cached Node with the given hostname, ie its name or one of its addresses of
+	// type Hostname, eg to find the Node referenced by an Endpoint's hostname. The lookup is indexed, so it's O(1).
+	GetNodeByHostname(hostname string) (*k8sV1.Node, bool)
+
+	// GetNodeInfos returns the NodeInfo of copies of the Nodes in the controller's cache, sorted by name. The local Node is
+	// flagged via IsLocal.
+	GetNodeInfos() []NodeInfo
+
+	// GetLocalNodeName returns the name of the local Node on which the controller runs.
+	GetLocalNodeName() string
+
+	// GetGatewayNodeNames returns the names of the cached Nodes labeled as gateways, ie with the "submariner.io/gateway"
+	// label set to "true", sorted. This includes the local Node if it's labeled.
+	GetGatewayNodeNames() []string
+
+	// GetGatewayEndpoint returns the active gateway Endpoint for the given remote cluster. If multiple Endpoints exist for
+	// the cluster, eg during a gateway failover, the most recently created one is returned.
+	GetGatewayEndpoint(clusterID string) (*submV1.Endpoint, bool)
+
+	// HasRemoteCluster returns whether the given remote cluster is currently represented by at least one remote Endpoint.
+	HasRemoteCluster(clusterID string) bool
+
+	// GetEndpointInfo returns the tracked information for the gateway Endpoint of the given remote cluster.
+	GetEndpointInfo(clusterID string) (*EndpointInfo, bool)
+
+	// GetEndpointAnnotation returns the value of the given annotation on the gateway Endpoint of the given remote cluster.
+	GetEndpointAnnotation(clusterID, key string) (string, bool)
+
+	// Context returns the controller's context which is cancelled when the controller is stopped, eg to abort long-running
+	// operations started by a handler.
+	Context() context.Context
 }
 
 type DefaultHandlerState struct{}
 
+func (c *DefaultHandlerState) GetClusterID() string {
+	return ""
+}
+
+func (c *DefaultHandlerState) GetCorrelationID() string {
+	return ""
+}
+
 func (c *DefaultHandlerState) IsOnGateway() bool {
 	return false
 }
@@ -40,6 +188,54 @@ func (c *DefaultHandlerState) GetRemoteEndpoints() []submV1.Endpoint {
 	return nil
 }
 
+func (c *DefaultHandlerState) GetRemoteEndpointsSortedBy(_ func(a, b *submV1.Endpoint) bool) []submV1.Endpoint {
+	return nil
+}
+
+func (c *DefaultHandlerState) GetRemoteSubnets() []string {
+	return nil
+}
+
+func (c *DefaultHandlerState) GetNodes() []k8sV1.Node {
+	return nil
+}
+
+func (c *DefaultHandlerState) GetNodeByHostname(_ string) (*k8sV1.Node, bool) {
+	return nil, false
+}
+
+func (c *DefaultHandlerState) GetNodeInfos() []NodeInfo {
+	return nil
+}
+
+func (c *DefaultHandlerState) GetLocalNodeName() string {
+	return ""
+}
+
+func (c *DefaultHandlerState) GetGatewayNodeNames() []string {
+	return nil
+}
+
+func (c *DefaultHandlerState) GetGatewayEndpoint(_ string) (*submV1.Endpoint, bool) {
+	return nil, false
+}
+
+func (c *DefaultHandlerState) HasRemoteCluster(_ string) bool {
+	return false
+}
+
+func (c *DefaultHandlerState) GetEndpointInfo(_ string) (*EndpointInfo, bool) {
+	return nil, false
+}
+
+func (c *DefaultHandlerState) GetEndpointAnnotation(_, _ string) (string, bool) {
+	return "", false
+}
+
+func (c *DefaultHandlerState) Context() context.Context {
+	return context.Background()
+}
+
 type Handler interface {
 	// Init is called once on startup to let the handler initialize any state it needs.
 	Init() error
@@ -93,6 +289,171 @@ type Handler interface {
 	NodeRemoved(node *k8sV1.Node) error
 }
 
+// SubnetConflictHandler can optionally be implemented by a Handler to be notified when the subnets advertised by
+// Endpoints of different remote clusters overlap.
+type SubnetConflictHandler interface {
+	// SubnetConflictDetected is called when a subnet of Endpoint a overlaps a subnet of Endpoint b. The overlap is the
+	// more specific of the two overlapping subnets.
+	SubnetConflictDetected(a, b *submV1.Endpoint, overlap string) error
+}
+
+// EndpointHealthHandler can optionally be implemented by a Handler to be notified when the reachability of a remote
+// cluster's gateway Endpoint changes.
+type EndpointHealthHandler interface {
+	// EndpointHealthChanged is called when the given remote Endpoint transitions between healthy and unhealthy.
+	EndpointHealthChanged(endpoint *submV1.Endpoint, healthy bool) error
+}
+
+// LocalEndpointIPChangeHandler can optionally be implemented by a Handler to be notified specifically when the public or
+// private IP of a local Endpoint changes, eg to re-establish the local tunnel.
+type LocalEndpointIPChangeHandler interface {
+	// LocalEndpointIPChanged is called after LocalEndpointUpdated with the previous and updated local Endpoint.
+	LocalEndpointIPChanged(oldEndpoint, newEndpoint *submV1.Endpoint) error
+}
+
+// BackendConfigChangeHandler can optionally be implemented by a Handler to be notified specifically when the
+// backend-specific configuration of an Endpoint changes, eg its PSK or ports.
+type BackendConfigChangeHandler interface {
+	// EndpointBackendConfigChanged is called after LocalEndpointUpdated or RemoteEndpointUpdated with the ID of the
+	// Endpoint's cluster and the Spec.BackendConfig entries that were added or changed, mapped to their new values.
+	// Removed entries are mapped to the empty string.
+	EndpointBackendConfigChanged(clusterID string, changed map[string]string) error
+}
+
+// PreferredGatewayHandler can optionally be implemented by a Handler to be notified when the remote Endpoint preferred as
+// the gateway for a remote cluster changes, eg on failover.
+type PreferredGatewayHandler interface {
+	// PreferredGatewayChanged is called with the previous and new preferred Endpoint of the given remote cluster, either
+	// of which is nil if the cluster had or has no preferred Endpoint.
+	PreferredGatewayChanged(clusterID string, oldEndpoint, newEndpoint *submV1.Endpoint) error
+}
+
+// ClusterGlobalEgressIPHandler can optionally be implemented by a Handler to be notified of ClusterGlobalEgressIP changes.
+// The controller only watches ClusterGlobalEgressIPs if configured to do so and the globalnet CRD is installed.
+type ClusterGlobalEgressIPHandler interface {
+	// ClusterGlobalEgressIPCreated is called when a ClusterGlobalEgressIP is created.
+	ClusterGlobalEgressIPCreated(egressIP *submV1.ClusterGlobalEgressIP) error
+
+	// ClusterGlobalEgressIPUpdated is called when a ClusterGlobalEgressIP is updated.
+	ClusterGlobalEgressIPUpdated(egressIP *submV1.ClusterGlobalEgressIP) error
+
+	// ClusterGlobalEgressIPRemoved is called when a ClusterGlobalEgressIP is removed.
+	ClusterGlobalEgressIPRemoved(egressIP *submV1.ClusterGlobalEgressIP) error
+}
+
+// SoleGatewayHandler can optionally be implemented by a Handler to be notified when the local node becomes, or ceases to
+// be, the only gateway as per the number of Nodes labeled as gateways.
+type SoleGatewayHandler interface {
+	// OnSoleGateway is called with true when the local Node becomes the only gateway Node and false when another gateway
+	// Node appears or the local Node is no longer a gateway Node.
+	OnSoleGateway(sole bool) error
+}
+
+// LeadershipHandler can optionally be implemented by a Handler to be notified when the controller's leadership status
+// changes, as told via the controller's SetLeader, eg so a Handler only acts while its instance is the leader.
+type LeadershipHandler interface {
+	// OnLeadershipChanged is called with true when the controller becomes the leader and false when it loses the
+	// leadership.
+	OnLeadershipChanged(isLeader bool) error
+}
+
+// RemoteEndpointBatchHandler can optionally be implemented by a Handler to be notified of the remote Endpoint events
+// grouped by a batch key, eg the cluster ID, in a single call rather than individually, if the controller is configured
+// to do so.
+type RemoteEndpointBatchHandler interface {
+	// OnRemoteEndpointBatch is called with the batch key and the RemoteEndpointCreated, RemoteEndpointUpdated and
+	// RemoteEndpointRemoved events with that key, in the order they occurred.
+	OnRemoteEndpointBatch(key string, batch []EndpointEvent) error
+}
+
+// UnstructuredResourceHandler can optionally be implemented by a Handler to be notified of the events of the resource
+// types watched via the controller's WatchGVR, as generic unstructured objects.
+type UnstructuredResourceHandler interface {
+	// OnUnstructuredEvent is called with the watched resource type, the event type, ie UnstructuredCreated,
+	// UnstructuredUpdated or UnstructuredRemoved, and the object.
+	OnUnstructuredEvent(gvr schema.GroupVersionResource, eventType Type, obj *unstructured.Unstructured) error
+}
+
+// WatchReconnectHandler can optionally be implemented by a Handler to be notified when the watch connection for a
+// watched resource type dropped and was re-established, during which time events may have been missed. Handlers may
+// treat this as a trigger to reconcile.
+type WatchReconnectHandler interface {
+	// OnWatchReconnected is called with the name of the resource type whose watch was re-established, eg "Endpoint", or
+	// "east/Endpoint" if the resource type is watched in multiple clusters.
+	OnWatchReconnected(resource string) error
+}
+
+// HeartbeatHandler can optionally be implemented by a Handler to be notified periodically, regardless of resource changes,
+// eg to reconcile its state, if the controller is configured with a heartbeat interval.
+type HeartbeatHandler interface {
+	// OnHeartbeat is called at the configured heartbeat interval.
+	OnHeartbeat() error
+}
+
+// InitialEndpointsHandler can optionally be implemented by a Handler to be notified of the Endpoints that exist when the
+// controller starts in a single call rather than individually, if the controller is configured to do so.
+type InitialEndpointsHandler interface {
+	// OnInitialEndpoints is called once, after the initial sync, with the pre-existing local and remote Endpoints. The
+	// remote Endpoints are excluded if the Handler is notified of them in batches via RemoteEndpointBatchHandler.
+	OnInitialEndpoints(endpoints []submV1.Endpoint) error
+}
+
+// PreStartHandler can optionally be implemented by a Handler to perform one-time setup, eg loading firewall rules, after
+// it's initialized but before it receives any event notifications.
+type PreStartHandler interface {
+	// PreStart is called once, after Init and SetState, before any event is dispatched to the Handler.
+	PreStart(state HandlerState) error
+}
+
+// ControllerLifecycleHandler can optionally be implemented by a Handler to be notified when the controller has started and
+// when it's stopping, as opposed to Init and Stop which pertain to the Handler itself.
+type ControllerLifecycleHandler interface {
+	// OnControllerStarted is called once the controller has started successfully. If the Handler is added after the
+	// controller started, it's called when the Handler is added.
+	OnControllerStarted() error
+
+	// OnControllerStopping is called when the controller is being stopped, before the Handlers are stopped.
+	OnControllerStopping() error
+}
+
+// ReadinessHandler can optionally be implemented by a Handler that completes its initialization asynchronously, ie
+// isn't ready to process events when Init returns.
+type ReadinessHandler interface {
+	// Ready returns a channel that's closed once the Handler is ready.
+	Ready() <-chan struct{}
+}
+
+// NodeInfoHandler can optionally be implemented by a Handler to receive the topology information of Nodes.
+type NodeInfoHandler interface {
+	// OnNodeInfo is called after each successful NodeCreated, NodeUpdated or NodeRemoved notification, identified by the
+	// given event type, with the NodeInfo of the Node.
+	OnNodeInfo(eventType Type, info *NodeInfo) error
+}
+
+// NodeUpdateSubscriber can optionally be implemented by a Handler to only be notified of NodeUpdated, and the accompanying
+// OnNodeInfo, when specific fields of a Node change. Handlers not implementing it are notified of every Node update.
+type NodeUpdateSubscriber interface {
+	// NodeUpdateFields returns the fields of a Node whose changes the Handler is notified of.
+	NodeUpdateFields() []NodeField
+}
+
+// EndpointUpdateSubscriber can optionally be implemented by a Handler to only be notified of LocalEndpointUpdated and
+// RemoteEndpointUpdated when specific fields of an Endpoint change. Handlers not implementing it are notified of every
+// Endpoint update.
+type EndpointUpdateSubscriber interface {
+	// EndpointUpdateFields returns the fields of an Endpoint whose changes the Handler is notified of.
+	EndpointUpdateFields() []EndpointField
+}
+
+// CableNameSubscriber can optionally be implemented by a Handler to only be notified of the Endpoint events, ie the
+// LocalEndpoint*, RemoteEndpoint*, LocalEndpointIPChanged, EndpointHealthChanged and InitialEndpoints notifications, of
+// Endpoints whose cable name matches a pattern. Handlers not implementing it are notified of all Endpoints.
+type CableNameSubscriber interface {
+	// CableNamePattern returns the pattern the Spec.CableName of an Endpoint must match for the Handler to be notified
+	// of it, or nil to be notified of all Endpoints.
+	CableNamePattern() *regexp.Regexp
+}
+
 // Base structure for event handlers that stubs out methods considered to be optional.
 type HandlerBase struct {
 	handlerState HandlerState
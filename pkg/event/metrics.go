@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	registryLabel  = "registry"
+	eventTypeLabel = "event_type"
+)
+
+var lastSuccessGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "submariner_event_last_success_timestamp_seconds",
+		Help: "Timestamp of the last successful processing of each event type (by registry and event type)",
+	},
+	[]string{
+		registryLabel,
+		eventTypeLabel,
+	},
+)
+
+func init() {
+	prometheus.MustRegister(lastSuccessGauge)
+}
+
+func recordLastSuccess(registry, eventName string, t time.Time) {
+	lastSuccessGauge.With(prometheus.Labels{registryLabel: registry, eventTypeLabel: eventName}).Set(float64(t.Unix()))
+}
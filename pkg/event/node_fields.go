@@ -0,0 +1,93 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"reflect"
+
+	k8sV1 "k8s.io/api/core/v1"
+	"k8s.io/utils/set"
+)
+
+// NodeField identifies a field of a Node whose changes a NodeUpdateSubscriber can subscribe to.
+type NodeField string
+
+const (
+	NodeLabels      NodeField = "Labels"
+	NodeAnnotations NodeField = "Annotations"
+	NodeAddresses   NodeField = "Addresses"
+	// NodeConditions only considers the type, status and reason of each condition, not its heartbeat time or message.
+	NodeConditions NodeField = "Conditions"
+)
+
+type nodeCondition struct {
+	conditionType k8sV1.NodeConditionType
+	status        k8sV1.ConditionStatus
+	reason        string
+}
+
+// changedNodeFields returns the fields that differ between the given previous and updated Node.
+func changedNodeFields(oldNode, newNode *k8sV1.Node) set.Set[NodeField] {
+	changed := set.New[NodeField]()
+
+	if !reflect.DeepEqual(oldNode.Labels, newNode.Labels) {
+		changed.Insert(NodeLabels)
+	}
+
+	if !reflect.DeepEqual(oldNode.Annotations, newNode.Annotations) {
+		changed.Insert(NodeAnnotations)
+	}
+
+	if !reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses) {
+		changed.Insert(NodeAddresses)
+	}
+
+	if !reflect.DeepEqual(nodeConditions(oldNode), nodeConditions(newNode)) {
+		changed.Insert(NodeConditions)
+	}
+
+	return changed
+}
+
+func nodeConditions(node *k8sV1.Node) []nodeCondition {
+	conditions := make([]nodeCondition, len(node.Status.Conditions))
+	for i := range node.Status.Conditions {
+		c := &node.Status.Conditions[i]
+		conditions[i] = nodeCondition{conditionType: c.Type, status: c.Status, reason: c.Reason}
+	}
+
+	return conditions
+}
+
+// isSubscribedToNodeUpdate returns whether the given Handler should be notified of a Node update with the given changed
+// fields. A nil set indicates the changes are unknown.
+func isSubscribedToNodeUpdate(h Handler, changed set.Set[NodeField]) bool {
+	sh, ok := h.(NodeUpdateSubscriber)
+	if !ok || changed == nil {
+		return true
+	}
+
+	for _, field := range sh.NodeUpdateFields() {
+		if changed.Has(field) {
+			return true
+		}
+	}
+
+	return false
+}
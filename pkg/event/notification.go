@@ -0,0 +1,50 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Notification describes an event dispatched by a Registry to its Handlers.
+type Notification struct {
+	// Type is the type of the event.
+	Type Type
+
+	// Objects are the objects the event pertains to, if any, in the order they're passed to the Handlers.
+	Objects []runtime.Object
+
+	// Time is when the event was dispatched.
+	Time time.Time
+}
+
+// SetObserver sets the function invoked with each event dispatched to the Handlers, eg to capture the events for
+// debugging. The objects are passed as is so the observer must not modify them. It's not safe to call this concurrently
+// with event dispatch.
+func (er *Registry) SetObserver(observer func(n Notification)) {
+	er.observer = observer
+}
+
+func (er *Registry) observe(eventType Type, objs ...runtime.Object) {
+	if er.observer != nil {
+		er.observer(Notification{Type: eventType, Objects: objs, Time: time.Now()})
+	}
+}
@@ -19,13 +19,19 @@ limitations under the License.
 package event
 
 import (
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/log"
 	submV1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	k8sV1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/utils/set"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -35,11 +41,38 @@ type Registry struct {
 	name                    string
 	networkPlugin           string
 	eventHandlers           []Handler
+	disabledHandlers        set.Set[string]
 	remoteEndpointTimeStamp map[string]v1.Time
+	timeStampMutex          sync.Mutex
+	deepCopyObjects         bool
+	reverseTeardown         bool
+	batchRemoteEndpoints    bool
+	tracer                  *log.Logger
+	handlerState            HandlerState
+	// lastSuccess holds the time each event, keyed by name, was last processed successfully by all Handlers.
+	lastSuccess sync.Map
+	// lastNodes holds the Nodes, keyed by name, last notified successfully to the Handlers in order to determine the fields
+	// that changed on update. Guarded by nodeMutex.
+	lastNodes map[string]*k8sV1.Node
+	nodeMutex sync.Mutex
+	// lastEndpoints holds the Endpoints, keyed by name, last notified successfully to the Handlers in order to determine
+	// the fields that changed on update. Guarded by endpointMutex.
+	lastEndpoints map[string]*submV1.Endpoint
+	endpointMutex sync.Mutex
+	// observer is invoked with each event dispatched to the Handlers.
+	observer func(n Notification)
+	// inFlight holds the number of events, keyed by Handler name, currently being processed by each Handler. Guarded by
+	// inFlightMutex.
+	inFlight      map[string]int
+	inFlightMutex sync.Mutex
 }
 
 var logger = log.Logger{Logger: logf.Log.WithName("EventRegistry")}
 
+// teardownEvents are the events dispatched to the Handlers in reverse order if reverse teardown order is enabled.
+var teardownEvents = set.New(string(TransitionToNonGateway), string(LocalEndpointRemoved), string(RemoteEndpointRemoved),
+	string(NodeRemoved), string(ClusterGlobalEgressIPRemoved))
+
 // NewRegistry creates a new registry with the given name, typically referencing the owner, to manage event
 // Handlers that match the given networkPlugin name. The given event Handlers whose associated network plugin matches the given
 // networkPlugin name are added. Non-matching Handlers are ignored. Handlers will be called in registration order.
@@ -48,11 +81,15 @@ func NewRegistry(name, networkPlugin string, eventHandlers ...Handler) (*Registr
 		name:                    name,
 		networkPlugin:           strings.ToLower(networkPlugin),
 		eventHandlers:           []Handler{},
+		disabledHandlers:        set.New[string](),
 		remoteEndpointTimeStamp: map[string]v1.Time{},
+		lastNodes:               map[string]*k8sV1.Node{},
+		lastEndpoints:           map[string]*submV1.Endpoint{},
+		inFlight:                map[string]int{},
 	}
 
 	for _, eventHandler := range eventHandlers {
-		err := r.addHandler(eventHandler)
+		_, err := r.addHandler(eventHandler)
 		if err != nil {
 			return nil, err
 		}
@@ -66,7 +103,14 @@ func (er *Registry) GetName() string {
 	return er.name
 }
 
-func (er *Registry) addHandler(eventHandler Handler) error {
+// AddHandler adds the given Handler to the registry if its associated network plugin matches the registry's. The Handler
+// is initialized before it's added. Returns true if the Handler was added or false if it was ignored. An error is returned
+// if a Handler with the same name was already added, as names must be unique to unambiguously identify Handlers.
+func (er *Registry) AddHandler(eventHandler Handler) (bool, error) {
+	return er.addHandler(eventHandler)
+}
+
+func (er *Registry) addHandler(eventHandler Handler) (bool, error) {
 	evNetworkPlugins := set.New[string]()
 
 	for _, np := range eventHandler.GetNetworkPlugins() {
@@ -74,71 +118,258 @@ func (er *Registry) addHandler(eventHandler Handler) error {
 	}
 
 	if evNetworkPlugins.Has(AnyNetworkPlugin) || evNetworkPlugins.Has(er.networkPlugin) {
+		if er.hasHandler(eventHandler.GetName()) {
+			return false, errors.Errorf("Event handler %q is already registered in registry %q", eventHandler.GetName(), er.name)
+		}
+
 		if err := eventHandler.Init(); err != nil {
-			return errors.Wrapf(err, "Event handler %q failed to initialize", eventHandler.GetName())
+			return false, errors.Wrapf(err, "Event handler %q failed to initialize", eventHandler.GetName())
 		}
 
 		er.eventHandlers = append(er.eventHandlers, eventHandler)
 		logger.Infof("Event handler %q added to registry %q.", eventHandler.GetName(), er.name)
-	} else {
-		logger.V(log.DEBUG).Infof("Event handler %q ignored for registry %q as networkPlugin is %q.",
-			eventHandler.GetName(), er.name, er.networkPlugin)
+
+		return true, nil
 	}
 
-	return nil
+	logger.V(log.DEBUG).Infof("Event handler %q ignored for registry %q as networkPlugin is %q.",
+		eventHandler.GetName(), er.name, er.networkPlugin)
+
+	return false, nil
+}
+
+func (er *Registry) hasHandler(name string) bool {
+	for _, h := range er.eventHandlers {
+		if h.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDeepCopyObjects sets whether or not each object is deep-copied before being passed to each Handler. This prevents a
+// Handler that mutates an object from affecting the object seen by subsequent Handlers, at the cost of an allocation per
+// Handler per event.
+func (er *Registry) SetDeepCopyObjects(deepCopy bool) {
+	er.deepCopyObjects = deepCopy
+}
+
+// SetReverseTeardownOrder sets whether or not the teardown events, ie TransitionToNonGateway and the removal events, are
+// dispatched to the Handlers in reverse registration order so Handlers are torn down in the reverse order they were set up.
+// All other events are dispatched in registration order.
+func (er *Registry) SetReverseTeardownOrder(reverse bool) {
+	er.reverseTeardown = reverse
+}
+
+// SetBatchRemoteEndpoints sets whether or not Handlers implementing RemoteEndpointBatchHandler are notified of the remote
+// Endpoint events in batches via RemoteEndpointBatch, in which case they're no longer notified of them individually.
+// Other Handlers are unaffected.
+func (er *Registry) SetBatchRemoteEndpoints(batch bool) {
+	er.batchRemoteEndpoints = batch
+}
+
+// isNotifiedInBatches returns whether the given Handler is notified of the remote Endpoint events in batches.
+func (er *Registry) isNotifiedInBatches(h Handler) bool {
+	_, ok := h.(RemoteEndpointBatchHandler)
+	return ok && er.batchRemoteEndpoints
+}
+
+// SetTracer sets the logger to which the dispatch of each event to each Handler and its result are traced at debug level.
+// Tracing is disabled if nil, which is the default.
+func (er *Registry) SetTracer(tracer *log.Logger) {
+	er.tracer = tracer
+}
+
+type deepCopier[T any] interface {
+	DeepCopy() T
+}
+
+// objectFor returns the object to pass to a Handler, copying it if configured.
+func objectFor[T deepCopier[T]](er *Registry, obj T) T {
+	if er.deepCopyObjects {
+		return obj.DeepCopy()
+	}
+
+	return obj
+}
+
+// SetHandlerEnabled enables or disables event notifications for the Handler with the given name. A disabled Handler is
+// still notified of SetState, Stop and Uninstall but receives no other event notifications. Returns false if no such
+// Handler is registered.
+func (er *Registry) SetHandlerEnabled(name string, enabled bool) bool {
+	for _, h := range er.eventHandlers {
+		if h.GetName() != name {
+			continue
+		}
+
+		if enabled {
+			er.disabledHandlers.Delete(name)
+		} else {
+			er.disabledHandlers.Insert(name)
+		}
+
+		logger.Infof("Event handler %q in registry %q enabled: %v", name, er.name, enabled)
+
+		return true
+	}
+
+	return false
+}
+
+// RemoveHandler removes the Handler with the given name from the registry so it receives no further event notifications.
+// The Handler isn't stopped. Returns false if no such Handler is registered.
+func (er *Registry) RemoveHandler(name string) bool {
+	for i, h := range er.eventHandlers {
+		if h.GetName() != name {
+			continue
+		}
+
+		er.eventHandlers = append(er.eventHandlers[:i:i], er.eventHandlers[i+1:]...)
+		er.disabledHandlers.Delete(name)
+
+		logger.Infof("Event handler %q removed from registry %q", name, er.name)
+
+		return true
+	}
+
+	return false
 }
 
 func (er *Registry) SetHandlerState(handlerState HandlerState) {
-	_ = er.invokeHandlers("SetHandlerState", func(h Handler) error {
+	er.handlerState = handlerState
+
+	_ = er.invokeAllHandlers("SetHandlerState", func(h Handler) error {
 		h.SetState(handlerState)
 		return nil
 	})
 }
 
+// PreStart invokes PreStart on the Handlers that implement PreStartHandler, including disabled Handlers.
+func (er *Registry) PreStart(handlerState HandlerState) error {
+	return er.invokeAllHandlers("PreStart", func(h Handler) error {
+		if ph, ok := h.(PreStartHandler); ok {
+			return ph.PreStart(handlerState) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) ControllerStarted() error {
+	return er.invokeAllHandlers("ControllerStarted", func(h Handler) error {
+		if lh, ok := h.(ControllerLifecycleHandler); ok {
+			return lh.OnControllerStarted() //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) ControllerStopping() error {
+	return er.invokeAllHandlers("ControllerStopping", func(h Handler) error {
+		if lh, ok := h.(ControllerLifecycleHandler); ok {
+			return lh.OnControllerStopping() //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+// ReadyChannels returns the Ready channels, keyed by Handler name, of the Handlers implementing ReadinessHandler.
+func (er *Registry) ReadyChannels() map[string]<-chan struct{} {
+	channels := map[string]<-chan struct{}{}
+
+	for _, h := range er.eventHandlers {
+		if rh, ok := h.(ReadinessHandler); ok {
+			channels[h.GetName()] = rh.Ready()
+		}
+	}
+
+	return channels
+}
+
 func (er *Registry) StopHandlers() error {
-	return er.invokeHandlers("Stop", func(h Handler) error {
+	return er.invokeAllHandlers("Stop", func(h Handler) error {
 		return h.Stop() //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) Uninstall() error {
-	return er.invokeHandlers("Uninstall", func(h Handler) error {
+	return er.invokeAllHandlers("Uninstall", func(h Handler) error {
 		return h.Uninstall() //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) TransitionToNonGateway() error {
+	er.observe(TransitionToNonGateway)
+
 	return er.invokeHandlers("TransitionToNonGateway", func(h Handler) error {
 		return h.TransitionToNonGateway() //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) TransitionToGateway() error {
+	er.observe(TransitionToGateway)
+
 	return er.invokeHandlers("TransitionToGateway", func(h Handler) error {
 		return h.TransitionToGateway() //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) LocalEndpointCreated(endpoint *submV1.Endpoint) error {
-	return er.invokeHandlers("LocalEndpointCreated", func(h Handler) error {
-		return h.LocalEndpointCreated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	er.observe(LocalEndpointCreated, endpoint)
+
+	err := er.invokeHandlers("LocalEndpointCreated", func(h Handler) error {
+		if !isSubscribedToCable(h, endpoint) {
+			return nil
+		}
+
+		return h.LocalEndpointCreated(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
 	})
+	if err == nil {
+		er.setLastEndpoint(endpoint.Name, endpoint.DeepCopy())
+	}
+
+	return err
 }
 
+// LocalEndpointUpdated notifies the Handlers of the updated local Endpoint. Handlers implementing EndpointUpdateSubscriber
+// are only notified if any of their subscribed fields changed since the Endpoint was last notified.
 func (er *Registry) LocalEndpointUpdated(endpoint *submV1.Endpoint) error {
-	return er.invokeHandlers("LocalEndpointUpdated", func(h Handler) error {
-		return h.LocalEndpointUpdated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	changed := er.changedEndpointFieldsOf(endpoint)
+
+	er.observe(LocalEndpointUpdated, endpoint)
+
+	err := er.invokeHandlers("LocalEndpointUpdated", func(h Handler) error {
+		if !isSubscribedToCable(h, endpoint) || !isSubscribedToEndpointUpdate(h, changed) {
+			return nil
+		}
+
+		return h.LocalEndpointUpdated(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
 	})
+	if err == nil {
+		er.setLastEndpoint(endpoint.Name, endpoint.DeepCopy())
+	}
+
+	return err
 }
 
 func (er *Registry) LocalEndpointRemoved(endpoint *submV1.Endpoint) error {
+	er.setLastEndpoint(endpoint.Name, nil)
+	er.observe(LocalEndpointRemoved, endpoint)
+
 	return er.invokeHandlers("LocalEndpointRemoved", func(h Handler) error {
-		return h.LocalEndpointRemoved(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+		if !isSubscribedToCable(h, endpoint) {
+			return nil
+		}
+
+		return h.LocalEndpointRemoved(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) RemoteEndpointCreated(endpoint *submV1.Endpoint) error {
-	lastProcessedTime, ok := er.remoteEndpointTimeStamp[endpoint.Spec.ClusterID]
+	lastProcessedTime, ok := er.lastProcessedTime(endpoint.Spec.ClusterID)
 
 	if ok && lastProcessedTime.After(endpoint.CreationTimestamp.Time) {
 		logger.Infof("Ignoring new remote %#v since a later endpoint was already"+
@@ -146,25 +377,88 @@ func (er *Registry) RemoteEndpointCreated(endpoint *submV1.Endpoint) error {
 		return nil
 	}
 
+	er.observe(RemoteEndpointCreated, endpoint)
+
 	err := er.invokeHandlers("RemoteEndpointCreated", func(h Handler) error {
-		return h.RemoteEndpointCreated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+		if !isSubscribedToCable(h, endpoint) || er.isNotifiedInBatches(h) {
+			return nil
+		}
+
+		return h.RemoteEndpointCreated(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
 	})
 
 	if err == nil {
+		er.timeStampMutex.Lock()
 		er.remoteEndpointTimeStamp[endpoint.Spec.ClusterID] = endpoint.CreationTimestamp
+		er.timeStampMutex.Unlock()
+
+		er.setLastEndpoint(endpoint.Name, endpoint.DeepCopy())
 	}
 
 	return err
 }
 
+func (er *Registry) lastProcessedTime(clusterID string) (v1.Time, bool) {
+	er.timeStampMutex.Lock()
+	defer er.timeStampMutex.Unlock()
+
+	t, ok := er.remoteEndpointTimeStamp[clusterID]
+
+	return t, ok
+}
+
+// RemoteEndpointUpdated notifies the Handlers of the updated remote Endpoint. Handlers implementing
+// EndpointUpdateSubscriber are only notified if any of their subscribed fields changed since the Endpoint was last
+// notified.
 func (er *Registry) RemoteEndpointUpdated(endpoint *submV1.Endpoint) error {
-	return er.invokeHandlers("RemoteEndpointUpdated", func(h Handler) error {
-		return h.RemoteEndpointUpdated(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+	changed := er.changedEndpointFieldsOf(endpoint)
+
+	er.observe(RemoteEndpointUpdated, endpoint)
+
+	err := er.invokeHandlers("RemoteEndpointUpdated", func(h Handler) error {
+		if !isSubscribedToCable(h, endpoint) || !isSubscribedToEndpointUpdate(h, changed) || er.isNotifiedInBatches(h) {
+			return nil
+		}
+
+		return h.RemoteEndpointUpdated(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
 	})
+	if err == nil {
+		er.setLastEndpoint(endpoint.Name, endpoint.DeepCopy())
+	}
+
+	return err
+}
+
+// changedEndpointFieldsOf returns the fields of the given Endpoint that changed since it was last notified, or nil if it
+// wasn't previously notified.
+func (er *Registry) changedEndpointFieldsOf(endpoint *submV1.Endpoint) set.Set[EndpointField] {
+	if lastEndpoint := er.getLastEndpoint(endpoint.Name); lastEndpoint != nil {
+		return changedEndpointFields(lastEndpoint, endpoint)
+	}
+
+	return nil
+}
+
+func (er *Registry) getLastEndpoint(name string) *submV1.Endpoint {
+	er.endpointMutex.Lock()
+	defer er.endpointMutex.Unlock()
+
+	return er.lastEndpoints[name]
+}
+
+func (er *Registry) setLastEndpoint(name string, endpoint *submV1.Endpoint) {
+	er.endpointMutex.Lock()
+	defer er.endpointMutex.Unlock()
+
+	if endpoint == nil {
+		delete(er.lastEndpoints, name)
+	} else {
+		er.lastEndpoints[name] = endpoint
+	}
 }
 
 func (er *Registry) RemoteEndpointRemoved(endpoint *submV1.Endpoint) error {
-	lastProcessedTime, ok := er.remoteEndpointTimeStamp[endpoint.Spec.ClusterID]
+	lastProcessedTime, ok := er.lastProcessedTime(endpoint.Spec.ClusterID)
 
 	if ok && lastProcessedTime.After(endpoint.CreationTimestamp.Time) {
 		logger.Infof("Ignoring deleted remote %#v since a later endpoint was already"+
@@ -172,40 +466,474 @@ func (er *Registry) RemoteEndpointRemoved(endpoint *submV1.Endpoint) error {
 		return nil
 	}
 
+	er.timeStampMutex.Lock()
 	delete(er.remoteEndpointTimeStamp, endpoint.Spec.ClusterID)
+	er.timeStampMutex.Unlock()
+
+	er.setLastEndpoint(endpoint.Name, nil)
+	er.observe(RemoteEndpointRemoved, endpoint)
 
 	return er.invokeHandlers("RemoteEndpointRemoved", func(h Handler) error {
-		return h.RemoteEndpointRemoved(endpoint) //nolint:wrapcheck  // Let the caller wrap it
+		if !isSubscribedToCable(h, endpoint) || er.isNotifiedInBatches(h) {
+			return nil
+		}
+
+		return h.RemoteEndpointRemoved(objectFor(er, endpoint)) //nolint:wrapcheck  // Let the caller wrap it
+	})
+}
+
+// RemoteEndpointBatch notifies the Handlers implementing RemoteEndpointBatchHandler of the given batch of remote Endpoint
+// events with the given key, if batching is enabled via SetBatchRemoteEndpoints. Each Handler is only notified of the
+// events of the Endpoints it's subscribed to as per CableNameSubscriber.
+func (er *Registry) RemoteEndpointBatch(key string, batch []EndpointEvent) error {
+	objs := make([]runtime.Object, 0, len(batch))
+	for i := range batch {
+		objs = append(objs, batch[i].Endpoint)
+	}
+
+	er.observe(RemoteEndpointBatch, objs...)
+
+	return er.invokeHandlers("RemoteEndpointBatch", func(h Handler) error {
+		bh, ok := h.(RemoteEndpointBatchHandler)
+		if !ok || !er.batchRemoteEndpoints {
+			return nil
+		}
+
+		subscribed := make([]EndpointEvent, 0, len(batch))
+
+		for i := range batch {
+			if isSubscribedToCable(h, batch[i].Endpoint) {
+				subscribed = append(subscribed, EndpointEvent{Type: batch[i].Type, Endpoint: objectFor(er, batch[i].Endpoint)})
+			}
+		}
+
+		if len(subscribed) == 0 {
+			return nil
+		}
+
+		return bh.OnRemoteEndpointBatch(key, subscribed) //nolint:wrapcheck  // Let the caller wrap it
 	})
 }
 
 func (er *Registry) NodeCreated(node *k8sV1.Node) error {
-	return er.invokeHandlers("NodeCreated", func(h Handler) error {
-		return h.NodeCreated(node) //nolint:wrapcheck  // Let the caller wrap it
+	er.observe(NodeCreated, node)
+
+	err := er.invokeHandlers("NodeCreated", func(h Handler) error {
+		if err := h.NodeCreated(objectFor(er, node)); err != nil {
+			return err //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return er.notifyNodeInfo(h, NodeCreated, node)
 	})
+	if err == nil {
+		er.setLastNode(node.Name, node.DeepCopy())
+	}
+
+	return err
 }
 
+// NodeUpdated notifies the Handlers of the updated Node. Handlers implementing NodeUpdateSubscriber are only notified if
+// any of their subscribed fields changed since the Node was last notified.
 func (er *Registry) NodeUpdated(node *k8sV1.Node) error {
-	return er.invokeHandlers("NodeUpdated", func(h Handler) error {
-		return h.NodeUpdated(node) //nolint:wrapcheck  // Let the caller wrap it
+	var changed set.Set[NodeField]
+
+	if lastNode := er.getLastNode(node.Name); lastNode != nil {
+		changed = changedNodeFields(lastNode, node)
+	}
+
+	er.observe(NodeUpdated, node)
+
+	err := er.invokeHandlers("NodeUpdated", func(h Handler) error {
+		if !isSubscribedToNodeUpdate(h, changed) {
+			return nil
+		}
+
+		if err := h.NodeUpdated(objectFor(er, node)); err != nil {
+			return err //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return er.notifyNodeInfo(h, NodeUpdated, node)
 	})
+	if err == nil {
+		er.setLastNode(node.Name, node.DeepCopy())
+	}
+
+	return err
+}
+
+func (er *Registry) getLastNode(name string) *k8sV1.Node {
+	er.nodeMutex.Lock()
+	defer er.nodeMutex.Unlock()
+
+	return er.lastNodes[name]
+}
+
+func (er *Registry) setLastNode(name string, node *k8sV1.Node) {
+	er.nodeMutex.Lock()
+	defer er.nodeMutex.Unlock()
+
+	if node == nil {
+		delete(er.lastNodes, name)
+	} else {
+		er.lastNodes[name] = node
+	}
 }
 
 func (er *Registry) NodeRemoved(node *k8sV1.Node) error {
+	er.setLastNode(node.Name, nil)
+	er.observe(NodeRemoved, node)
+
 	return er.invokeHandlers("NodeRemoved", func(h Handler) error {
-		return h.NodeRemoved(node) //nolint:wrapcheck  // Let the caller wrap it
+		if err := h.NodeRemoved(objectFor(er, node)); err != nil {
+			return err //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return er.notifyNodeInfo(h, NodeRemoved, node)
 	})
 }
 
+func (er *Registry) notifyNodeInfo(h Handler, eventType Type, node *k8sV1.Node) error {
+	if nh, ok := h.(NodeInfoHandler); ok {
+		info := NewNodeInfo(objectFor(er, node))
+		info.IsLocal = er.handlerState != nil && node.Name == er.handlerState.GetLocalNodeName()
+
+		return nh.OnNodeInfo(eventType, info) //nolint:wrapcheck  // Let the caller wrap it
+	}
+
+	return nil
+}
+
+func (er *Registry) SubnetConflictDetected(a, b *submV1.Endpoint, overlap string) error {
+	er.observe(SubnetConflictDetected, a, b)
+
+	return er.invokeHandlers("SubnetConflictDetected", func(h Handler) error {
+		if sh, ok := h.(SubnetConflictHandler); ok {
+			return sh.SubnetConflictDetected(objectFor(er, a), objectFor(er, b), overlap) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) EndpointHealthChanged(endpoint *submV1.Endpoint, healthy bool) error {
+	er.observe(EndpointHealthChanged, endpoint)
+
+	return er.invokeHandlers("EndpointHealthChanged", func(h Handler) error {
+		if eh, ok := h.(EndpointHealthHandler); ok && isSubscribedToCable(h, endpoint) {
+			return eh.EndpointHealthChanged(objectFor(er, endpoint), healthy) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) LocalEndpointIPChanged(oldEndpoint, newEndpoint *submV1.Endpoint) error {
+	er.observe(LocalEndpointIPChanged, oldEndpoint, newEndpoint)
+
+	return er.invokeHandlers("LocalEndpointIPChanged", func(h Handler) error {
+		if ih, ok := h.(LocalEndpointIPChangeHandler); ok && isSubscribedToCable(h, oldEndpoint, newEndpoint) {
+			return ih.LocalEndpointIPChanged(objectFor(er, oldEndpoint), objectFor(er, newEndpoint)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) EndpointBackendConfigChanged(clusterID string, changed map[string]string) error {
+	er.observe(EndpointBackendConfigChanged)
+
+	return er.invokeHandlers("EndpointBackendConfigChanged", func(h Handler) error {
+		if bh, ok := h.(BackendConfigChangeHandler); ok {
+			return bh.EndpointBackendConfigChanged(clusterID, changed) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) PreferredGatewayChanged(clusterID string, oldEp, newEp *submV1.Endpoint) error {
+	er.observe(PreferredGatewayChanged, oldEp, newEp)
+
+	return er.invokeHandlers("PreferredGatewayChanged", func(h Handler) error {
+		if ph, ok := h.(PreferredGatewayHandler); ok {
+			return ph.PreferredGatewayChanged(clusterID, objectFor(er, oldEp), objectFor(er, newEp)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) ClusterGlobalEgressIPCreated(egressIP *submV1.ClusterGlobalEgressIP) error {
+	er.observe(ClusterGlobalEgressIPCreated, egressIP)
+
+	return er.invokeHandlers("ClusterGlobalEgressIPCreated", func(h Handler) error {
+		if gh, ok := h.(ClusterGlobalEgressIPHandler); ok {
+			return gh.ClusterGlobalEgressIPCreated(objectFor(er, egressIP)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) ClusterGlobalEgressIPUpdated(egressIP *submV1.ClusterGlobalEgressIP) error {
+	er.observe(ClusterGlobalEgressIPUpdated, egressIP)
+
+	return er.invokeHandlers("ClusterGlobalEgressIPUpdated", func(h Handler) error {
+		if gh, ok := h.(ClusterGlobalEgressIPHandler); ok {
+			return gh.ClusterGlobalEgressIPUpdated(objectFor(er, egressIP)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) ClusterGlobalEgressIPRemoved(egressIP *submV1.ClusterGlobalEgressIP) error {
+	er.observe(ClusterGlobalEgressIPRemoved, egressIP)
+
+	return er.invokeHandlers("ClusterGlobalEgressIPRemoved", func(h Handler) error {
+		if gh, ok := h.(ClusterGlobalEgressIPHandler); ok {
+			return gh.ClusterGlobalEgressIPRemoved(objectFor(er, egressIP)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+// UnstructuredEvent notifies the Handlers implementing UnstructuredResourceHandler of the given event of a resource type
+// watched via GroupVersionResource.
+func (er *Registry) UnstructuredEvent(gvr schema.GroupVersionResource, eventType Type, obj *unstructured.Unstructured) error {
+	er.observe(eventType, obj)
+
+	return er.invokeHandlers(string(eventType), func(h Handler) error {
+		if uh, ok := h.(UnstructuredResourceHandler); ok {
+			return uh.OnUnstructuredEvent(gvr, eventType, objectFor(er, obj)) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) SoleGatewayChanged(sole bool) error {
+	er.observe(SoleGatewayChanged)
+
+	return er.invokeHandlers("SoleGatewayChanged", func(h Handler) error {
+		if sh, ok := h.(SoleGatewayHandler); ok {
+			return sh.OnSoleGateway(sole) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) LeadershipChanged(isLeader bool) error {
+	er.observe(LeadershipChanged)
+
+	return er.invokeHandlers("LeadershipChanged", func(h Handler) error {
+		if lh, ok := h.(LeadershipHandler); ok {
+			return lh.OnLeadershipChanged(isLeader) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) WatchReconnected(resource string) error {
+	er.observe(WatchReconnected)
+
+	return er.invokeHandlers("WatchReconnected", func(h Handler) error {
+		if wh, ok := h.(WatchReconnectHandler); ok {
+			return wh.OnWatchReconnected(resource) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+func (er *Registry) Heartbeat() error {
+	er.observe(Heartbeat)
+
+	return er.invokeHandlers("Heartbeat", func(h Handler) error {
+		if hh, ok := h.(HeartbeatHandler); ok {
+			return hh.OnHeartbeat() //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		return nil
+	})
+}
+
+// InitialEndpoints notifies the Handlers of the Endpoints that existed when the controller started. Handlers implementing
+// InitialEndpointsHandler are notified in a single call while the others are notified of each Endpoint's creation.
+// Handlers notified of the remote Endpoint events in batches, as per SetBatchRemoteEndpoints, aren't notified of the remote
+// Endpoints here as they're expected to be notified of their creation via RemoteEndpointBatch.
+func (er *Registry) InitialEndpoints(local, remote []*submV1.Endpoint) error {
+	objs := make([]runtime.Object, 0, len(local)+len(remote))
+	for _, endpoint := range append(append([]*submV1.Endpoint{}, local...), remote...) {
+		objs = append(objs, endpoint)
+	}
+
+	er.observe(InitialEndpoints, objs...)
+
+	err := er.invoke("InitialEndpoints", false, func(h Handler) error {
+		subscribedLocal, subscribedRemote := subscribedEndpoints(h, local), subscribedEndpoints(h, remote)
+		if er.isNotifiedInBatches(h) {
+			subscribedRemote = nil
+		}
+
+		if ih, ok := h.(InitialEndpointsHandler); ok {
+			endpoints := make([]submV1.Endpoint, 0, len(subscribedLocal)+len(subscribedRemote))
+
+			for _, endpoint := range append(append([]*submV1.Endpoint{}, subscribedLocal...), subscribedRemote...) {
+				endpoints = append(endpoints, *objectFor(er, endpoint))
+			}
+
+			return ih.OnInitialEndpoints(endpoints) //nolint:wrapcheck  // Let the caller wrap it
+		}
+
+		var errs []error
+
+		for _, endpoint := range subscribedLocal {
+			errs = append(errs, h.LocalEndpointCreated(objectFor(er, endpoint)))
+		}
+
+		for _, endpoint := range subscribedRemote {
+			errs = append(errs, h.RemoteEndpointCreated(objectFor(er, endpoint)))
+		}
+
+		return k8serrors.NewAggregate(errs)
+	})
+
+	if err == nil {
+		// The Endpoints are notified as created so advance the last success time of the creation events instead.
+		if len(local) > 0 {
+			er.recordSuccess(LocalEndpointCreated)
+		}
+
+		if len(remote) > 0 {
+			er.recordSuccess(RemoteEndpointCreated)
+		}
+
+		for _, endpoint := range append(append([]*submV1.Endpoint{}, local...), remote...) {
+			er.setLastEndpoint(endpoint.Name, endpoint.DeepCopy())
+		}
+
+		er.timeStampMutex.Lock()
+		defer er.timeStampMutex.Unlock()
+
+		for _, endpoint := range remote {
+			if lastProcessedTime, ok := er.remoteEndpointTimeStamp[endpoint.Spec.ClusterID]; !ok ||
+				endpoint.CreationTimestamp.After(lastProcessedTime.Time) {
+				er.remoteEndpointTimeStamp[endpoint.Spec.ClusterID] = endpoint.CreationTimestamp
+			}
+		}
+	}
+
+	return err
+}
+
+// LastSuccess returns the last time the given event type was processed successfully by all Handlers or the zero time if
+// it never was.
+func (er *Registry) LastSuccess(eventType Type) time.Time {
+	t, _ := er.lastSuccess.Load(string(eventType))
+	last, _ := t.(time.Time)
+
+	return last
+}
+
+// invokeHandlers invokes the enabled handlers and, if they all succeed, records the last success time of the event.
 func (er *Registry) invokeHandlers(eventName string, invoke func(h Handler) error) error {
+	err := er.invoke(eventName, false, invoke)
+	if err == nil {
+		er.recordSuccess(Type(eventName))
+	}
+
+	return err
+}
+
+func (er *Registry) recordSuccess(eventTypes ...Type) {
+	now := time.Now()
+
+	for _, eventType := range eventTypes {
+		er.lastSuccess.Store(string(eventType), now)
+		recordLastSuccess(er.name, string(eventType), now)
+	}
+}
+
+// invokeAllHandlers invokes all handlers, including disabled ones.
+func (er *Registry) invokeAllHandlers(eventName string, invoke func(h Handler) error) error {
+	return er.invoke(eventName, true, invoke)
+}
+
+func (er *Registry) invoke(eventName string, includeDisabled bool, invoke func(h Handler) error) error {
 	var errs []error
 
-	for _, h := range er.eventHandlers {
-		err := invoke(h)
+	handlers := er.eventHandlers
+	if er.reverseTeardown && teardownEvents.Has(eventName) {
+		handlers = make([]Handler, len(er.eventHandlers))
+		for i, h := range er.eventHandlers {
+			handlers[len(handlers)-1-i] = h
+		}
+	}
+
+	for _, h := range handlers {
+		if !includeDisabled && er.disabledHandlers.Has(h.GetName()) {
+			continue
+		}
+
+		er.trace("Event %q dispatched to handler %q in registry %q", eventName, h.GetName(), er.name)
+
+		err := er.invokeHandler(h, invoke)
 		if err != nil {
 			errs = append(errs, errors.Wrapf(err, "%q returned error", h.GetName()))
 		}
+
+		er.trace("Event %q handled by handler %q in registry %q with result: %v", eventName, h.GetName(), er.name, err)
 	}
 
 	return errors.Wrapf(k8serrors.NewAggregate(errs), "%s failed", eventName)
 }
+
+// invokeHandler invokes the given Handler, tracking the event as in flight.
+func (er *Registry) invokeHandler(h Handler, invoke func(h Handler) error) error {
+	er.trackInFlight(h.GetName(), 1)
+	defer er.trackInFlight(h.GetName(), -1)
+
+	return invoke(h)
+}
+
+func (er *Registry) trackInFlight(name string, delta int) {
+	er.inFlightMutex.Lock()
+	defer er.inFlightMutex.Unlock()
+
+	er.inFlight[name] += delta
+	if er.inFlight[name] <= 0 {
+		delete(er.inFlight, name)
+	}
+}
+
+// InFlightHandlers returns the sorted names of the Handlers currently processing an event.
+func (er *Registry) InFlightHandlers() []string {
+	er.inFlightMutex.Lock()
+	defer er.inFlightMutex.Unlock()
+
+	names := make([]string, 0, len(er.inFlight))
+	for name := range er.inFlight {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (er *Registry) trace(format string, args ...interface{}) {
+	if er.tracer == nil {
+		return
+	}
+
+	tracer := *er.tracer
+	if er.handlerState != nil && er.handlerState.GetCorrelationID() != "" {
+		tracer = log.Logger{Logger: tracer.WithValues("correlationID", er.handlerState.GetCorrelationID())}
+	}
+
+	tracer.V(log.DEBUG).Infof("Trace: "+format, args...)
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package event_test
 
 import (
+	"regexp"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -30,6 +31,7 @@ import (
 	"github.com/submariner-io/submariner/pkg/event/testing"
 	k8sV1 "k8s.io/api/core/v1"
 	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const npGenericKubeproxyIptables = "GenericKubeproxyIptables"
@@ -87,6 +89,35 @@ var _ = Describe("Event Registry", func() {
 			Expect(allTestEvents).ToNot(Receive())
 		})
 
+		When("reverse teardown order is enabled", func() {
+			It("should invoke the matching handlers of teardown events in reverse registration order", func() {
+				registry.SetReverseTeardownOrder(true)
+
+				teardown := map[string]bool{
+					testing.EvTransitionToNonGateway: true, testing.EvLocalEndpointRemoved: true, testing.EvRemoteEndpointRemoved: true,
+					testing.EvNodeRemoved: true, testing.EvClusterGlobalEgressIPRemoved: true,
+				}
+
+				for ev, f := range allEvents(registry) {
+					Expect(f()).To(Succeed())
+
+					handlers := append([]*testing.TestHandler{}, matchingHandlers...)
+					if teardown[ev.Name] {
+						for i, j := 0, len(handlers)-1; i < j; i, j = i+1, j-1 {
+							handlers[i], handlers[j] = handlers[j], handlers[i]
+						}
+					}
+
+					for _, h := range handlers {
+						ev.Handler = h.Name
+						Expect(allTestEvents).To(Receive(Equal(ev)))
+					}
+				}
+
+				Expect(allTestEvents).ToNot(Receive())
+			})
+		})
+
 		When("one handler returns an error", func() {
 			It("should invoke subsequent matching handlers", func() {
 				events := allEvents(registry)
@@ -142,6 +173,433 @@ var _ = Describe("Event Registry", func() {
 		})
 	})
 
+	When("a handler is disabled", func() {
+		It("should only be notified of lifecycle events until it's re-enabled", func() {
+			events := make(chan testing.TestEvent, 100)
+			h := testing.NewTestHandler("test", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.SetHandlerEnabled(h.Name, false)).To(BeTrue())
+
+			for ev, f := range allEvents(registry) {
+				Expect(f()).To(Succeed())
+
+				if ev.Name == testing.EvStop || ev.Name == testing.EvUninstall {
+					ev.Handler = h.Name
+					Expect(events).To(Receive(Equal(ev)))
+				}
+			}
+
+			Expect(events).ToNot(Receive())
+
+			Expect(registry.SetHandlerEnabled(h.Name, true)).To(BeTrue())
+			Expect(registry.TransitionToGateway()).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: h.Name, Name: testing.EvTransitionToGateway})))
+
+			Expect(registry.SetHandlerEnabled("unknown", false)).To(BeFalse())
+		})
+	})
+
+	When("a handler is removed", func() {
+		It("should no longer be notified of events", func() {
+			events := make(chan testing.TestEvent, 100)
+			h1 := testing.NewTestHandler("test1", event.AnyNetworkPlugin, events)
+			h2 := testing.NewTestHandler("test2", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h1, h2)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.RemoveHandler(h1.Name)).To(BeTrue())
+			Expect(registry.RemoveHandler(h1.Name)).To(BeFalse())
+
+			Expect(registry.TransitionToGateway()).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: h2.Name, Name: testing.EvTransitionToGateway})))
+			Expect(events).ToNot(Receive())
+
+			added, err := registry.AddHandler(h1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(added).To(BeTrue())
+		})
+	})
+
+	When("events are processed successfully", func() {
+		It("should advance the last success time for the event type", func() {
+			events := make(chan testing.TestEvent, 100)
+			h := testing.NewTestHandler("test", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.LastSuccess(event.TransitionToGateway)).To(BeZero())
+
+			Expect(registry.TransitionToGateway()).To(Succeed())
+
+			first := registry.LastSuccess(event.TransitionToGateway)
+			Expect(first).ToNot(BeZero())
+			Expect(registry.LastSuccess(event.TransitionToNonGateway)).To(BeZero())
+
+			time.Sleep(10 * time.Millisecond)
+			Expect(registry.TransitionToGateway()).To(Succeed())
+			Expect(registry.LastSuccess(event.TransitionToGateway)).To(BeTemporally(">", first))
+
+			last := registry.LastSuccess(event.TransitionToGateway)
+
+			h.FailOnEvent(testing.EvTransitionToGateway)
+			Expect(registry.TransitionToGateway()).ToNot(Succeed())
+			Expect(registry.LastSuccess(event.TransitionToGateway)).To(Equal(last))
+		})
+
+		It("should not record the last success time for the lifecycle calls", func() {
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler("test", event.AnyNetworkPlugin, make(chan testing.TestEvent, 100)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.StopHandlers()).To(Succeed())
+			Expect(registry.LastSuccess("Stop")).To(BeZero())
+		})
+
+		It("should advance the last success time of the Endpoint creation events for the initial Endpoints", func() {
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin,
+				testing.NewTestHandler("test", event.AnyNetworkPlugin, make(chan testing.TestEvent, 100)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.InitialEndpoints(nil, []*submV1.Endpoint{{ObjectMeta: v1meta.ObjectMeta{Name: "remote"}}})).To(Succeed())
+			Expect(registry.LastSuccess(event.InitialEndpoints)).To(BeZero())
+			Expect(registry.LastSuccess(event.LocalEndpointCreated)).To(BeZero())
+			Expect(registry.LastSuccess(event.RemoteEndpointCreated)).ToNot(BeZero())
+		})
+	})
+
+	When("an observer is set", func() {
+		It("should be invoked with each dispatched event and its objects", func() {
+			h := testing.NewTestHandler("test", event.AnyNetworkPlugin, make(chan testing.TestEvent, 100))
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h)
+			Expect(err).NotTo(HaveOccurred())
+
+			var notifications []event.Notification
+
+			registry.SetObserver(func(n event.Notification) {
+				notifications = append(notifications, n)
+			})
+
+			endpoint := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "local"}}
+			node := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node"}}
+
+			Expect(registry.LocalEndpointCreated(endpoint)).To(Succeed())
+			Expect(registry.NodeRemoved(node)).To(Succeed())
+			Expect(registry.TransitionToGateway()).To(Succeed())
+
+			Expect(notifications).To(HaveLen(3))
+			Expect(notifications[0].Type).To(Equal(event.LocalEndpointCreated))
+			Expect(notifications[0].Objects).To(Equal([]runtime.Object{endpoint}))
+			Expect(notifications[0].Time).ToNot(BeZero())
+			Expect(notifications[1].Type).To(Equal(event.NodeRemoved))
+			Expect(notifications[1].Objects).To(Equal([]runtime.Object{node}))
+			Expect(notifications[2].Type).To(Equal(event.TransitionToGateway))
+			Expect(notifications[2].Objects).To(BeEmpty())
+		})
+	})
+
+	When("handlers with the same name are registered", func() {
+		It("should return an error and not add the duplicate handler", func() {
+			events := make(chan testing.TestEvent, 100)
+			h1 := testing.NewTestHandler("test", event.AnyNetworkPlugin, events)
+			h2 := testing.NewTestHandler("test", event.AnyNetworkPlugin, events)
+
+			_, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h1, h2)
+			Expect(err).To(HaveOccurred())
+
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h1)
+			Expect(err).NotTo(HaveOccurred())
+
+			added, err := registry.AddHandler(h2)
+			Expect(err).To(HaveOccurred())
+			Expect(added).To(BeFalse())
+			Expect(h2.Initialized).To(BeFalse())
+
+			Expect(registry.TransitionToGateway()).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: h1.Name, Name: testing.EvTransitionToGateway})))
+			Expect(events).ToNot(Receive())
+		})
+	})
+
+	When("the initial Endpoints are notified", func() {
+		It("should notify them in bulk or individually depending on the handler", func() {
+			events := make(chan testing.TestEvent, 100)
+			bulkHandler := testing.NewTestHandler("bulk", event.AnyNetworkPlugin, events)
+			perEndpointHandler := &createRecordingHandler{}
+
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, bulkHandler, perEndpointHandler)
+			Expect(err).NotTo(HaveOccurred())
+
+			local := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "local"}}
+			remote := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "remote"}}
+
+			Expect(registry.InitialEndpoints([]*submV1.Endpoint{local}, []*submV1.Endpoint{remote})).To(Succeed())
+
+			var e testing.TestEvent
+			Expect(events).To(Receive(&e))
+			Expect(e.Name).To(Equal(testing.EvInitialEndpoints))
+			Expect(e.Parameter).To(Equal([]submV1.Endpoint{*local, *remote}))
+			Expect(events).ToNot(Receive())
+
+			Expect(perEndpointHandler.created).To(Equal([]string{"local:" + local.Name, "remote:" + remote.Name}))
+		})
+
+		It("should not notify the remote Endpoints to a handler notified of them in batches", func() {
+			batchHandler := &batchRecordingHandler{}
+
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, batchHandler)
+			Expect(err).NotTo(HaveOccurred())
+
+			registry.SetBatchRemoteEndpoints(true)
+
+			local := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "local"}}
+			remote := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "remote"}}
+
+			Expect(registry.InitialEndpoints([]*submV1.Endpoint{local}, []*submV1.Endpoint{remote})).To(Succeed())
+			Expect(batchHandler.created).To(Equal([]string{"local:" + local.Name}))
+		})
+	})
+
+	When("a handler receives the topology information of Nodes", func() {
+		It("should deliver the zone and region with each Node event", func() {
+			h := &nodeInfoHandler{}
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h)
+			Expect(err).NotTo(HaveOccurred())
+
+			node := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{
+				Name: "node1",
+				Labels: map[string]string{
+					k8sV1.LabelTopologyZone:   "us-east-1a",
+					k8sV1.LabelTopologyRegion: "us-east-1",
+				},
+			}}
+
+			Expect(registry.NodeCreated(node)).To(Succeed())
+			Expect(registry.NodeUpdated(node)).To(Succeed())
+
+			unlabeled := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node2"}}
+			Expect(registry.NodeRemoved(unlabeled)).To(Succeed())
+
+			Expect(h.types).To(Equal([]event.Type{event.NodeCreated, event.NodeUpdated, event.NodeRemoved}))
+			Expect(h.infos).To(Equal([]event.NodeInfo{
+				{Node: node, Zone: "us-east-1a", Region: "us-east-1"},
+				{Node: node, Zone: "us-east-1a", Region: "us-east-1"},
+				{Node: unlabeled},
+			}))
+		})
+
+		It("should flag the local Node", func() {
+			h := &nodeInfoHandler{}
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, h)
+			Expect(err).NotTo(HaveOccurred())
+
+			registry.SetHandlerState(&localNodeState{localNodeName: "local-node"})
+
+			local := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "local-node"}}
+			other := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "other-node"}}
+
+			Expect(registry.NodeCreated(local)).To(Succeed())
+			Expect(registry.NodeCreated(other)).To(Succeed())
+
+			Expect(h.infos).To(Equal([]event.NodeInfo{
+				{Node: local, IsLocal: true},
+				{Node: other},
+			}))
+		})
+	})
+
+	When("a handler subscribes to Node label changes", func() {
+		It("should only be notified of Node updates that change the labels", func() {
+			events := make(chan testing.TestEvent, 100)
+			subscriber := &labelSubscriber{TestHandler: testing.NewTestHandler("subscriber", event.AnyNetworkPlugin, events)}
+			other := testing.NewTestHandler("other", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, subscriber, other)
+			Expect(err).NotTo(HaveOccurred())
+
+			node := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node1", Labels: map[string]string{"label": "1"}}}
+
+			Expect(registry.NodeCreated(node)).To(Succeed())
+			Expect(events).To(Receive(HaveField("Name", testing.EvNodeCreated)))
+			Expect(events).To(Receive(HaveField("Name", testing.EvNodeCreated)))
+
+			By("Updating the annotations")
+
+			node = node.DeepCopy()
+			node.Annotations = map[string]string{"annotation": "1"}
+			Expect(registry.NodeUpdated(node)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: testing.EvNodeUpdated, Parameter: node})))
+			Expect(events).ToNot(Receive())
+
+			By("Updating the labels")
+
+			node = node.DeepCopy()
+			node.Labels["label"] = "2"
+			Expect(registry.NodeUpdated(node)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: subscriber.Name, Name: testing.EvNodeUpdated, Parameter: node})))
+			Expect(events).To(Receive(Equal(testing.TestEvent{Handler: other.Name, Name: testing.EvNodeUpdated, Parameter: node})))
+			Expect(events).ToNot(Receive())
+
+			By("Updating a Node that wasn't previously notified")
+
+			unknown := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node2"}}
+			Expect(registry.NodeUpdated(unknown)).To(Succeed())
+			Expect(events).To(Receive(HaveField("Handler", subscriber.Name)))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+		})
+	})
+
+	When("a handler subscribes to Endpoint subnet changes", func() {
+		It("should only be notified of Endpoint updates that change the subnets", func() {
+			events := make(chan testing.TestEvent, 100)
+			subscriber := &subnetSubscriber{TestHandler: testing.NewTestHandler("subscriber", event.AnyNetworkPlugin, events)}
+			other := testing.NewTestHandler("other", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, subscriber, other)
+			Expect(err).NotTo(HaveOccurred())
+
+			endpoint := &submV1.Endpoint{
+				ObjectMeta: v1meta.ObjectMeta{Name: "east"},
+				Spec:       submV1.EndpointSpec{ClusterID: "east", Subnets: []string{"10.0.0.0/16"}, PublicIP: "1.1.1.1"},
+			}
+
+			Expect(registry.RemoteEndpointCreated(endpoint)).To(Succeed())
+			Expect(events).To(Receive(HaveField("Name", testing.EvRemoteEndpointCreated)))
+			Expect(events).To(Receive(HaveField("Name", testing.EvRemoteEndpointCreated)))
+
+			By("Updating the public IP")
+
+			endpoint = endpoint.DeepCopy()
+			endpoint.Spec.PublicIP = "2.2.2.2"
+			Expect(registry.RemoteEndpointUpdated(endpoint)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvRemoteEndpointUpdated, Parameter: endpoint,
+			})))
+			Expect(events).ToNot(Receive())
+
+			By("Updating the subnets")
+
+			endpoint = endpoint.DeepCopy()
+			endpoint.Spec.Subnets = append(endpoint.Spec.Subnets, "10.1.0.0/16")
+			Expect(registry.RemoteEndpointUpdated(endpoint)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: subscriber.Name, Name: testing.EvRemoteEndpointUpdated, Parameter: endpoint,
+			})))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+			Expect(events).ToNot(Receive())
+
+			By("Updating the subnets of a local Endpoint that wasn't previously notified")
+
+			local := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "local"}}
+			Expect(registry.LocalEndpointUpdated(local)).To(Succeed())
+			Expect(events).To(Receive(HaveField("Handler", subscriber.Name)))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+
+			By("Updating the hostname of the local Endpoint")
+
+			local = local.DeepCopy()
+			local.Spec.Hostname = "host"
+			Expect(registry.LocalEndpointUpdated(local)).To(Succeed())
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+			Expect(events).ToNot(Receive())
+		})
+	})
+
+	When("a handler subscribes to a cable name pattern", func() {
+		It("should only be notified of the Endpoints with matching cable names", func() {
+			events := make(chan testing.TestEvent, 100)
+			subscriber := &cableSubscriber{
+				TestHandler: testing.NewTestHandler("subscriber", event.AnyNetworkPlugin, events),
+				pattern:     regexp.MustCompile("^submariner-cable-east-"),
+			}
+			other := testing.NewTestHandler("other", event.AnyNetworkPlugin, events)
+			registry, err := event.NewRegistry("test-registry", event.AnyNetworkPlugin, subscriber, other)
+			Expect(err).NotTo(HaveOccurred())
+
+			matching := &submV1.Endpoint{
+				ObjectMeta: v1meta.ObjectMeta{Name: "east"},
+				Spec:       submV1.EndpointSpec{CableName: "submariner-cable-east-10-1-1-1"},
+			}
+			nonMatching := &submV1.Endpoint{
+				ObjectMeta: v1meta.ObjectMeta{Name: "west"},
+				Spec:       submV1.EndpointSpec{CableName: "submariner-cable-west-10-2-2-2"},
+			}
+
+			Expect(registry.RemoteEndpointCreated(matching)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: subscriber.Name, Name: testing.EvRemoteEndpointCreated, Parameter: matching,
+			})))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+			Expect(events).ToNot(Receive())
+
+			Expect(registry.RemoteEndpointUpdated(nonMatching)).To(Succeed())
+			Expect(registry.LocalEndpointCreated(nonMatching)).To(Succeed())
+			Expect(registry.LocalEndpointRemoved(nonMatching)).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvRemoteEndpointUpdated, Parameter: nonMatching,
+			})))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+			Expect(events).To(Receive(HaveField("Handler", other.Name)))
+			Expect(events).ToNot(Receive())
+
+			By("Notifying the initial Endpoints")
+
+			Expect(registry.InitialEndpoints([]*submV1.Endpoint{nonMatching}, []*submV1.Endpoint{matching})).To(Succeed())
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: subscriber.Name, Name: testing.EvInitialEndpoints, Parameter: []submV1.Endpoint{*matching},
+			})))
+			Expect(events).To(Receive(Equal(testing.TestEvent{
+				Handler: other.Name, Name: testing.EvInitialEndpoints, Parameter: []submV1.Endpoint{*nonMatching, *matching},
+			})))
+			Expect(events).ToNot(Receive())
+		})
+	})
+
+	When("a handler mutates a notified object", func() {
+		var (
+			registry *event.Registry
+			events   chan testing.TestEvent
+			endpoint *submV1.Endpoint
+		)
+
+		BeforeEach(func() {
+			events = make(chan testing.TestEvent, 10)
+			endpoint = &submV1.Endpoint{
+				ObjectMeta: v1meta.ObjectMeta{Name: "endpoint1"},
+				Spec:       submV1.EndpointSpec{ClusterID: "east", Hostname: "host1"},
+			}
+
+			var err error
+
+			registry, err = event.NewRegistry("test-registry", event.AnyNetworkPlugin, &mutatingHandler{},
+				testing.NewTestHandler("test", event.AnyNetworkPlugin, events))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("and deep-copying of objects is enabled", func() {
+			It("should not affect the object seen by subsequent handlers", func() {
+				registry.SetDeepCopyObjects(true)
+
+				expected := endpoint.DeepCopy()
+
+				Expect(registry.RemoteEndpointUpdated(endpoint)).To(Succeed())
+				Expect(events).To(Receive(Equal(testing.TestEvent{
+					Handler: "test", Name: testing.EvRemoteEndpointUpdated, Parameter: expected,
+				})))
+				Expect(endpoint).To(Equal(expected))
+			})
+		})
+
+		Context("and deep-copying of objects is disabled", func() {
+			It("should pass the mutated object to subsequent handlers", func() {
+				Expect(registry.RemoteEndpointUpdated(endpoint)).To(Succeed())
+
+				var ev testing.TestEvent
+				Expect(events).To(Receive(&ev))
+				Expect(ev.Parameter.(*submV1.Endpoint).Spec.Hostname).To(Equal("mutated"))
+			})
+		})
+	})
+
 	When("SetHandlerState is called on the registry", func() {
 		It("should invoke SetState on the handlers", func() {
 			h := testing.NewTestHandler("test", event.AnyNetworkPlugin, nil)
@@ -165,6 +623,8 @@ var _ = Describe("Event Registry", func() {
 func allEvents(registry *event.Registry) map[testing.TestEvent]func() error {
 	endpoint := &submV1.Endpoint{ObjectMeta: v1meta.ObjectMeta{Name: "endpoint1"}}
 	node := &k8sV1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node1"}}
+	conflict := testing.SubnetConflict{A: endpoint, B: endpoint, Overlap: "10.0.0.0/16"}
+	egressIP := &submV1.ClusterGlobalEgressIP{ObjectMeta: v1meta.ObjectMeta{Name: "egress-ip1"}}
 
 	return map[testing.TestEvent]func() error{
 		{Name: testing.EvStop}:                                       func() error { return registry.StopHandlers() },
@@ -180,5 +640,140 @@ func allEvents(registry *event.Registry) map[testing.TestEvent]func() error {
 		{Name: testing.EvRemoteEndpointCreated, Parameter: endpoint}: func() error { return registry.RemoteEndpointCreated(endpoint) },
 		{Name: testing.EvRemoteEndpointUpdated, Parameter: endpoint}: func() error { return registry.RemoteEndpointUpdated(endpoint) },
 		{Name: testing.EvRemoteEndpointRemoved, Parameter: endpoint}: func() error { return registry.RemoteEndpointRemoved(endpoint) },
+		{Name: testing.EvSubnetConflictDetected, Parameter: conflict}: func() error {
+			return registry.SubnetConflictDetected(conflict.A, conflict.B, conflict.Overlap)
+		},
+		{Name: testing.EvEndpointHealthChanged, Parameter: testing.EndpointHealth{Endpoint: endpoint}}: func() error {
+			return registry.EndpointHealthChanged(endpoint, false)
+		},
+		{Name: testing.EvLocalEndpointIPChanged, Parameter: testing.EndpointIPChange{Old: endpoint, New: endpoint}}: func() error {
+			return registry.LocalEndpointIPChanged(endpoint, endpoint)
+		},
+		{
+			Name:      testing.EvEndpointBackendConfigChanged,
+			Parameter: &testing.BackendConfigChange{ClusterID: "east", Changed: map[string]string{"port": "4500"}},
+		}: func() error {
+			return registry.EndpointBackendConfigChanged("east", map[string]string{"port": "4500"})
+		},
+		{
+			Name:      testing.EvPreferredGatewayChanged,
+			Parameter: testing.PreferredGatewayChange{ClusterID: "east", Old: endpoint, New: endpoint},
+		}: func() error {
+			return registry.PreferredGatewayChanged("east", endpoint, endpoint)
+		},
+		{Name: testing.EvClusterGlobalEgressIPCreated, Parameter: egressIP}: func() error {
+			return registry.ClusterGlobalEgressIPCreated(egressIP)
+		},
+		{Name: testing.EvClusterGlobalEgressIPUpdated, Parameter: egressIP}: func() error {
+			return registry.ClusterGlobalEgressIPUpdated(egressIP)
+		},
+		{Name: testing.EvClusterGlobalEgressIPRemoved, Parameter: egressIP}: func() error {
+			return registry.ClusterGlobalEgressIPRemoved(egressIP)
+		},
+		{Name: testing.EvWatchReconnected, Parameter: "Endpoint"}: func() error { return registry.WatchReconnected("Endpoint") },
+		{Name: testing.EvHeartbeat}:                               registry.Heartbeat,
 	}
 }
+
+type mutatingHandler struct {
+	event.HandlerBase
+}
+
+func (m *mutatingHandler) GetName() string {
+	return "mutating-handler"
+}
+
+func (m *mutatingHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (m *mutatingHandler) RemoteEndpointUpdated(endpoint *submV1.Endpoint) error {
+	endpoint.Spec.Hostname = "mutated"
+	return nil
+}
+
+type createRecordingHandler struct {
+	event.HandlerBase
+	created []string
+}
+
+func (c *createRecordingHandler) GetName() string {
+	return "create-recording-handler"
+}
+
+func (c *createRecordingHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (c *createRecordingHandler) LocalEndpointCreated(endpoint *submV1.Endpoint) error {
+	c.created = append(c.created, "local:"+endpoint.Name)
+	return nil
+}
+
+func (c *createRecordingHandler) RemoteEndpointCreated(endpoint *submV1.Endpoint) error {
+	c.created = append(c.created, "remote:"+endpoint.Name)
+	return nil
+}
+
+type batchRecordingHandler struct {
+	createRecordingHandler
+}
+
+func (b *batchRecordingHandler) OnRemoteEndpointBatch(_ string, _ []event.EndpointEvent) error {
+	return nil
+}
+
+type labelSubscriber struct {
+	*testing.TestHandler
+}
+
+func (l *labelSubscriber) NodeUpdateFields() []event.NodeField {
+	return []event.NodeField{event.NodeLabels}
+}
+
+type subnetSubscriber struct {
+	*testing.TestHandler
+}
+
+func (s *subnetSubscriber) EndpointUpdateFields() []event.EndpointField {
+	return []event.EndpointField{event.EndpointSubnets}
+}
+
+type cableSubscriber struct {
+	*testing.TestHandler
+	pattern *regexp.Regexp
+}
+
+func (c *cableSubscriber) CableNamePattern() *regexp.Regexp {
+	return c.pattern
+}
+
+type localNodeState struct {
+	event.DefaultHandlerState
+	localNodeName string
+}
+
+func (s *localNodeState) GetLocalNodeName() string {
+	return s.localNodeName
+}
+
+type nodeInfoHandler struct {
+	event.HandlerBase
+	types []event.Type
+	infos []event.NodeInfo
+}
+
+func (n *nodeInfoHandler) GetName() string {
+	return "node-info-handler"
+}
+
+func (n *nodeInfoHandler) GetNetworkPlugins() []string {
+	return []string{event.AnyNetworkPlugin}
+}
+
+func (n *nodeInfoHandler) OnNodeInfo(eventType event.Type, info *event.NodeInfo) error {
+	n.types = append(n.types, eventType)
+	n.infos = append(n.infos, *info)
+
+	return nil
+}
@@ -47,7 +47,10 @@ func init() {
 }
 
 type ControllerSupport struct {
-	Hostname  string
+	Hostname   string
+	Controller *controller.Controller
+	// Configure, if set, is invoked to modify the controller Config before the controller is created.
+	Configure func(config *controller.Config)
 	endpoints dynamic.ResourceInterface
 	nodes     dynamic.ResourceInterface
 }
@@ -77,11 +80,17 @@ func (c *ControllerSupport) Start(handler event.Handler) {
 	c.nodes = config.Client.Resource(*test.GetGroupVersionResourceFor(config.RestMapper, &corev1.Node{}))
 	c.endpoints = config.Client.Resource(*test.GetGroupVersionResourceFor(config.RestMapper, &submV1.Endpoint{})).Namespace(Namespace)
 
+	if c.Configure != nil {
+		c.Configure(&config)
+	}
+
 	eventController, err := controller.New(&config)
 
 	Expect(err).To(Succeed())
 	Expect(eventController.Start(stopCh)).To(Succeed())
 
+	c.Controller = eventController
+
 	DeferCleanup(func() {
 		close(stopCh)
 		eventController.Stop()
@@ -33,6 +33,38 @@ type TestEvent struct {
 	Parameter interface{}
 }
 
+// SubnetConflict is the TestEvent Parameter for EvSubnetConflictDetected.
+type SubnetConflict struct {
+	A       *v1.Endpoint
+	B       *v1.Endpoint
+	Overlap string
+}
+
+// EndpointHealth is the TestEvent Parameter for EvEndpointHealthChanged.
+type EndpointHealth struct {
+	Endpoint *v1.Endpoint
+	Healthy  bool
+}
+
+// EndpointIPChange is the TestEvent Parameter for EvLocalEndpointIPChanged.
+type EndpointIPChange struct {
+	Old *v1.Endpoint
+	New *v1.Endpoint
+}
+
+// BackendConfigChange is the TestEvent Parameter, by pointer, for EvEndpointBackendConfigChanged.
+type BackendConfigChange struct {
+	ClusterID string
+	Changed   map[string]string
+}
+
+// PreferredGatewayChange is the TestEvent Parameter for EvPreferredGatewayChanged.
+type PreferredGatewayChange struct {
+	ClusterID string
+	Old       *v1.Endpoint
+	New       *v1.Endpoint
+}
+
 type TestHandlerState struct {
 	event.DefaultHandlerState
 	Gateway bool
@@ -111,6 +143,21 @@ const (
 	EvNodeRemoved            = "NodeRemoved"
 	EvStop                   = "Stop"
 	EvUninstall              = "Uninstall"
+	EvSubnetConflictDetected = "SubnetConflictDetected"
+	EvEndpointHealthChanged  = "EndpointHealthChanged"
+
+	EvClusterGlobalEgressIPCreated = "ClusterGlobalEgressIPCreated"
+	EvClusterGlobalEgressIPUpdated = "ClusterGlobalEgressIPUpdated"
+	EvClusterGlobalEgressIPRemoved = "ClusterGlobalEgressIPRemoved"
+
+	EvWatchReconnected = "WatchReconnected"
+	EvInitialEndpoints = "InitialEndpoints"
+
+	EvLocalEndpointIPChanged  = "LocalEndpointIPChanged"
+	EvPreferredGatewayChanged = "PreferredGatewayChanged"
+	EvHeartbeat               = "Heartbeat"
+
+	EvEndpointBackendConfigChanged = "EndpointBackendConfigChanged"
 )
 
 func (t *TestHandler) Stop() error {
@@ -164,3 +211,47 @@ func (t *TestHandler) NodeUpdated(node *v12.Node) error {
 func (t *TestHandler) NodeRemoved(node *v12.Node) error {
 	return t.addEvent(EvNodeRemoved, node)
 }
+
+func (t *TestHandler) SubnetConflictDetected(a, b *v1.Endpoint, overlap string) error {
+	return t.addEvent(EvSubnetConflictDetected, SubnetConflict{A: a, B: b, Overlap: overlap})
+}
+
+func (t *TestHandler) EndpointHealthChanged(endpoint *v1.Endpoint, healthy bool) error {
+	return t.addEvent(EvEndpointHealthChanged, EndpointHealth{Endpoint: endpoint, Healthy: healthy})
+}
+
+func (t *TestHandler) LocalEndpointIPChanged(oldEndpoint, newEndpoint *v1.Endpoint) error {
+	return t.addEvent(EvLocalEndpointIPChanged, EndpointIPChange{Old: oldEndpoint, New: newEndpoint})
+}
+
+func (t *TestHandler) EndpointBackendConfigChanged(clusterID string, changed map[string]string) error {
+	return t.addEvent(EvEndpointBackendConfigChanged, &BackendConfigChange{ClusterID: clusterID, Changed: changed})
+}
+
+func (t *TestHandler) PreferredGatewayChanged(clusterID string, oldEndpoint, newEndpoint *v1.Endpoint) error {
+	return t.addEvent(EvPreferredGatewayChanged, PreferredGatewayChange{ClusterID: clusterID, Old: oldEndpoint, New: newEndpoint})
+}
+
+func (t *TestHandler) ClusterGlobalEgressIPCreated(egressIP *v1.ClusterGlobalEgressIP) error {
+	return t.addEvent(EvClusterGlobalEgressIPCreated, egressIP)
+}
+
+func (t *TestHandler) ClusterGlobalEgressIPUpdated(egressIP *v1.ClusterGlobalEgressIP) error {
+	return t.addEvent(EvClusterGlobalEgressIPUpdated, egressIP)
+}
+
+func (t *TestHandler) ClusterGlobalEgressIPRemoved(egressIP *v1.ClusterGlobalEgressIP) error {
+	return t.addEvent(EvClusterGlobalEgressIPRemoved, egressIP)
+}
+
+func (t *TestHandler) OnWatchReconnected(resource string) error {
+	return t.addEvent(EvWatchReconnected, resource)
+}
+
+func (t *TestHandler) OnHeartbeat() error {
+	return t.addEvent(EvHeartbeat, nil)
+}
+
+func (t *TestHandler) OnInitialEndpoints(endpoints []v1.Endpoint) error {
+	return t.addEvent(EvInitialEndpoints, endpoints)
+}